@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/campus"
+	"github.com/IbnBaqqi/book-me/internal/config"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+	"github.com/IbnBaqqi/book-me/internal/service"
+	appvalidator "github.com/IbnBaqqi/book-me/internal/validator"
+)
+
+// operator holds the grants of whoever runs an admin command. Having shell
+// and database access, they may do anything an admin may, and are recorded
+// in the audit log as the system.
+var operator = rbac.Grants{{Role: rbac.Admin}}
+
+// promoteUser grants a role to the user with an email, so the first admin
+// can be set up before anyone can use the API.
+func promoteUser(args []string) error {
+	flags, configPath := newFlagSet("user promote", "user promote <email> [flags]")
+	role := flags.String("role", string(rbac.Admin), "role to grant")
+	roomID := flags.Int64("room", 0, "ID of the room to grant the role for, all rooms by default")
+	positional, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return errors.New("user promote takes the email of the user")
+	}
+	userEmail := positional[0]
+
+	ctx := context.Background()
+	_, db, err := connect(ctx, config.Load, *configPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	user, err := db.GetUserByEmail(ctx, userEmail)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no user with email %q, they must log in once first", userEmail)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	roles := service.NewRoleService(db, audit.NewService(db))
+	assignment, err := roles.AssignRole(ctx, service.AssignRoleInput{
+		UserID: user.ID,
+		Role:   *role,
+		RoomID: *roomID,
+		Grants: operator,
+	})
+	if err != nil {
+		return err
+	}
+
+	scope := "all rooms"
+	if assignment.RoomID.Valid {
+		scope = fmt.Sprintf("room %d", assignment.RoomID.Int64)
+	}
+	fmt.Printf("granted %s to %s (user %d) for %s\n", assignment.Role, user.Email, user.ID, scope)
+	return nil
+}
+
+// addRoom adds a room to a campus.
+func addRoom(args []string) error {
+	flags, configPath := newFlagSet("room add", "room add --name <name> [flags]")
+	name := flags.String("name", "", "name of the room")
+	campusSlug := flags.String("campus", "", "slug of the campus, the default campus by default")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+	if err := appvalidator.Validate(dto.CreateRoomRequest{Name: *name}); err != nil {
+		return invalid("room", err)
+	}
+
+	ctx := context.Background()
+	cfg, db, err := connect(ctx, config.Load, *configPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	campuses, home, err := loadCampus(ctx, cfg, db, *campusSlug)
+	if err != nil {
+		return err
+	}
+	if home == nil {
+		home = campuses.Default()
+	}
+
+	campusService := service.NewCampusService(db, campuses, audit.NewService(db))
+	room, err := campusService.CreateRoom(ctx, service.CreateRoomInput{
+		CampusID: home.ID,
+		Name:     *name,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("added room %q (room %d) to %s\n", room.Name, room.ID, home.Name)
+	return nil
+}
+
+// loadCampus loads the campuses and returns the one with the slug, or nil
+// when the slug is empty.
+func loadCampus(
+	ctx context.Context,
+	cfg *config.Config,
+	db *database.DB,
+	slug string,
+) (*campus.Registry, *campus.Campus, error) {
+	campuses := campus.NewRegistry(db, cfg.Access.DefaultCampus)
+	if err := campuses.Load(ctx); err != nil {
+		return nil, nil, fmt.Errorf("failed to load campuses: %w", err)
+	}
+	if slug == "" {
+		return campuses, nil, nil
+	}
+
+	c, ok := campuses.BySlug(slug)
+	if !ok {
+		return nil, nil, fmt.Errorf("no campus with slug %q", slug)
+	}
+	return campuses, c, nil
+}
+
+// invalid describes a failed validation of a flag value.
+func invalid(what string, err error) error {
+	var validationErr *appvalidator.ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Fields) == 0 {
+		return fmt.Errorf("invalid %s: %w", what, err)
+	}
+
+	msg := "invalid " + what
+	for _, field := range slices.Sorted(maps.Keys(validationErr.Fields)) {
+		msg += fmt.Sprintf(", %s: %s", field, validationErr.Fields[field])
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,128 @@
+// Package main is the bookme command. It serves the API and runs the admin
+// tasks operators need, like migrations, without raw SQL.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	_ "time/tzdata"
+
+	"github.com/IbnBaqqi/book-me/internal/config"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/logger"
+)
+
+const usage = `Usage: bookme <command> [flags]
+
+Commands:
+  serve                   run the server, the default without a command
+  migrate up|down|status  apply, roll back the last or list database migrations
+  user promote <email>    grant a role to a user, ADMIN by default
+  room add                add a room to a campus
+  reservations export     export reservations as CSV or JSON
+  calendar resync         create the missing Google Calendar events
+
+Every command reads the configuration like the server does. Run
+"bookme <command> -h" for the flags of a command.
+`
+
+// commands by name, admin tasks are named by the object they act on and
+// the action.
+var commands = map[string]func(args []string) error{
+	"serve":               serve,
+	"migrate":             migrate,
+	"user promote":        promoteUser,
+	"room add":            addRoom,
+	"reservations export": exportReservations,
+	"calendar resync":     resyncCalendar,
+}
+
+func main() {
+	err := run(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bookme:", err)
+		os.Exit(1)
+	}
+}
+
+// run dispatches args to their command. Without a command, or with flags
+// only, the server is run.
+func run(args []string) error {
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+		fmt.Fprint(os.Stderr, usage)
+		return flag.ErrHelp
+	}
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return serve(args)
+	}
+	if command, ok := commands[args[0]]; ok {
+		return command(args[1:])
+	}
+	if len(args) > 1 {
+		if command, ok := commands[args[0]+" "+args[1]]; ok {
+			return command(args[2:])
+		}
+	}
+
+	fmt.Fprint(os.Stderr, usage)
+	return fmt.Errorf("unknown command %q", strings.Join(args, " "))
+}
+
+// newFlagSet creates the flags of a command, with the config file flag
+// every command takes.
+func newFlagSet(name, synopsis string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet("bookme "+name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: bookme %s\n\nFlags:\n", synopsis)
+		flags.PrintDefaults()
+	}
+	configPath := flags.String("config", "", "path of the YAML config file, defaults to $CONFIG_FILE")
+	return flags, configPath
+}
+
+// connect loads the configuration with load and connects to the database
+// for an admin task, logging to standard error so output can be piped.
+func connect(
+	ctx context.Context,
+	load func(path string) (*config.Config, error),
+	configPath string,
+) (*config.Config, *database.DB, error) {
+	cfg, err := load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	logger.InitWriter(os.Stderr, &cfg.Logger, cfg.App.Env)
+
+	db, err := database.Connect(ctx, &cfg.App)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return cfg, db, nil
+}
+
+// parse parses flags anywhere in args, unlike FlagSet.Parse which stops at
+// the first positional argument, and returns the positional arguments.
+func parse(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...), nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"flag"
+	"io"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/campus"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/schedule"
+)
+
+func TestParse_FlagsAfterPositional(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	role := flags.String("role", "ADMIN", "")
+	room := flags.Int64("room", 0, "")
+
+	positional, err := parse(flags, []string{"jane@example.com", "--role", "ROOM_MANAGER", "--room=3", "--", "-x"})
+	if err != nil {
+		t.Fatalf("parse() error = %v", err)
+	}
+	if !slices.Equal(positional, []string{"jane@example.com", "-x"}) {
+		t.Errorf("positional = %q", positional)
+	}
+	if *role != "ROOM_MANAGER" || *room != 3 {
+		t.Errorf("role = %q, room = %d", *role, *room)
+	}
+}
+
+func TestPeriod(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 3, 10, 23, 30, 0, 0, time.UTC) // March 11th in Helsinki
+
+	tests := []struct {
+		name      string
+		from, to  string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "defaults are relative to today in the campus",
+			wantStart: time.Date(2026, 2, 9, 0, 0, 0, 0, loc),
+			wantEnd:   time.Date(2026, 3, 12, 0, 0, 0, 0, loc),
+		},
+		{
+			name:      "days include the last day",
+			from:      "2026-01-01",
+			to:        "2026-01-31",
+			wantStart: time.Date(2026, 1, 1, 0, 0, 0, 0, loc),
+			wantEnd:   time.Date(2026, 2, 1, 0, 0, 0, 0, loc),
+		},
+		{name: "invalid day", from: "01/01/2026", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := period(tt.from, tt.to, -30, 0, now, loc)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("period() error = %v", err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("period() = %v, %v, want %v, %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	campuses := campus.NewRegistry(nil, "hive-helsinki")
+	if err := campuses.Replace([]*campus.Campus{{
+		ID: 1, Slug: "hive-helsinki", Location: loc,
+		Hours: schedule.NewCalendar(loc, schedule.Hours{Open: 6 * 60, Close: 20 * 60}),
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	rows := []database.ListReservationsBetweenRow{{
+		ID: 7, CampusID: 1, RoomID: 2, UserID: 3,
+		StartTime: start, EndTime: start.Add(time.Hour),
+		Status: "RESERVED", GcalEventID: sql.NullString{String: "evt", Valid: true},
+		RoomName: "Big, Room", UserName: "Jane", UserEmail: "jane@example.com",
+	}}
+
+	var out bytes.Buffer
+	if err := writeCSV(&out, rows, campuses); err != nil {
+		t.Fatalf("writeCSV() error = %v", err)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want header and one row", len(records))
+	}
+	want := []string{
+		"7", "hive-helsinki", "2", "Big, Room", "3", "Jane", "jane@example.com",
+		"2026-03-10T10:00:00+02:00", "2026-03-10T11:00:00+02:00", "RESERVED", "evt",
+	}
+	if !slices.Equal(records[1], want) {
+		t.Errorf("row = %q, want %q", records[1], want)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/IbnBaqqi/book-me/internal/config"
+	"github.com/IbnBaqqi/book-me/internal/database"
+)
+
+// migrate applies, rolls back or lists the migrations embedded in the binary.
+// It only needs the database settings, so it can run before the others are set.
+func migrate(args []string) error {
+	flags, configPath := newFlagSet("migrate", "migrate up|down|status [flags]")
+	positional, err := parse(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		flags.Usage()
+		return errors.New("migrate takes one of up, down or status")
+	}
+
+	ctx := context.Background()
+	_, db, err := connect(ctx, config.LoadDatabase, *configPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return database.Migrate(ctx, db.DB, positional[0], os.Stdout)
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/api"
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/campus"
+	"github.com/IbnBaqqi/book-me/internal/config"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/email"
+	"github.com/IbnBaqqi/book-me/internal/google"
+	"github.com/IbnBaqqi/book-me/internal/integration"
+	"github.com/IbnBaqqi/book-me/internal/service"
+)
+
+// exportReservations writes the reservations of a period as CSV or JSON.
+func exportReservations(args []string) error {
+	flags, configPath := newFlagSet("reservations export", "reservations export [flags]")
+	campusSlug := flags.String("campus", "", "slug of the campus, all campuses by default")
+	from := flags.String("from", "", "first day to export as YYYY-MM-DD, 30 days ago by default")
+	to := flags.String("to", "", "last day to export as YYYY-MM-DD, today by default")
+	format := flags.String("format", "csv", "output format, csv or json")
+	output := flags.String("o", "", "file to write, standard output by default")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+	if *format != "csv" && *format != "json" {
+		return fmt.Errorf("unknown format %q, expected csv or json", *format)
+	}
+
+	ctx := context.Background()
+	cfg, db, err := connect(ctx, config.Load, *configPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	campuses, input, err := periodOf(ctx, cfg, db, *campusSlug, *from, *to, -30, 0)
+	if err != nil {
+		return err
+	}
+
+	calendar := integration.NewOptional[google.Calendar]("calendar", google.NullCalendar{}, integration.Disabled())
+	reservations := newReservationService(db, campuses, calendar)
+	rows, err := reservations.ListReservations(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if *format == "json" {
+		err = writeJSON(w, rows, campuses)
+	} else {
+		err = writeCSV(w, rows, campuses)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write reservations: %w", err)
+	}
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "exported %d reservations to %s\n", len(rows), *output)
+	}
+	return nil
+}
+
+// resyncCalendar creates the calendar events of reservations booked while
+// the calendar was off or failing.
+func resyncCalendar(args []string) error {
+	flags, configPath := newFlagSet("calendar resync", "calendar resync [flags]")
+	campusSlug := flags.String("campus", "", "slug of the campus, all campuses by default")
+	from := flags.String("from", "", "first day to resync as YYYY-MM-DD, today by default")
+	to := flags.String("to", "", "last day to resync as YYYY-MM-DD, 90 days from now by default")
+	if _, err := parse(flags, args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	cfg, db, err := connect(ctx, config.Load, *configPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	calendar, state := api.NewCalendar(cfg)
+	if state.Status != integration.StatusAvailable {
+		return fmt.Errorf("calendar %s: %s", state.Status, state.Reason)
+	}
+
+	campuses, input, err := periodOf(ctx, cfg, db, *campusSlug, *from, *to, 0, 90)
+	if err != nil {
+		return err
+	}
+
+	reservations := newReservationService(db, campuses, integration.NewOptional("calendar", calendar, state))
+	result, err := reservations.ResyncCalendar(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created %d calendar events, %d failed\n", result.Created, result.Failed)
+	if result.Failed > 0 {
+		return errors.New("some calendar events could not be created, see the log")
+	}
+	return nil
+}
+
+// periodOf loads the campuses and returns the reservations to list from
+// the campus and day flags. Days are in the timezone of the campus, or of the
+// default campus for all campuses.
+func periodOf(
+	ctx context.Context,
+	cfg *config.Config,
+	db *database.DB,
+	campusSlug, from, to string,
+	fromDays, toDays int,
+) (*campus.Registry, service.ListReservationsInput, error) {
+	var input service.ListReservationsInput
+	campuses, home, err := loadCampus(ctx, cfg, db, campusSlug)
+	if err != nil {
+		return nil, input, err
+	}
+	if home != nil {
+		input.CampusID = home.ID
+	} else {
+		home = campuses.Default()
+	}
+
+	input.From, input.To, err = period(from, to, fromDays, toDays, time.Now(), home.Location)
+	return campuses, input, err
+}
+
+// newReservationService creates the reservation service of a command, which
+// never sends email.
+func newReservationService(
+	db *database.DB,
+	campuses *campus.Registry,
+	calendar *integration.Optional[google.Calendar],
+) *service.ReservationService {
+	return service.NewReservationService(
+		db,
+		integration.NewOptional[email.Sender]("email", email.NullSender{}, integration.Disabled()),
+		calendar,
+		audit.NewService(db),
+		campuses,
+	)
+}
+
+// period returns the start of day from and the end of day to in loc. An
+// empty day is now moved by the days given for it.
+func period(from, to string, fromDays, toDays int, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	start := today.AddDate(0, 0, fromDays)
+	if from != "" {
+		day, err := time.ParseInLocation(time.DateOnly, from, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --from day %q, expected YYYY-MM-DD", from)
+		}
+		start = day
+	}
+
+	end := today.AddDate(0, 0, toDays)
+	if to != "" {
+		day, err := time.ParseInLocation(time.DateOnly, to, loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --to day %q, expected YYYY-MM-DD", to)
+		}
+		end = day
+	}
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+// exportedReservation is a reservation as exported, with times in the
+// timezone of its campus.
+type exportedReservation struct {
+	ID              int64  `json:"id"`
+	Campus          string `json:"campus"`
+	RoomID          int64  `json:"roomId"`
+	Room            string `json:"room"`
+	UserID          int64  `json:"userId"`
+	UserName        string `json:"userName"`
+	UserEmail       string `json:"userEmail"`
+	StartTime       string `json:"startTime"`
+	EndTime         string `json:"endTime"`
+	Status          string `json:"status"`
+	CalendarEventID string `json:"calendarEventId,omitempty"`
+}
+
+func toExported(row database.ListReservationsBetweenRow, campuses *campus.Registry) exportedReservation {
+	home := campuses.Resolve(row.CampusID)
+	return exportedReservation{
+		ID:              row.ID,
+		Campus:          home.Slug,
+		RoomID:          row.RoomID,
+		Room:            row.RoomName,
+		UserID:          row.UserID,
+		UserName:        row.UserName,
+		UserEmail:       row.UserEmail,
+		StartTime:       row.StartTime.In(home.Location).Format(time.RFC3339),
+		EndTime:         row.EndTime.In(home.Location).Format(time.RFC3339),
+		Status:          row.Status,
+		CalendarEventID: row.GcalEventID.String,
+	}
+}
+
+// writeJSON writes the reservations as a JSON array.
+func writeJSON(w io.Writer, rows []database.ListReservationsBetweenRow, campuses *campus.Registry) error {
+	exported := make([]exportedReservation, 0, len(rows))
+	for _, row := range rows {
+		exported = append(exported, toExported(row, campuses))
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(exported)
+}
+
+// writeCSV writes the reservations as CSV with a header row.
+func writeCSV(w io.Writer, rows []database.ListReservationsBetweenRow, campuses *campus.Registry) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{
+		"id", "campus", "room_id", "room", "user_id", "user_name", "user_email",
+		"start_time", "end_time", "status", "calendar_event_id",
+	})
+	for _, row := range rows {
+		r := toExported(row, campuses)
+		_ = writer.Write([]string{
+			strconv.FormatInt(r.ID, 10), r.Campus, strconv.FormatInt(r.RoomID, 10), r.Room,
+			strconv.FormatInt(r.UserID, 10), r.UserName, r.UserEmail,
+			r.StartTime, r.EndTime, r.Status, r.CalendarEventID,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/api"
+	"github.com/IbnBaqqi/book-me/internal/config"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/logger"
+	"github.com/IbnBaqqi/book-me/internal/tracing"
+)
+
+// serve runs the server until it is asked to shut down.
+func serve(args []string) error {
+	flags, configPath := newFlagSet("serve", "serve [flags]")
+	printConfig := flags.Bool("print-config", false, "print the configuration with secrets redacted and exit")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return runServer(*configPath, *printConfig)
+}
+
+func runServer(configPath string, printConfig bool) error {
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if printConfig {
+		return cfg.Print(os.Stdout)
+	}
+
+	logger.Init(&cfg.Logger, cfg.App.Env)
+
+	slog.Info("starting book-me server",
+		"port", cfg.Server.Port,
+		"log_level", cfg.Logger.Level,
+	)
+
+	ctx := context.Background()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Error("failed to flush traces", "error", err)
+		}
+	}()
+
+	db, err := database.Connect(ctx, &cfg.App)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		if err = db.Close(); err != nil {
+			slog.Error("failed to close database connection", "error", err)
+		}
+	}()
+
+	apiCfg, err := api.New(cfg, db)
+	if err != nil {
+		return fmt.Errorf("failed to initialize api services: %w", err)
+	}
+
+	handler := api.SetupRoutes(apiCfg)
+
+	// Check dependencies in the background for the readiness probe
+	healthCtx, stopHealth := context.WithCancel(ctx)
+	defer stopHealth()
+	go apiCfg.Health.Run(healthCtx)
+
+	stopWatching := watchConfig(configPath, cfg, apiCfg)
+	defer stopWatching()
+
+	server := &http.Server{
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      handler,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("Server listening", "address", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errCh <- err
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case <-quit:
+		slog.Info("Server is shutting down...")
+	case err := <-errCh:
+		return fmt.Errorf("server failed to start: %w", err)
+	}
+
+	// Fail readiness first, so load balancers stop sending traffic before
+	// the listener closes
+	apiCfg.Health.Drain()
+	if cfg.Server.DrainDelay > 0 {
+		slog.Info("draining connections", "delay", cfg.Server.DrainDelay)
+		time.Sleep(cfg.Server.DrainDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("server forced to shutdown: %w", err)
+	}
+
+	slog.Info("Server exited gracefully")
+	return nil
+}
+
+// watchConfig reloads the configuration on SIGHUP and applies the settings
+// that can change while serving. Secrets read from files and secret
+// managers are read again every refresh interval to pick up rotations, and
+// calendar and email are retried with the current configuration every
+// integration retry interval. An invalid configuration is logged and the
+// current one kept. The returned function stops watching.
+func watchConfig(configPath string, cfg *config.Config, apiCfg *api.API) func() {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	done := make(chan struct{})
+	go func() {
+		var refresh <-chan time.Time
+		if cfg.Secrets.RefreshInterval > 0 {
+			ticker := time.NewTicker(cfg.Secrets.RefreshInterval)
+			defer ticker.Stop()
+			refresh = ticker.C
+		}
+		retry := time.NewTicker(cfg.Server.IntegrationRetryInterval)
+		defer retry.Stop()
+
+		current := cfg
+		for {
+			var next *config.Config
+			var err error
+			select {
+			case <-hup:
+				next, err = config.Load(configPath)
+				if err == nil {
+					apiCfg.Reload(next)
+				}
+			case <-refresh:
+				next, err = current.RefreshSecrets(context.Background())
+			case <-retry.C:
+				apiCfg.RetryIntegrations(current)
+				continue
+			case <-done:
+				return
+			}
+			if err != nil {
+				slog.Error("failed to reload configuration, keeping the current one", "error", err)
+				continue
+			}
+			apiCfg.RotateSecrets(next, config.ChangedSecrets(current, next))
+			current = next
+		}
+	}()
+
+	return func() {
+		signal.Stop(hup)
+		close(done)
+	}
+}
@@ -2,15 +2,30 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/audit"
 	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/campus"
+	"github.com/IbnBaqqi/book-me/internal/clientip"
 	"github.com/IbnBaqqi/book-me/internal/config"
 	"github.com/IbnBaqqi/book-me/internal/database"
 	"github.com/IbnBaqqi/book-me/internal/email"
 	"github.com/IbnBaqqi/book-me/internal/google"
+	"github.com/IbnBaqqi/book-me/internal/health"
+	"github.com/IbnBaqqi/book-me/internal/integration"
+	"github.com/IbnBaqqi/book-me/internal/metrics"
+	"github.com/IbnBaqqi/book-me/internal/middleware"
 	"github.com/IbnBaqqi/book-me/internal/oauth"
+	"github.com/IbnBaqqi/book-me/internal/ratelimit"
+	"github.com/IbnBaqqi/book-me/internal/schedule"
 	"github.com/IbnBaqqi/book-me/internal/service"
+	"github.com/IbnBaqqi/book-me/internal/validator"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/redis/go-redis/v9"
 	"golang.org/x/oauth2"
 )
 
@@ -19,40 +34,64 @@ type API struct {
 	DB              *database.DB
 	Oauth           *oauth.Service
 	Auth            *auth.Service
-	EmailService    *email.Service
-	CalendarService *google.CalendarService
+	EmailService    *integration.Optional[email.Sender]
+	CalendarService *integration.Optional[google.Calendar]
 	Reservation     *service.ReservationService
+	AccessRules     *service.AccessRuleService
+	Roles           *service.RoleService
+	APITokens       *service.APITokenService
+	Displays        *service.DisplayService
+	Audit           *audit.Service
+	Users           *service.UserService
+	Blackouts       *service.BlackoutService
+	OpeningHours    *service.OpeningHoursService
+	CampusAdmin     *service.CampusService
+	Campuses        *campus.Registry
+	LoginGuard      *service.LoginGuardService
+	RateLimits      ratelimit.Store
+	OAuthLimiter    *middleware.RateLimiter
+	APIIPLimiter    *middleware.RateLimiter
+	APILimiter      *middleware.RateLimiter
+	Cors            *middleware.Cors
+	ClientIPs       *clientip.Resolver
+	Health          *health.Checker
 }
 
 // New initializes all services and returns a pointer to API
 func New(cfg *config.Config, db *database.DB) (*API, error) {
 
-	// Initialize Google Calendar service
-	calendarService, err := google.NewCalendarService(
-		cfg.Google.CredentialsBase64,
-		cfg.Google.CalendarScope,
-		cfg.Google.CalendarID,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize calendar service: %w", err)
+	// Expose the connection pool stats
+	if err := metrics.RegisterDB(db.DB); err != nil {
+		return nil, fmt.Errorf("failed to register database metrics: %w", err)
 	}
 
-	// Initialize email service
-	emailCfg := email.Config{
-		SMTPHost:     cfg.Email.SMTPHost,
-		SMTPPort:     cfg.Email.SMTPPort,
-		SMTPUsername: cfg.Email.SMTPUsername,
-		SMTPPassword: cfg.Email.SMTPPassword,
-		FromEmail:    cfg.Email.FromEmail,
-		FromName:     cfg.Email.FromName,
-		UseTLS:       cfg.Email.UseTLS,
+	// Client IPs are read from forwarding headers of trusted proxies only
+	clientIPs, err := clientip.NewResolver(cfg.Server.TrustedProxies)
+	if err != nil {
+		return nil, err
 	}
 
-	emailService, err := email.NewService(emailCfg)
+	// Rate limiter state, shared between replicas when kept in Redis
+	storeCtx, cancelStore := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelStore()
+	rateLimits, err := newRateLimitStore(storeCtx, cfg.RateLimit)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize email service: %w", err)
+		return nil, err
 	}
 
+	// Create rate limiters. API requests are limited per IP before their
+	// token is checked, then per user with limits overridden per route and role
+	oauthLimiter := middleware.NewRateLimiter("oauth", rateLimits, limitOf(cfg.RateLimit.OAuth), clientIPs)
+	apiIPLimiter := middleware.NewRateLimiter("api_ip", rateLimits, limitOf(cfg.RateLimit.APIIP), clientIPs)
+	apiLimiter := middleware.NewRateLimiter("api", rateLimits, limitOf(cfg.RateLimit.API), clientIPs, rateLimitPoliciesOf(cfg)...)
+
+	// Google Calendar and email are optional, the server runs without them
+	// and queues their work until they are back
+	calendar, calendarState := NewCalendar(cfg)
+	calendarService := integration.NewOptional("calendar", calendar, calendarState)
+	sender, emailState := NewEmail(cfg)
+	emailService := integration.NewOptional("email", sender, emailState)
+
 	// Initialize OAuth2 config for 42 auth
 	oauthConfig := &oauth2.Config{
 		ClientID:     cfg.App.ClientID,
@@ -65,28 +104,97 @@ func New(cfg *config.Config, db *database.DB) (*API, error) {
 		},
 	}
 
-	// Initialize Keycloak OIDC config
+	// Initialize Keycloak OIDC config, endpoints are filled in by discovery
 	keycloakConfig := &oauth2.Config{
 		ClientID:     cfg.App.KeycloakClientID,
 		ClientSecret: cfg.App.KeycloakClientSecret,
 		RedirectURL:  cfg.App.KeycloakRedirectURI,
-		Scopes:       []string{"openid", "email", "profile"},
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  cfg.App.KeycloakAuthURI,
-			TokenURL: cfg.App.KeycloakTokenURI,
-		},
+		Scopes:       []string{oidc.ScopeOpenID, "email", "profile"},
+	}
+
+	// Load campuses with their opening hours, shared by logins,
+	// request validation, reservations and CORS
+	campuses := campus.NewRegistry(db, cfg.Access.DefaultCampus)
+
+	loadCtx, cancelLoad := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelLoad()
+	if err := campuses.Load(loadCtx); err != nil {
+		return nil, fmt.Errorf("failed to load campuses: %w", err)
 	}
+	validator.SetOpeningHours(func() *schedule.Calendar {
+		return campuses.Default().Hours
+	})
 
 	// Initialize 42 oauth provider & service
-	oauth42 := oauth.NewProvider42(db, oauthConfig, cfg.App.SessionSecret, cfg.App.RedirectTokenURI, cfg.App.User42InfoURL)
-	oauthKeycloak := oauth.NewProviderKeycloak(db, keycloakConfig, cfg.App.SessionSecret, cfg.App.RedirectTokenURI, cfg.App.KeycloakUserInfoURL)
-	oauthService := oauth.NewService(oauth42, oauthKeycloak)
+	oauth42 := oauth.NewProvider42(db, oauthConfig, cfg.App.SessionSecret, cfg.App.RedirectTokenURI, cfg.App.User42InfoURL, accessPolicyOf(cfg), campuses)
+
+	discoveryCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	oauthKeycloak, err := oauth.NewProviderKeycloak(
+		discoveryCtx,
+		db,
+		keycloakConfig,
+		cfg.App.KeycloakIssuerURL,
+		cfg.App.SessionSecret,
+		cfg.App.RedirectTokenURI,
+		cfg.App.KeycloakStaffRoles,
+		campuses,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize keycloak provider: %w", err)
+	}
+	// Initialize audit log
+	auditService := audit.NewService(db)
+
+	// Initialize failed login tracking and lockouts
+	loginGuard := service.NewLoginGuardService(db, auditService, lockoutPolicyOf(cfg))
+	oauthService := oauth.NewService(oauth42, oauthKeycloak, loginGuard)
 
 	// Initialize auth service for app (JWT)
-	authService := auth.NewService(cfg.App.JWTSecret)
+	authService := auth.NewService(cfg.App.JWTSecret, db)
+
+	// Initialize opening hours and campus admin services
+	openingHoursService := service.NewOpeningHoursService(db, campuses, auditService)
+	campusService := service.NewCampusService(db, campuses, auditService)
 
 	// Initialize reservation service
-	reservationService := service.NewReservationService(db, emailService, calendarService)
+	reservationService := service.NewReservationService(db, emailService, calendarService, auditService, campuses)
+
+	// Initialize login access rule service
+	accessRuleService := service.NewAccessRuleService(db, auditService)
+
+	// Initialize role service
+	roleService := service.NewRoleService(db, auditService)
+
+	// Initialize personal access token service
+	apiTokenService := service.NewAPITokenService(db, auditService)
+
+	// Initialize user admin service
+	userService := service.NewUserService(db, authService, calendarService, auditService, campuses)
+
+	// Initialize room display service
+	displayService := service.NewDisplayService(db, auditService)
+
+	// Initialize room blackout service
+	blackoutService := service.NewBlackoutService(db, emailService, calendarService, auditService, campuses)
+
+	// Dependencies are checked in the background, for the readiness probe
+	// and the health report. Only the database is critical.
+	healthChecker := health.NewChecker(cfg.Server.HealthCheckInterval, cfg.Server.HealthCheckTimeout,
+		health.Check{Name: "database", Critical: true, Run: db.PingContext},
+		health.Check{Name: "calendar", Run: func(ctx context.Context) error {
+			if err := calendarService.Err(); err != nil {
+				return err
+			}
+			return calendarService.Get().HealthCheck(ctx)
+		}},
+		health.Check{Name: "email", Run: func(context.Context) error {
+			return emailService.Err()
+		}},
+	)
+
+	// Configured origins and the origins of campuses may call the API
+	cors := middleware.NewCors(corsOptionsOf(cfg), campuses.Origins)
 
 	return &API{
 		DB:              db,
@@ -95,5 +203,84 @@ func New(cfg *config.Config, db *database.DB) (*API, error) {
 		EmailService:    emailService,
 		CalendarService: calendarService,
 		Reservation:     reservationService,
+		AccessRules:     accessRuleService,
+		Roles:           roleService,
+		APITokens:       apiTokenService,
+		Displays:        displayService,
+		Audit:           auditService,
+		Users:           userService,
+		Blackouts:       blackoutService,
+		OpeningHours:    openingHoursService,
+		CampusAdmin:     campusService,
+		Campuses:        campuses,
+		LoginGuard:      loginGuard,
+		RateLimits:      rateLimits,
+		OAuthLimiter:    oauthLimiter,
+		APIIPLimiter:    apiIPLimiter,
+		APILimiter:      apiLimiter,
+		Cors:            cors,
+		ClientIPs:       clientIPs,
+		Health:          healthChecker,
 	}, nil
 }
+
+// newRateLimitStore creates the configured rate limiter store, checking
+// that Redis is reachable when it is used.
+func newRateLimitStore(ctx context.Context, cfg config.RateLimitConfig) (ratelimit.Store, error) {
+	switch cfg.Store {
+	case "memory", "":
+		return ratelimit.NewMemoryStore(), nil
+	case "redis":
+		opts, err := redis.ParseURL(cfg.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+		}
+		client := redis.NewClient(opts)
+		if err := client.Ping(ctx).Err(); err != nil {
+			return nil, fmt.Errorf("failed to reach redis: %w", err)
+		}
+		return ratelimit.NewRedisStore(client, "bookme:ratelimit:"), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q, expected memory or redis", cfg.Store)
+	}
+}
+
+// NewCalendar creates the Google Calendar service, or a null one when it is
+// disabled or can't be created.
+func NewCalendar(cfg *config.Config) (google.Calendar, integration.State) {
+	if !cfg.Google.Enabled {
+		return google.NullCalendar{}, integration.Disabled()
+	}
+	calendar, err := google.NewCalendarService(
+		cfg.Google.CredentialsBase64,
+		cfg.Google.CalendarScope,
+		cfg.Google.CalendarID,
+	)
+	if err != nil {
+		slog.Error("failed to initialize calendar service, running without it", "error", err)
+		return google.NullCalendar{}, integration.Unavailable(err)
+	}
+	return calendar, integration.Available()
+}
+
+// NewEmail creates the email service, or a null one when it is disabled or
+// can't be created.
+func NewEmail(cfg *config.Config) (email.Sender, integration.State) {
+	if !cfg.Email.Enabled {
+		return email.NullSender{}, integration.Disabled()
+	}
+	sender, err := email.NewService(email.Config{
+		SMTPHost:     cfg.Email.SMTPHost,
+		SMTPPort:     cfg.Email.SMTPPort,
+		SMTPUsername: cfg.Email.SMTPUsername,
+		SMTPPassword: cfg.Email.SMTPPassword,
+		FromEmail:    cfg.Email.FromEmail,
+		FromName:     cfg.Email.FromName,
+		UseTLS:       cfg.Email.UseTLS,
+	})
+	if err != nil {
+		slog.Error("failed to initialize email service, running without it", "error", err)
+		return email.NullSender{}, integration.Unavailable(err)
+	}
+	return sender, integration.Available()
+}
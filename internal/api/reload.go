@@ -0,0 +1,150 @@
+package api
+
+import (
+	"log/slog"
+
+	"github.com/IbnBaqqi/book-me/internal/config"
+	"github.com/IbnBaqqi/book-me/internal/email"
+	"github.com/IbnBaqqi/book-me/internal/google"
+	"github.com/IbnBaqqi/book-me/internal/integration"
+	"github.com/IbnBaqqi/book-me/internal/middleware"
+	"github.com/IbnBaqqi/book-me/internal/oauth"
+	"github.com/IbnBaqqi/book-me/internal/ratelimit"
+	"github.com/IbnBaqqi/book-me/internal/service"
+)
+
+// Reload applies the settings that can change while serving: the 42 login
+// access policy, login lockouts, CORS, rate limits and turning calendar and
+// email on or off. Other settings, like the port, secrets or the rate limit
+// store, need a restart.
+func (a *API) Reload(cfg *config.Config) {
+	a.syncCalendar(cfg)
+	a.syncEmail(cfg)
+	a.Oauth.SetAccessPolicy(accessPolicyOf(cfg))
+	a.LoginGuard.SetPolicy(lockoutPolicyOf(cfg))
+	a.Cors.SetOptions(corsOptionsOf(cfg))
+	a.OAuthLimiter.SetLimits(limitOf(cfg.RateLimit.OAuth))
+	a.APIIPLimiter.SetLimits(limitOf(cfg.RateLimit.APIIP))
+	a.APILimiter.SetLimits(limitOf(cfg.RateLimit.API), rateLimitPoliciesOf(cfg)...)
+
+	slog.Info("configuration reloaded",
+		"rate_limit_rules", len(cfg.RateLimit.Rules),
+		"cors_origins", len(cfg.CORS.Origins),
+	)
+}
+
+// RotateSecrets applies the secrets that changed, listed by environment
+// variable. Secrets that can't be swapped while serving, like the database
+// URL, are logged as needing a restart.
+func (a *API) RotateSecrets(cfg *config.Config, changed []string) {
+	for _, env := range changed {
+		var err error
+		switch env {
+		case "JWT_SECRET":
+			a.Auth.SetSecret(cfg.App.JWTSecret)
+		case "SECRET", "KEYCLOAK_CLIENT_SECRET", "SESSION_SECRET":
+			a.Oauth.SetSecrets(cfg.App.ClientSecret, cfg.App.KeycloakClientSecret, cfg.App.SessionSecret)
+		case "SMTP_PASSWORD":
+			if sender, ok := a.EmailService.Get().(*email.Service); ok {
+				err = sender.SetPassword(cfg.Email.SMTPPassword)
+			} else {
+				a.syncEmail(cfg)
+			}
+		case "GOOGLE_CREDENTIALS_BASE64":
+			if calendar, ok := a.CalendarService.Get().(*google.CalendarService); ok {
+				err = calendar.SetCredentials(cfg.Google.CredentialsBase64)
+			} else {
+				a.syncCalendar(cfg)
+			}
+		case "VAULT_TOKEN":
+			// Only used to read the other secrets
+		default:
+			slog.Warn("secret changed, restart to apply it", "secret", env)
+			continue
+		}
+		if err != nil {
+			slog.Error("failed to rotate secret", "secret", env, "error", err)
+			continue
+		}
+		slog.Info("secret rotated", "secret", env)
+	}
+}
+
+// RetryIntegrations starts calendar and email again when they are enabled
+// but failed to start, and retries the work they failed to take while
+// briefly unavailable.
+func (a *API) RetryIntegrations(cfg *config.Config) {
+	a.CalendarService.Retry(func() (google.Calendar, integration.State) {
+		return NewCalendar(cfg)
+	})
+	a.EmailService.Retry(func() (email.Sender, integration.State) {
+		return NewEmail(cfg)
+	})
+}
+
+// syncCalendar creates the calendar service again when it is enabled but
+// not running, because it was disabled or failed to start, or replaces it
+// by a null one once it is disabled.
+func (a *API) syncCalendar(cfg *config.Config) {
+	_, running := a.CalendarService.Get().(*google.CalendarService)
+	if running != cfg.Google.Enabled {
+		a.CalendarService.Set(NewCalendar(cfg))
+	}
+}
+
+// syncEmail is syncCalendar for the email service.
+func (a *API) syncEmail(cfg *config.Config) {
+	_, running := a.EmailService.Get().(*email.Service)
+	if running != cfg.Email.Enabled {
+		a.EmailService.Set(NewEmail(cfg))
+	}
+}
+
+func accessPolicyOf(cfg *config.Config) oauth.AccessPolicy {
+	return oauth.AccessPolicy{
+		CampusIDs:  cfg.Access.CampusIDs,
+		CursusIDs:  cfg.Access.CursusIDs,
+		PoolYears:  cfg.Access.PoolYears,
+		PoolMonths: cfg.Access.PoolMonths,
+	}
+}
+
+func lockoutPolicyOf(cfg *config.Config) service.LockoutPolicy {
+	return service.LockoutPolicy{
+		Threshold: cfg.Lockout.Threshold,
+		Window:    cfg.Lockout.Window,
+		Base:      cfg.Lockout.Base,
+		Max:       cfg.Lockout.Max,
+	}
+}
+
+func corsOptionsOf(cfg *config.Config) middleware.CorsOptions {
+	return middleware.CorsOptions{
+		Origins:        cfg.CORS.Origins,
+		Methods:        cfg.CORS.Methods,
+		Headers:        cfg.CORS.Headers,
+		ExposedHeaders: cfg.CORS.ExposedHeaders,
+		MaxAge:         cfg.CORS.MaxAge,
+	}
+}
+
+// rateLimitPoliciesOf converts the rules overriding the API limit.
+func rateLimitPoliciesOf(cfg *config.Config) []middleware.RateLimitPolicy {
+	policies := make([]middleware.RateLimitPolicy, 0, len(cfg.RateLimit.Rules))
+	for _, rule := range cfg.RateLimit.Rules {
+		policies = append(policies, middleware.RateLimitPolicy{
+			Route: rule.Route,
+			Role:  rule.Role,
+			Limit: limitOf(rule.Limit),
+		})
+	}
+	return policies
+}
+
+// limitOf converts a configured limit to the limiter rate and burst.
+func limitOf(l config.RateLimit) ratelimit.Limit {
+	return ratelimit.Limit{
+		Rate:  float64(l.Requests) / l.Period.Seconds(),
+		Burst: l.Requests,
+	}
+}
@@ -3,11 +3,12 @@ package api
 
 import (
 	"net/http"
-	"time"
 
 	"github.com/IbnBaqqi/book-me/internal/handler"
+	"github.com/IbnBaqqi/book-me/internal/metrics"
 	"github.com/IbnBaqqi/book-me/internal/middleware"
-	"golang.org/x/time/rate"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+	"github.com/IbnBaqqi/book-me/internal/tracing"
 )
 
 // SetupRoutes configures all HTTP routes and middleware
@@ -22,45 +23,111 @@ func SetupRoutes(cfg *API) http.Handler {
 		cfg.EmailService,
 		cfg.CalendarService,
 		cfg.Reservation,
+		cfg.AccessRules,
+		cfg.Roles,
+		cfg.APITokens,
+		cfg.Displays,
+		cfg.Audit,
+		cfg.Users,
+		cfg.Blackouts,
+		cfg.OpeningHours,
+		cfg.CampusAdmin,
+		cfg.Campuses,
+		cfg.LoginGuard,
+		cfg.Health,
 	)
 
-	// Create rate limiters
-	oauthLimiter := middleware.NewRateLimiter(rate.Every(1*time.Second), 20, false)
-	apiLimiter := middleware.NewRateLimiter(rate.Every(1*time.Second), 100, false)
-
 	// Create auth middleware
 	authenticate := middleware.Authenticate(cfg.Auth)
 
-	// Health check
-	mux.HandleFunc("GET /api/v1/health", h.Health)
+	// protected wraps a handler with rate limiting per IP, so guessed tokens
+	// are limited before they are looked up, authentication, rate limiting
+	// per user and the permission required to call it
+	protected := func(p rbac.Permission, next http.HandlerFunc) http.Handler {
+		return cfg.APIIPLimiter.Limit(
+			authenticate(
+				cfg.APILimiter.Limit(
+					middleware.RequirePermission(p)(next))))
+	}
+
+	// Probes, answered from cached checks so they never hit dependencies
+	mux.HandleFunc("GET /livez", h.Livez)
+	mux.HandleFunc("GET /readyz", h.Readyz)
+
+	// Full dependency report
+	mux.Handle("GET /api/v1/health", protected(rbac.HealthRead, h.Health))
+
+	// Prometheus metrics
+	mux.Handle("GET /metrics", metrics.Handler())
 
 	// Authentication routes
-	mux.Handle("GET /auth/42/login", oauthLimiter.Limit(http.HandlerFunc(h.Login42)))
-	mux.Handle("GET /auth/42/callback", oauthLimiter.Limit(http.HandlerFunc(h.Callback42)))
-	mux.Handle("GET /auth/keycloak/login", oauthLimiter.Limit(http.HandlerFunc(h.LoginKeycloak)))
-	mux.Handle("GET /auth/keycloak/callback", oauthLimiter.Limit(http.HandlerFunc(h.CallbackKeycloak)))
+	mux.Handle("GET /auth/42/login", cfg.OAuthLimiter.Limit(http.HandlerFunc(h.Login42)))
+	mux.Handle("GET /auth/42/callback", cfg.OAuthLimiter.Limit(http.HandlerFunc(h.Callback42)))
+	mux.Handle("GET /auth/keycloak/login", cfg.OAuthLimiter.Limit(http.HandlerFunc(h.LoginKeycloak)))
+	mux.Handle("GET /auth/keycloak/callback", cfg.OAuthLimiter.Limit(http.HandlerFunc(h.CallbackKeycloak)))
 
 	// Reservation routes
-	mux.Handle(
-		"POST /api/v1/reservations",
-		apiLimiter.Limit(
-			authenticate(
-				middleware.RequireAuth(
-					http.HandlerFunc(h.CreateReservation)))))
+	mux.Handle("POST /api/v1/reservations", protected(rbac.ReservationsCreate, h.CreateReservation))
+	mux.Handle("GET /api/v1/reservations", protected(rbac.ReservationsRead, h.GetReservations))
+	mux.Handle("DELETE /api/v1/reservations/{id}", protected(rbac.ReservationsCancel, h.CancelReservation))
 
-	mux.Handle(
-		"GET /api/v1/reservations",
-		apiLimiter.Limit(
-			authenticate(
-				middleware.RequireAuth(
-					http.HandlerFunc(h.GetReservations)))))
+	// Campus and room routes
+	mux.Handle("GET /api/v1/campuses", protected(rbac.ReservationsRead, h.ListCampuses))
+	mux.Handle("GET /api/v1/rooms", protected(rbac.ReservationsRead, h.ListRooms))
 
-	mux.Handle(
-		"DELETE /api/v1/reservations/{id}",
-		apiLimiter.Limit(
-			authenticate(
-				middleware.RequireAuth(
-					http.HandlerFunc(h.CancelReservation)))))
+	// Opening hours, with holidays and special hours applied
+	mux.Handle("GET /api/v1/opening-hours", protected(rbac.ReservationsRead, h.GetOpeningHours))
+
+	// Personal access token routes
+	mux.Handle("POST /api/v1/tokens", protected(rbac.TokensManage, h.CreateAPIToken))
+	mux.Handle("GET /api/v1/tokens", protected(rbac.TokensManage, h.ListAPITokens))
+	mux.Handle("DELETE /api/v1/tokens/{id}", protected(rbac.TokensManage, h.RevokeAPIToken))
+
+	// Room display routes
+	mux.Handle("GET /api/v1/rooms/{id}/display", protected(rbac.RoomsDisplay, h.GetRoomDisplay))
+	mux.Handle("POST /api/v1/rooms/{id}/displays", protected(rbac.RoomsManage, h.CreateDisplayDevice))
+	mux.Handle("GET /api/v1/rooms/{id}/displays", protected(rbac.RoomsManage, h.ListDisplayDevices))
+	mux.Handle("DELETE /api/v1/rooms/{id}/displays/{deviceId}", protected(rbac.RoomsManage, h.RevokeDisplayDevice))
+
+	// Room blackout routes
+	mux.Handle("POST /api/v1/rooms/{id}/blackouts", protected(rbac.RoomsManage, h.CreateBlackout))
+	mux.Handle("GET /api/v1/rooms/{id}/blackouts", protected(rbac.RoomsManage, h.ListBlackouts))
+	mux.Handle("DELETE /api/v1/rooms/{id}/blackouts/{blackoutId}", protected(rbac.RoomsManage, h.DeleteBlackout))
+
+	// Admin routes
+	mux.Handle("GET /api/v1/admin/access-rules", protected(rbac.AccessManage, h.ListAccessRules))
+	mux.Handle("PUT /api/v1/admin/access-rules/{login}", protected(rbac.AccessManage, h.SetAccessRule))
+	mux.Handle("DELETE /api/v1/admin/access-rules/{login}", protected(rbac.AccessManage, h.DeleteAccessRule))
+
+	mux.Handle("GET /api/v1/admin/login-lockouts", protected(rbac.AccessManage, h.ListLoginLockouts))
+	mux.Handle("DELETE /api/v1/admin/login-lockouts/{kind}/{subject}", protected(rbac.AccessManage, h.UnblockLogin))
+
+	mux.Handle("GET /api/v1/admin/opening-hours", protected(rbac.HoursManage, h.ListOpeningHoursExceptions))
+	mux.Handle("POST /api/v1/admin/opening-hours/import", protected(rbac.HoursManage, h.ImportOpeningHours))
+	mux.Handle("PUT /api/v1/admin/opening-hours/{date}", protected(rbac.HoursManage, h.SetOpeningHours))
+	mux.Handle("DELETE /api/v1/admin/opening-hours/{date}", protected(rbac.HoursManage, h.DeleteOpeningHours))
+
+	mux.Handle("GET /api/v1/admin/campuses", protected(rbac.CampusesManage, h.ListCampusSettings))
+	mux.Handle("POST /api/v1/admin/campuses", protected(rbac.CampusesManage, h.CreateCampus))
+	mux.Handle("PUT /api/v1/admin/campuses/{id}", protected(rbac.CampusesManage, h.UpdateCampus))
+	mux.Handle("POST /api/v1/admin/campuses/{id}/rooms", protected(rbac.CampusesManage, h.CreateRoom))
+
+	mux.Handle("GET /api/v1/admin/audit", protected(rbac.AuditRead, h.ListAuditLog))
+
+	mux.Handle("GET /api/v1/admin/users", protected(rbac.UsersManage, h.ListUsers))
+	mux.Handle("GET /api/v1/admin/users/{id}", protected(rbac.UsersManage, h.GetUser))
+	mux.Handle("GET /api/v1/admin/users/{id}/reservations", protected(rbac.UsersManage, h.ListUserReservations))
+	mux.Handle("PUT /api/v1/admin/users/{id}/suspension", protected(rbac.UsersManage, h.SuspendUser))
+	mux.Handle("DELETE /api/v1/admin/users/{id}/suspension", protected(rbac.UsersManage, h.UnsuspendUser))
+	mux.Handle("DELETE /api/v1/admin/users/{id}", protected(rbac.UsersDelete, h.DeleteUser))
+
+	mux.Handle("GET /api/v1/admin/users/{id}/roles", protected(rbac.RolesAssign, h.ListUserRoles))
+	mux.Handle("POST /api/v1/admin/users/{id}/roles", protected(rbac.RolesAssign, h.AssignRole))
+	mux.Handle("DELETE /api/v1/admin/users/{id}/roles/{assignmentId}", protected(rbac.RolesAssign, h.RevokeRole))
 
-	return middleware.Cors(mux)
+	// Tracing and metrics must wrap the mux directly to see the matched route
+	return cfg.Cors.Handler(
+		middleware.RequestMeta(mux, cfg.ClientIPs)(
+			tracing.Middleware(
+				metrics.Middleware(mux))))
 }
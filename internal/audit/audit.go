@@ -0,0 +1,134 @@
+// Package audit records an append-only trail of reservation, admin and login actions.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/IbnBaqqi/book-me/internal/database"
+)
+
+// Action is the kind of change an entry records.
+type Action string
+
+// Actions
+const (
+	ReservationCreate  Action = "reservation.create"
+	ReservationCancel  Action = "reservation.cancel"
+	RoleAssign         Action = "role.assign"
+	RoleRevoke         Action = "role.revoke"
+	AccessRuleSet      Action = "access_rule.set"
+	AccessRuleDelete   Action = "access_rule.delete"
+	APITokenCreate     Action = "api_token.create"
+	APITokenRevoke     Action = "api_token.revoke"
+	RoomDisplayAdd     Action = "room.display_add"
+	RoomDisplayRevoke  Action = "room.display_revoke"
+	RoomBlackoutAdd    Action = "room.blackout_add"
+	RoomBlackoutDelete Action = "room.blackout_delete"
+	OpeningHoursSet    Action = "opening_hours.set"
+	OpeningHoursDelete Action = "opening_hours.delete"
+	OpeningHoursImport Action = "opening_hours.import"
+	CampusCreate       Action = "campus.create"
+	CampusUpdate       Action = "campus.update"
+	RoomCreate         Action = "room.create"
+	UserSuspend        Action = "user.suspend"
+	UserUnsuspend      Action = "user.unsuspend"
+	UserAnonymize      Action = "user.anonymize"
+	UserDelete         Action = "user.delete"
+	LoginSuccess       Action = "auth.login"
+	LoginFailure       Action = "auth.login_failed"
+	LoginUnblock       Action = "auth.unblock"
+)
+
+// Target types
+const (
+	TargetReservation    = "reservation"
+	TargetRoleAssignment = "role_assignment"
+	TargetAccessRule     = "access_rule"
+	TargetAPIToken       = "api_token"
+	TargetDisplayDevice  = "display_device"
+	TargetRoomBlackout   = "room_blackout"
+	TargetOpeningHours   = "opening_hours"
+	TargetCampus         = "campus"
+	TargetRoom           = "room"
+	TargetUser           = "user"
+	TargetLogin          = "login"
+	TargetLoginLockout   = "login_lockout"
+)
+
+// Entry is a single audited action. Before and After are marshalled to JSON
+// and hold the state of the target around the change.
+type Entry struct {
+	Action     Action
+	ActorID    int64 // user performing the action, 0 for anonymous or system
+	UserID     int64 // user the action was performed on
+	RoomID     int64
+	CampusID   int64 // 0 for the campus of the room, the user acted on or the actor
+	TargetType string
+	TargetID   string
+	Before     any
+	After      any
+}
+
+// Service writes and queries the audit log.
+type Service struct {
+	db *database.DB
+}
+
+// NewService creates a new audit service.
+func NewService(db *database.DB) *Service {
+	return &Service{
+		db: db,
+	}
+}
+
+// Record appends an entry, taking IP and request ID from the request metadata
+// in ctx. Failures are logged and never fail the audited action. A nil
+// service records nothing.
+func (s *Service) Record(ctx context.Context, e Entry) {
+	if s == nil {
+		return
+	}
+
+	before, err := json.Marshal(e.Before)
+	if err != nil {
+		slog.Error("failed to marshal audit state", "action", e.Action, "error", err)
+		before = json.RawMessage("null")
+	}
+	after, err := json.Marshal(e.After)
+	if err != nil {
+		slog.Error("failed to marshal audit state", "action", e.Action, "error", err)
+		after = json.RawMessage("null")
+	}
+
+	meta := MetaFromContext(ctx)
+
+	// The entry must be written even if the request was cancelled meanwhile
+	err = s.db.CreateAuditEntry(context.WithoutCancel(ctx), database.CreateAuditEntryParams{
+		Action:     string(e.Action),
+		ActorID:    nullID(e.ActorID),
+		UserID:     nullID(e.UserID),
+		RoomID:     nullID(e.RoomID),
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Before:     before,
+		After:      after,
+		Ip:         meta.IP,
+		RequestID:  meta.RequestID,
+		CampusID:   nullID(e.CampusID),
+	})
+	if err != nil {
+		slog.Error("failed to write audit entry",
+			"action", e.Action,
+			"actor_id", e.ActorID,
+			"target", e.TargetType+":"+e.TargetID,
+			"error", err,
+		)
+	}
+}
+
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
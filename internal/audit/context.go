@@ -0,0 +1,22 @@
+package audit
+
+import "context"
+
+// Meta is the request metadata recorded with every entry.
+type Meta struct {
+	IP        string
+	RequestID string
+}
+
+type metaKey struct{}
+
+// WithMeta saves the request metadata into context.
+func WithMeta(ctx context.Context, meta Meta) context.Context {
+	return context.WithValue(ctx, metaKey{}, meta)
+}
+
+// MetaFromContext returns the request metadata saved in context, if any.
+func MetaFromContext(ctx context.Context) Meta {
+	meta, _ := ctx.Value(metaKey{}).(Meta)
+	return meta
+}
@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/database"
+)
+
+func TestWriteCSV(t *testing.T) {
+	entries := []database.AuditLog{
+		{
+			ID:         1,
+			OccurredAt: time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC),
+			Action:     string(ReservationCancel),
+			ActorID:    sql.NullInt64{Int64: 7, Valid: true},
+			UserID:     sql.NullInt64{Int64: 42, Valid: true},
+			RoomID:     sql.NullInt64{Int64: 1, Valid: true},
+			TargetType: TargetReservation,
+			TargetID:   "123",
+			Before:     json.RawMessage(`{"id":123,"status":"RESERVED"}`),
+			After:      json.RawMessage("null"),
+			Ip:         "10.0.0.1",
+			RequestID:  "req-1",
+		},
+		{
+			ID:         2,
+			OccurredAt: time.Date(2026, 2, 23, 11, 0, 0, 0, time.UTC),
+			Action:     string(LoginFailure),
+			TargetType: TargetLogin,
+			TargetID:   "=HYPERLINK(\"http://evil\")",
+			Before:     json.RawMessage("null"),
+			After:      json.RawMessage(`{"error":"access denied"}`),
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, entries); err != nil {
+		t.Fatalf("failed to write csv: %v", err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read csv back: %v", err)
+	}
+
+	if len(records) != 3 {
+		t.Fatalf("expected header and 2 rows, got %d", len(records))
+	}
+	if records[0][0] != "id" || len(records[0]) != len(csvHeader) {
+		t.Errorf("unexpected header %v", records[0])
+	}
+
+	first := records[1]
+	if first[1] != "2026-02-23T10:00:00Z" || first[3] != "7" || first[8] != `{"id":123,"status":"RESERVED"}` {
+		t.Errorf("unexpected row %v", first)
+	}
+
+	second := records[2]
+	if second[3] != "" || second[5] != "" {
+		t.Errorf("expected empty IDs for anonymous entries, got %v", second)
+	}
+	if second[7] != `'=HYPERLINK("http://evil")` {
+		t.Errorf("expected formula to be neutralised, got %q", second[7])
+	}
+}
@@ -10,9 +10,11 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
@@ -28,13 +30,21 @@ type CustomClaims struct {
 type Service struct {
 	JwtSecret      string
 	AccessTokenTTL time.Duration
+	store          Store
+
+	mu             sync.RWMutex
+	previousSecret string // still accepted after a rotation
 }
 
 // User represents an authenticated User.
 type User struct {
-	ID   int64
-	Role string
-	Name string
+	ID       int64
+	Role     string
+	Name     string
+	CampusID int64 // 0 when unknown, the default campus applies
+	Grants   rbac.Grants
+	TokenID  int64 // set when authenticated with a personal access token
+	DeviceID int64 // set for room display devices, which have no user ID
 }
 
 type contextKey struct{}
@@ -52,6 +62,8 @@ var (
 	ErrEmptyBearerToken     = errors.New("bearer token is empty")
 	ErrInvalidBearerToken   = errors.New("bearer token is incorrect")
 	ErrNoAuthHeaderIncluded = errors.New("no auth header included in request")
+	ErrUserSuspended        = errors.New("user is suspended")
+	ErrRevokedToken         = errors.New("token has been revoked")
 )
 
 // WithUser save user into context
@@ -66,11 +78,26 @@ func UserFromContext(ctx context.Context) (User, bool) {
 }
 
 // NewService creates a new auth service(JWT).
-func NewService(secret string) *Service {
+// The store is used to resolve role grants, with a nil store users only
+// hold their base role.
+func NewService(secret string, store Store) *Service {
 	return &Service{
 		JwtSecret:      secret,
 		AccessTokenTTL: 7 * 24 * time.Hour,
+		store:          store,
+	}
+}
+
+// SetSecret rotates the signing secret. Tokens signed with the previous
+// secret stay valid until they expire or the secret is rotated again.
+func (s *Service) SetSecret(secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if secret == s.JwtSecret {
+		return
 	}
+	s.previousSecret = s.JwtSecret
+	s.JwtSecret = secret
 }
 
 // IssueAccessToken create a jwt token
@@ -86,7 +113,10 @@ func (s *Service) IssueAccessToken(user database.User) (string, error) {
 			Issuer:    string(tokenTypeAccess),
 		},
 	}
+	s.mu.RLock()
 	signingKey := []byte(s.JwtSecret)
+	s.mu.RUnlock()
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	jwtToken, err := token.SignedString(signingKey)
 	if err != nil {
@@ -97,17 +127,14 @@ func (s *Service) IssueAccessToken(user database.User) (string, error) {
 
 // VerifyAccessToken validate the signature of the JWT and extract the claims
 func (s *Service) VerifyAccessToken(tokenStr string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(
-		tokenStr,
-		&CustomClaims{},
-		func(token *jwt.Token) (any, error) {
-			// enforce signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, ErrInvalidToken
-			}
-			return []byte(s.JwtSecret), nil
-		},
-	)
+	s.mu.RLock()
+	current, previous := s.JwtSecret, s.previousSecret
+	s.mu.RUnlock()
+
+	token, err := parseAccessToken(tokenStr, current)
+	if errors.Is(err, jwt.ErrTokenSignatureInvalid) && previous != "" {
+		token, err = parseAccessToken(tokenStr, previous)
+	}
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -124,6 +151,21 @@ func (s *Service) VerifyAccessToken(tokenStr string) (*CustomClaims, error) {
 	return claims, nil
 }
 
+// parseAccessToken parses a token signed with secret.
+func parseAccessToken(tokenStr, secret string) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(
+		tokenStr,
+		&CustomClaims{},
+		func(token *jwt.Token) (any, error) {
+			// enforce signing method
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, ErrInvalidToken
+			}
+			return []byte(secret), nil
+		},
+	)
+}
+
 // GetBearerToken return the Bearer Token from request header
 func GetBearerToken(headers http.Header) (string, error) {
 	authHeader := headers.Get("Authorization")
@@ -11,7 +11,7 @@ import (
 )
 
 func TestIssueAccessToken(t *testing.T) {
-	service := NewService("test-secret-key")
+	service := NewService("test-secret-key", nil)
 
 	testUser := database.User{
 		ID:   123,
@@ -43,7 +43,7 @@ func TestIssueAccessToken(t *testing.T) {
 }
 
 func TestVerifyAccessToken(t *testing.T) {
-	service := NewService("test-secret-key")
+	service := NewService("test-secret-key", nil)
 
 	testUser := database.User{
 		ID:   456,
@@ -112,8 +112,8 @@ func TestVerifyAccessToken(t *testing.T) {
 }
 
 func TestVerifyAccessToken_WrongSecret(t *testing.T) {
-	service1 := NewService("secret-1")
-	service2 := NewService("secret-2")
+	service1 := NewService("secret-1", nil)
+	service2 := NewService("secret-2", nil)
 
 	testUser := database.User{
 		ID:   789,
@@ -130,8 +130,33 @@ func TestVerifyAccessToken_WrongSecret(t *testing.T) {
 	}
 }
 
+func TestSetSecret(t *testing.T) {
+	service := NewService("secret-1", nil)
+	testUser := database.User{ID: 42, Name: "Rotated", Role: "STUDENT"}
+
+	oldToken, _ := service.IssueAccessToken(testUser)
+
+	service.SetSecret("secret-2")
+	newToken, _ := service.IssueAccessToken(testUser)
+
+	if _, err := service.VerifyAccessToken(oldToken); err != nil {
+		t.Errorf("expected token signed before the rotation to stay valid, got %v", err)
+	}
+	if _, err := NewService("secret-2", nil).VerifyAccessToken(newToken); err != nil {
+		t.Errorf("expected new tokens signed with the new secret, got %v", err)
+	}
+
+	service.SetSecret("secret-3")
+	if _, err := service.VerifyAccessToken(oldToken); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken two rotations later, got %v", err)
+	}
+	if _, err := service.VerifyAccessToken(newToken); err != nil {
+		t.Errorf("expected token of the previous secret to stay valid, got %v", err)
+	}
+}
+
 func TestVerifyAccessToken_ExpiredToken(t *testing.T) {
-	service := NewService("test-secret")
+	service := NewService("test-secret", nil)
 	service.AccessTokenTTL = -1 * time.Hour // Already expired
 
 	testUser := database.User{
@@ -268,7 +293,7 @@ func TestMakeRefreshToken(t *testing.T) {
 
 func TestNewService(t *testing.T) {
 	secret := "my-secret-key"
-	service := NewService(secret)
+	service := NewService(secret, nil)
 
 	if service.JwtSecret != secret {
 		t.Errorf("expected secret %s, got %s", secret, service.JwtSecret)
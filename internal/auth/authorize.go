@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+)
+
+// Store is the persistence the auth service needs to authenticate and authorize users.
+type Store interface {
+	GetUser(ctx context.Context, id int64) (database.User, error)
+	ListRoleAssignmentsByUser(ctx context.Context, userID int64) ([]database.RoleAssignment, error)
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (database.ApiToken, error)
+	TouchAPIToken(ctx context.Context, id int64) error
+	GetDisplayDeviceByHash(ctx context.Context, tokenHash string) (database.DisplayDevice, error)
+	TouchDisplayDevice(ctx context.Context, id int64) error
+	RevokeUserTokens(ctx context.Context, id int64) error
+}
+
+// ResolveUser builds the authenticated user from verified claims. The user
+// is loaded on every request, so suspensions, revoked tokens and role changes
+// apply immediately. With a nil store the claims are trusted as is.
+func (s *Service) ResolveUser(ctx context.Context, claims *CustomClaims) (User, error) {
+	id, err := strconv.ParseInt(claims.Subject, 10, 64)
+	if err != nil {
+		return User{}, fmt.Errorf("invalid user ID in token: %w", err)
+	}
+
+	if s.store == nil {
+		return User{
+			ID:     id,
+			Role:   claims.Role,
+			Name:   claims.Name,
+			Grants: rbac.Grants{{Role: rbac.Role(claims.Role)}},
+		}, nil
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	dbUser, err := s.activeUser(ctx, id, issuedAt)
+	if err != nil {
+		return User{}, err
+	}
+
+	grants, err := s.grants(ctx, dbUser.ID, dbUser.Role)
+	if err != nil {
+		return User{}, err
+	}
+
+	return User{
+		ID:       dbUser.ID,
+		Role:     dbUser.Role,
+		Name:     dbUser.Name,
+		CampusID: dbUser.CampusID,
+		Grants:   grants,
+	}, nil
+}
+
+// activeUser loads the user a token was issued to and checks that the user
+// is not suspended and the token was issued after the last revocation.
+func (s *Service) activeUser(ctx context.Context, id int64, issuedAt time.Time) (database.User, error) {
+	user, err := s.store.GetUser(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.User{}, ErrInvalidToken
+		}
+		return database.User{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.SuspendedAt.Valid {
+		return database.User{}, ErrUserSuspended
+	}
+
+	// Token timestamps have second precision
+	if user.TokensValidAfter.Valid && issuedAt.Before(user.TokensValidAfter.Time.Truncate(time.Second)) {
+		return database.User{}, ErrRevokedToken
+	}
+
+	return user, nil
+}
+
+// RevokeTokens invalidates every JWT and personal access token issued to the
+// user so far. Later logins and new tokens are not affected.
+func (s *Service) RevokeTokens(ctx context.Context, userID int64) error {
+	if s.store == nil {
+		return nil
+	}
+	if err := s.store.RevokeUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke tokens: %w", err)
+	}
+	return nil
+}
+
+// grants returns the base role grant followed by the user's role assignments.
+func (s *Service) grants(ctx context.Context, userID int64, baseRole string) (rbac.Grants, error) {
+	grants := rbac.Grants{{Role: rbac.Role(baseRole)}}
+	if s.store == nil {
+		return grants, nil
+	}
+
+	assignments, err := s.store.ListRoleAssignmentsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load role assignments: %w", err)
+	}
+	for _, a := range assignments {
+		grants = append(grants, rbac.Grant{
+			Role:   rbac.Role(a.Role),
+			RoomID: a.RoomID.Int64,
+		})
+	}
+	return grants, nil
+}
+
+// Has reports whether the user holds the permission in any scope.
+func (u User) Has(p rbac.Permission) bool {
+	return u.Grants.Has(p)
+}
+
+// Can reports whether the user holds the permission for the room.
+func (u User) Can(p rbac.Permission, roomID int64) bool {
+	return u.Grants.Can(p, roomID)
+}
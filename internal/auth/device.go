@@ -0,0 +1,38 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+)
+
+// authenticateDeviceToken looks the device token up by hash and returns
+// a read-only principal limited to the device's room.
+func (s *Service) authenticateDeviceToken(ctx context.Context, token string) (User, error) {
+	if s.store == nil {
+		return User{}, ErrInvalidToken
+	}
+
+	device, err := s.store.GetDisplayDeviceByHash(ctx, HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return User{}, ErrInvalidToken
+		}
+		return User{}, fmt.Errorf("failed to get display device: %w", err)
+	}
+
+	if err := s.store.TouchDisplayDevice(ctx, device.ID); err != nil {
+		slog.Warn("failed to record display device use", "device_id", device.ID, "error", err)
+	}
+
+	return User{
+		Role:     string(rbac.Display),
+		Name:     device.Name,
+		Grants:   rbac.Grants{{Role: rbac.Display, RoomID: device.RoomID}},
+		DeviceID: device.ID,
+	}, nil
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+)
+
+// Opaque token prefixes, so they can be told apart from JWTs in the
+// Authorization header.
+const (
+	PersonalTokenPrefix = "bm_pat_"
+	DeviceTokenPrefix   = "bm_dev_"
+)
+
+// tokenDisplayLen is how many random characters of an opaque token are
+// kept in clear to help users recognise it in listings.
+const tokenDisplayLen = 6
+
+// makeOpaqueToken generates a random token with the prefix and returns it
+// together with its hash and display prefix. Only the hash is stored.
+func makeOpaqueToken(tokenPrefix string) (token, hash, prefix string) {
+	token = tokenPrefix + MakeRefreshToken()
+	return token, HashToken(token), token[:len(tokenPrefix)+tokenDisplayLen]
+}
+
+// MakePersonalToken generates a new personal access token.
+func MakePersonalToken() (token, hash, prefix string) {
+	return makeOpaqueToken(PersonalTokenPrefix)
+}
+
+// MakeDeviceToken generates a new room display device token.
+func MakeDeviceToken() (token, hash, prefix string) {
+	return makeOpaqueToken(DeviceTokenPrefix)
+}
+
+// HashToken returns the hex SHA-256 of an opaque token.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsPersonalToken reports whether the bearer token is a personal access token.
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
+
+// IsDeviceToken reports whether the bearer token is a display device token.
+func IsDeviceToken(token string) bool {
+	return strings.HasPrefix(token, DeviceTokenPrefix)
+}
+
+// Authenticate resolves the user behind a bearer token, which is either
+// a JWT issued at login, a personal access token or a device token.
+func (s *Service) Authenticate(ctx context.Context, token string) (User, error) {
+	switch {
+	case IsPersonalToken(token):
+		return s.authenticatePersonalToken(ctx, token)
+	case IsDeviceToken(token):
+		return s.authenticateDeviceToken(ctx, token)
+	}
+
+	claims, err := s.VerifyAccessToken(token)
+	if err != nil {
+		return User{}, err
+	}
+	return s.ResolveUser(ctx, claims)
+}
+
+// authenticatePersonalToken looks the token up by hash and returns its owner
+// with grants limited to the token scopes.
+func (s *Service) authenticatePersonalToken(ctx context.Context, token string) (User, error) {
+	if s.store == nil {
+		return User{}, ErrInvalidToken
+	}
+
+	apiToken, err := s.store.GetAPITokenByHash(ctx, HashToken(token))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return User{}, ErrInvalidToken
+		}
+		return User{}, fmt.Errorf("failed to get api token: %w", err)
+	}
+
+	if !time.Now().Before(apiToken.ExpiresAt) {
+		return User{}, ErrExpiredToken
+	}
+
+	owner, err := s.activeUser(ctx, apiToken.UserID, apiToken.CreatedAt)
+	if err != nil {
+		return User{}, err
+	}
+
+	grants, err := s.grants(ctx, owner.ID, owner.Role)
+	if err != nil {
+		return User{}, err
+	}
+
+	scopes := make([]rbac.Permission, 0, len(apiToken.Scopes))
+	for _, name := range apiToken.Scopes {
+		if scope, ok := rbac.ParseScope(name); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	// Last use is informational, a failed update must not reject the request
+	if err := s.store.TouchAPIToken(ctx, apiToken.ID); err != nil {
+		slog.Warn("failed to record api token use", "token_id", apiToken.ID, "error", err)
+	}
+
+	return User{
+		ID:       owner.ID,
+		Role:     owner.Role,
+		Name:     owner.Name,
+		CampusID: owner.CampusID,
+		Grants:   grants.Restrict(scopes),
+		TokenID:  apiToken.ID,
+	}, nil
+}
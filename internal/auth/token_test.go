@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fakeStore is an in-memory Store for tests.
+type fakeStore struct {
+	users   map[int64]database.User
+	tokens  map[string]database.ApiToken
+	devices map[string]database.DisplayDevice
+	touched []int64
+}
+
+func (f *fakeStore) GetUser(_ context.Context, id int64) (database.User, error) {
+	user, ok := f.users[id]
+	if !ok {
+		return database.User{}, sql.ErrNoRows
+	}
+	return user, nil
+}
+
+func (f *fakeStore) ListRoleAssignmentsByUser(_ context.Context, _ int64) ([]database.RoleAssignment, error) {
+	return nil, nil
+}
+
+func (f *fakeStore) GetAPITokenByHash(_ context.Context, tokenHash string) (database.ApiToken, error) {
+	token, ok := f.tokens[tokenHash]
+	if !ok {
+		return database.ApiToken{}, sql.ErrNoRows
+	}
+	return token, nil
+}
+
+func (f *fakeStore) TouchAPIToken(_ context.Context, id int64) error {
+	f.touched = append(f.touched, id)
+	return nil
+}
+
+func (f *fakeStore) GetDisplayDeviceByHash(_ context.Context, tokenHash string) (database.DisplayDevice, error) {
+	device, ok := f.devices[tokenHash]
+	if !ok {
+		return database.DisplayDevice{}, sql.ErrNoRows
+	}
+	return device, nil
+}
+
+func (f *fakeStore) TouchDisplayDevice(_ context.Context, id int64) error {
+	f.touched = append(f.touched, id)
+	return nil
+}
+
+func (f *fakeStore) RevokeUserTokens(_ context.Context, id int64) error {
+	user := f.users[id]
+	user.TokensValidAfter = sql.NullTime{Time: time.Now(), Valid: true}
+	f.users[id] = user
+	return nil
+}
+
+func TestMakePersonalToken(t *testing.T) {
+	token, hash, prefix := MakePersonalToken()
+
+	if !IsPersonalToken(token) {
+		t.Errorf("expected token to start with %s, got %s", PersonalTokenPrefix, token)
+	}
+	if hash != HashToken(token) {
+		t.Error("expected hash to match the token")
+	}
+	if len(hash) != 64 {
+		t.Errorf("expected hex sha256 hash, got %s", hash)
+	}
+	if !strings.HasPrefix(token, prefix) || len(prefix) >= len(token) {
+		t.Errorf("expected prefix to be the start of the token, got %s", prefix)
+	}
+}
+
+func TestAuthenticate_PersonalToken(t *testing.T) {
+	validToken, validHash, _ := MakePersonalToken()
+	expiredToken, expiredHash, _ := MakePersonalToken()
+
+	store := &fakeStore{
+		users: map[int64]database.User{
+			7: {ID: 7, Name: "Staff Member", Role: "STAFF"},
+		},
+		tokens: map[string]database.ApiToken{
+			validHash: {
+				ID:        1,
+				UserID:    7,
+				Scopes:    []string{"reservations:read", "reservations:create"},
+				ExpiresAt: time.Now().Add(time.Hour),
+			},
+			expiredHash: {
+				ID:        2,
+				UserID:    7,
+				Scopes:    []string{"reservations:read"},
+				ExpiresAt: time.Now().Add(-time.Hour),
+			},
+		},
+	}
+	service := NewService("test-secret-key", store)
+
+	t.Run("valid token is limited to its scopes", func(t *testing.T) {
+		user, err := service.Authenticate(context.Background(), validToken)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if user.ID != 7 || user.Role != "STAFF" || user.TokenID != 1 {
+			t.Errorf("unexpected user %+v", user)
+		}
+		if !user.Has(rbac.ReservationsCreate) {
+			t.Error("expected token to create reservations")
+		}
+		if user.Has(rbac.ReservationsModerate) || user.Has(rbac.TokensManage) {
+			t.Error("expected token not to carry permissions outside its scopes")
+		}
+		if len(store.touched) != 1 || store.touched[0] != 1 {
+			t.Errorf("expected last use to be recorded, got %v", store.touched)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		_, err := service.Authenticate(context.Background(), expiredToken)
+		if !errors.Is(err, ErrExpiredToken) {
+			t.Errorf("expected ErrExpiredToken, got %v", err)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err := service.Authenticate(context.Background(), PersonalTokenPrefix+"unknown")
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("jwt still accepted", func(t *testing.T) {
+		jwtToken, err := service.IssueAccessToken(database.User{ID: 7, Name: "Staff Member", Role: "STAFF"})
+		if err != nil {
+			t.Fatalf("failed to issue token: %v", err)
+		}
+		user, err := service.Authenticate(context.Background(), jwtToken)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if !user.Has(rbac.TokensManage) || user.TokenID != 0 {
+			t.Errorf("expected full grants for a jwt, got %+v", user)
+		}
+	})
+}
+
+func TestAuthenticate_DeviceToken(t *testing.T) {
+	token, hash, _ := MakeDeviceToken()
+
+	store := &fakeStore{
+		devices: map[string]database.DisplayDevice{
+			hash: {ID: 5, RoomID: 2, Name: "Big Room tablet"},
+		},
+	}
+	service := NewService("test-secret-key", store)
+
+	user, err := service.Authenticate(context.Background(), token)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if user.ID != 0 || user.DeviceID != 5 {
+		t.Errorf("expected device principal without user ID, got %+v", user)
+	}
+	if !user.Can(rbac.RoomsDisplay, 2) {
+		t.Error("expected device to read its own room")
+	}
+	if user.Can(rbac.RoomsDisplay, 1) {
+		t.Error("expected device not to read other rooms")
+	}
+	if user.Has(rbac.ReservationsRead) || user.Has(rbac.ReservationsCreate) {
+		t.Error("expected device to be limited to the room display")
+	}
+
+	if _, err := service.Authenticate(context.Background(), DeviceTokenPrefix+"unknown"); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestResolveUser_Status(t *testing.T) {
+	store := &fakeStore{
+		users: map[int64]database.User{
+			1: {ID: 1, Name: "Active", Role: "STAFF"},
+			2: {ID: 2, Name: "Suspended", Role: "STUDENT", SuspendedAt: sql.NullTime{Time: time.Now(), Valid: true}},
+			3: {ID: 3, Name: "Revoked", Role: "STUDENT"},
+		},
+	}
+	service := NewService("test-secret-key", store)
+
+	claimsFor := func(id int64, role string, issuedAt time.Time) *CustomClaims {
+		return &CustomClaims{
+			Role: role,
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:  strconv.FormatInt(id, 10),
+				IssuedAt: jwt.NewNumericDate(issuedAt),
+			},
+		}
+	}
+
+	t.Run("role is read from the database", func(t *testing.T) {
+		user, err := service.ResolveUser(context.Background(), claimsFor(1, "STUDENT", time.Now()))
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if user.Role != "STAFF" || !user.Has(rbac.ReservationsModerate) {
+			t.Errorf("expected stored role to win over token claims, got %+v", user)
+		}
+	})
+
+	t.Run("suspended user", func(t *testing.T) {
+		_, err := service.ResolveUser(context.Background(), claimsFor(2, "STUDENT", time.Now()))
+		if !errors.Is(err, ErrUserSuspended) {
+			t.Errorf("expected ErrUserSuspended, got %v", err)
+		}
+	})
+
+	t.Run("token issued before revocation", func(t *testing.T) {
+		issuedAt := time.Now().Add(-time.Hour)
+		if err := service.RevokeTokens(context.Background(), 3); err != nil {
+			t.Fatalf("failed to revoke tokens: %v", err)
+		}
+		_, err := service.ResolveUser(context.Background(), claimsFor(3, "STUDENT", issuedAt))
+		if !errors.Is(err, ErrRevokedToken) {
+			t.Errorf("expected ErrRevokedToken, got %v", err)
+		}
+
+		if _, err := service.ResolveUser(context.Background(), claimsFor(3, "STUDENT", time.Now().Add(time.Second))); err != nil {
+			t.Errorf("expected token issued after revocation to work, got %v", err)
+		}
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		_, err := service.ResolveUser(context.Background(), claimsFor(99, "STUDENT", time.Now()))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+	})
+}
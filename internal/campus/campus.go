@@ -0,0 +1,231 @@
+// Package campus provides the campuses one BookMe deployment serves. Each
+// campus owns its rooms, users and reservations and has its own timezone,
+// opening hours, login providers and calendar settings.
+package campus
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/schedule"
+)
+
+// Login providers a campus can enable.
+const (
+	Provider42       = "42"
+	ProviderKeycloak = "keycloak"
+)
+
+// Campus is a loaded campus with its timezone and opening hours resolved.
+type Campus struct {
+	ID            int64
+	Slug          string
+	Name          string
+	IntraCampusID int // 0 when 42 logins are not matched to the campus
+	Location      *time.Location
+	Hours         *schedule.Calendar
+	Providers     []string
+	CalendarID    string // Google calendar, empty uses the default calendar
+	CORSOrigins   []string
+}
+
+// AllowsProvider reports whether users of the campus may log in through the provider.
+func (c *Campus) AllowsProvider(provider string) bool {
+	return slices.Contains(c.Providers, provider)
+}
+
+// Store is the persistence the registry loads campuses from.
+type Store interface {
+	ListCampuses(ctx context.Context) ([]database.Campus, error)
+	ListOpeningHoursExceptions(ctx context.Context) ([]database.OpeningHoursException, error)
+}
+
+// Registry holds the loaded campuses. It is safe for concurrent use, the
+// campuses are swapped in by Load or Replace.
+type Registry struct {
+	store       Store
+	defaultSlug string
+
+	mu      sync.RWMutex
+	ordered []*Campus
+	byID    map[int64]*Campus
+	byIntra map[int]*Campus
+	def     *Campus
+}
+
+// NewRegistry creates an empty registry. Users without a campus of their
+// own, such as Keycloak staff, are placed on the campus with defaultSlug.
+func NewRegistry(store Store, defaultSlug string) *Registry {
+	return &Registry{
+		store:       store,
+		defaultSlug: defaultSlug,
+		byID:        make(map[int64]*Campus),
+		byIntra:     make(map[int]*Campus),
+	}
+}
+
+// Load reads all campuses and their opening hours exceptions from the store.
+func (r *Registry) Load(ctx context.Context) error {
+	rows, err := r.store.ListCampuses(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list campuses: %w", err)
+	}
+
+	exceptionRows, err := r.store.ListOpeningHoursExceptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list opening hours: %w", err)
+	}
+
+	exceptions := make(map[int64][]schedule.Exception)
+	for _, row := range exceptionRows {
+		exceptions[row.CampusID] = append(exceptions[row.CampusID], ToException(row))
+	}
+
+	campuses := make([]*Campus, 0, len(rows))
+	for _, row := range rows {
+		c, err := FromRow(row)
+		if err != nil {
+			return err
+		}
+		c.Hours.Replace(exceptions[c.ID])
+		campuses = append(campuses, c)
+	}
+
+	return r.Replace(campuses)
+}
+
+// Replace swaps the set of campuses. One of them must have the default slug.
+func (r *Registry) Replace(campuses []*Campus) error {
+	byID := make(map[int64]*Campus, len(campuses))
+	byIntra := make(map[int]*Campus, len(campuses))
+	var def *Campus
+	for _, c := range campuses {
+		byID[c.ID] = c
+		if c.IntraCampusID != 0 {
+			byIntra[c.IntraCampusID] = c
+		}
+		if c.Slug == r.defaultSlug {
+			def = c
+		}
+	}
+	if def == nil {
+		return fmt.Errorf("default campus %q not found", r.defaultSlug)
+	}
+
+	r.mu.Lock()
+	r.ordered = campuses
+	r.byID = byID
+	r.byIntra = byIntra
+	r.def = def
+	r.mu.Unlock()
+	return nil
+}
+
+// Get returns the campus with the BookMe ID.
+func (r *Registry) Get(id int64) (*Campus, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.byID[id]
+	return c, ok
+}
+
+// ByIntraID returns the campus matched to the 42 intra campus ID.
+func (r *Registry) ByIntraID(intraID int) (*Campus, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.byIntra[intraID]
+	return c, ok
+}
+
+// BySlug returns the campus with the slug.
+func (r *Registry) BySlug(slug string) (*Campus, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, c := range r.ordered {
+		if c.Slug == slug {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Default returns the default campus.
+func (r *Registry) Default() *Campus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.def
+}
+
+// Resolve returns the campus with the ID, or the default campus when it is
+// unknown. Used where a campus is always needed, such as rendering times.
+func (r *Registry) Resolve(id int64) *Campus {
+	if c, ok := r.Get(id); ok {
+		return c
+	}
+	return r.Default()
+}
+
+// All returns the campuses ordered by ID.
+func (r *Registry) All() []*Campus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Clone(r.ordered)
+}
+
+// Origins returns the browser origins allowed by any campus.
+func (r *Registry) Origins() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var origins []string
+	for _, c := range r.ordered {
+		for _, o := range c.CORSOrigins {
+			if !slices.Contains(origins, o) {
+				origins = append(origins, o)
+			}
+		}
+	}
+	return origins
+}
+
+// FromRow builds a campus from its database row. Opening hours start out
+// with the regular hours only.
+func FromRow(row database.Campus) (*Campus, error) {
+	loc, err := time.LoadLocation(row.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("campus %s: invalid timezone %q: %w", row.Slug, row.Timezone, err)
+	}
+
+	return &Campus{
+		ID:            row.ID,
+		Slug:          row.Slug,
+		Name:          row.Name,
+		IntraCampusID: int(row.IntraCampusID.Int32),
+		Location:      loc,
+		Hours: schedule.NewCalendar(loc, schedule.Hours{
+			Open:  int(row.OpenMinute),
+			Close: int(row.CloseMinute),
+		}),
+		Providers:   row.LoginProviders,
+		CalendarID:  row.CalendarID,
+		CORSOrigins: row.CorsOrigins,
+	}, nil
+}
+
+// ToException converts a stored opening hours exception.
+func ToException(row database.OpeningHoursException) schedule.Exception {
+	return schedule.Exception{
+		Date: row.Date.Format(schedule.DateLayout),
+		Hours: schedule.Hours{
+			Open:   int(row.OpenMinute),
+			Close:  int(row.CloseMinute),
+			Closed: row.Closed,
+		},
+		Name: row.Name,
+		UID:  row.SourceUid,
+	}
+}
@@ -0,0 +1,119 @@
+package campus
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/database"
+)
+
+type fakeStore struct {
+	campuses   []database.Campus
+	exceptions []database.OpeningHoursException
+}
+
+func (f *fakeStore) ListCampuses(_ context.Context) ([]database.Campus, error) {
+	return f.campuses, nil
+}
+
+func (f *fakeStore) ListOpeningHoursExceptions(_ context.Context) ([]database.OpeningHoursException, error) {
+	return f.exceptions, nil
+}
+
+func testStore() *fakeStore {
+	return &fakeStore{
+		campuses: []database.Campus{
+			{
+				ID:             1,
+				Slug:           "hive-helsinki",
+				Name:           "Hive Helsinki",
+				IntraCampusID:  sql.NullInt32{Int32: 13, Valid: true},
+				Timezone:       "Europe/Helsinki",
+				OpenMinute:     6 * 60,
+				CloseMinute:    20 * 60,
+				LoginProviders: []string{Provider42, ProviderKeycloak},
+				CorsOrigins:    []string{"https://hive.example.com"},
+			},
+			{
+				ID:             2,
+				Slug:           "42-lisboa",
+				Name:           "42 Lisboa",
+				IntraCampusID:  sql.NullInt32{Int32: 38, Valid: true},
+				Timezone:       "Europe/Lisbon",
+				OpenMinute:     8 * 60,
+				CloseMinute:    22 * 60,
+				LoginProviders: []string{Provider42},
+				CalendarID:     "lisboa@group.calendar.google.com",
+				CorsOrigins:    []string{"https://lisboa.example.com", "https://hive.example.com"},
+			},
+		},
+		exceptions: []database.OpeningHoursException{
+			{CampusID: 2, Date: time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC), Closed: true, Name: "Natal"},
+		},
+	}
+}
+
+func TestRegistry_Load(t *testing.T) {
+	r := NewRegistry(testStore(), "hive-helsinki")
+	if err := r.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if got := r.Default().Slug; got != "hive-helsinki" {
+		t.Errorf("Default() = %q, want hive-helsinki", got)
+	}
+
+	lisboa, ok := r.ByIntraID(38)
+	if !ok || lisboa.ID != 2 {
+		t.Fatalf("ByIntraID(38) = %v, %v", lisboa, ok)
+	}
+	if _, ok := r.ByIntraID(1); ok {
+		t.Error("ByIntraID(1) found an unregistered campus")
+	}
+	if lisboa.AllowsProvider(ProviderKeycloak) {
+		t.Error("lisboa allows keycloak logins")
+	}
+
+	// Hours are in the campus timezone and carry the campus exceptions only
+	christmas := time.Date(2025, 12, 25, 12, 0, 0, 0, lisboa.Location)
+	if lisboa.Hours.IsOpenAt(christmas) {
+		t.Error("lisboa is open on a closed day")
+	}
+	hive, _ := r.Get(1)
+	if !hive.Hours.IsOpenAt(christmas) {
+		t.Error("hive is closed by another campus exception")
+	}
+	if lisboa.Hours.IsOpenAt(time.Date(2025, 12, 26, 7, 30, 0, 0, lisboa.Location)) {
+		t.Error("lisboa is open before its opening time")
+	}
+
+	if got := r.Resolve(99).ID; got != 1 {
+		t.Errorf("Resolve(99) = campus %d, want the default", got)
+	}
+
+	want := []string{"https://hive.example.com", "https://lisboa.example.com"}
+	if got := r.Origins(); !slices.Equal(got, want) {
+		t.Errorf("Origins() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistry_LoadErrors(t *testing.T) {
+	t.Run("missing default campus", func(t *testing.T) {
+		r := NewRegistry(testStore(), "42-paris")
+		if err := r.Load(context.Background()); err == nil {
+			t.Error("Load() succeeded without the default campus")
+		}
+	})
+
+	t.Run("invalid timezone", func(t *testing.T) {
+		store := testStore()
+		store.campuses[1].Timezone = "Europe/Atlantis"
+		r := NewRegistry(store, "hive-helsinki")
+		if err := r.Load(context.Background()); err == nil {
+			t.Error("Load() succeeded with an invalid timezone")
+		}
+	})
+}
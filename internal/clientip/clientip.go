@@ -0,0 +1,135 @@
+// Package clientip resolves the IP address of the client behind trusted
+// reverse proxies.
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Resolver finds the client IP of requests. Forwarding headers are only
+// read when the request comes from a trusted proxy, and their entries are
+// walked from the right, skipping trusted proxies, so the first untrusted
+// address is the client. Entries left of it may be forged and are ignored.
+//
+// A nil Resolver trusts no proxy.
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// NewResolver creates a resolver trusting the given CIDRs, such as
+// 10.0.0.0/8. Bare IPs trust that single address.
+func NewResolver(proxies []string) (*Resolver, error) {
+	r := &Resolver{}
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if !strings.Contains(p, "/") {
+			addr, err := netip.ParseAddr(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+			}
+			r.trusted = append(r.trusted, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", p, err)
+		}
+		r.trusted = append(r.trusted, prefix.Masked())
+	}
+	return r, nil
+}
+
+// ClientIP returns the IP address of the client that sent r. The RFC 7239
+// Forwarded header is preferred over X-Forwarded-For, X-Real-IP is used when
+// neither is set.
+func (res *Resolver) ClientIP(r *http.Request) string {
+	peer := hostOf(r.RemoteAddr)
+	if !res.isTrusted(peer) {
+		return peer
+	}
+
+	var hops []string
+	switch {
+	case r.Header.Get("Forwarded") != "":
+		hops = forwardedFor(r.Header.Values("Forwarded"))
+	case r.Header.Get("X-Forwarded-For") != "":
+		hops = forwardedList(r.Header.Values("X-Forwarded-For"))
+	case r.Header.Get("X-Real-IP") != "":
+		hops = []string{strings.TrimSpace(r.Header.Get("X-Real-IP"))}
+	}
+
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := hostOf(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// Unknown or obfuscated hop, the last known address is
+			// the closest one that can be told
+			break
+		}
+		client = hop
+		if !res.isTrusted(hop) {
+			break
+		}
+	}
+	return client
+}
+
+// isTrusted reports whether ip belongs to a trusted proxy.
+func (res *Resolver) isTrusted(ip string) bool {
+	if res == nil || len(res.trusted) == 0 {
+		return false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range res.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedList splits X-Forwarded-For headers into their entries, in order.
+func forwardedList(values []string) []string {
+	var hops []string
+	for _, v := range values {
+		for _, hop := range strings.Split(v, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	return hops
+}
+
+// forwardedFor returns the for= parameter of every element of RFC 7239
+// Forwarded headers, in order. Elements without one are kept as empty
+// entries so they stop the walk.
+func forwardedFor(values []string) []string {
+	var hops []string
+	for _, element := range forwardedList(values) {
+		hop := ""
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				hop = strings.Trim(value, `"`)
+			}
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// hostOf strips the port and IPv6 brackets from an address.
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]")
+}
@@ -0,0 +1,162 @@
+package clientip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolver_ClientIP(t *testing.T) {
+	tests := []struct {
+		name          string
+		trusted       []string
+		remoteAddr    string
+		xForwardedFor []string
+		forwarded     string
+		xRealIP       string
+		expectedIP    string
+	}{
+		{
+			name:       "from RemoteAddr without proxies",
+			remoteAddr: "192.168.1.1:1234",
+			expectedIP: "192.168.1.1",
+		},
+		{
+			name:          "ignores headers without trusted proxies",
+			remoteAddr:    "192.168.1.1:1234",
+			xForwardedFor: []string{"10.0.0.2"},
+			forwarded:     "for=10.0.0.3",
+			xRealIP:       "10.0.0.4",
+			expectedIP:    "192.168.1.1",
+		},
+		{
+			name:          "ignores headers from untrusted peer",
+			trusted:       []string{"10.0.0.0/8"},
+			remoteAddr:    "192.168.1.1:1234",
+			xForwardedFor: []string{"1.2.3.4"},
+			expectedIP:    "192.168.1.1",
+		},
+		{
+			name:       "from RemoteAddr when trusted proxy sends no headers",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.1:1234",
+			expectedIP: "10.0.0.1",
+		},
+		{
+			name:          "from X-Forwarded-For behind trusted proxy",
+			trusted:       []string{"10.0.0.0/8"},
+			remoteAddr:    "10.0.0.1:1234",
+			xForwardedFor: []string{"203.0.113.7"},
+			expectedIP:    "203.0.113.7",
+		},
+		{
+			name:          "spoofed X-Forwarded-For entries are skipped",
+			trusted:       []string{"10.0.0.0/8"},
+			remoteAddr:    "10.0.0.1:1234",
+			xForwardedFor: []string{"1.1.1.1, 203.0.113.7, 10.0.0.5"},
+			expectedIP:    "203.0.113.7",
+		},
+		{
+			name:          "multiple X-Forwarded-For headers are joined",
+			trusted:       []string{"10.0.0.0/8"},
+			remoteAddr:    "10.0.0.1:1234",
+			xForwardedFor: []string{"1.1.1.1", "203.0.113.7"},
+			expectedIP:    "203.0.113.7",
+		},
+		{
+			name:          "all trusted takes leftmost",
+			trusted:       []string{"10.0.0.0/8"},
+			remoteAddr:    "10.0.0.1:1234",
+			xForwardedFor: []string{"10.0.0.9, 10.0.0.5"},
+			expectedIP:    "10.0.0.9",
+		},
+		{
+			name:          "invalid entry stops at last known hop",
+			trusted:       []string{"10.0.0.0/8"},
+			remoteAddr:    "10.0.0.1:1234",
+			xForwardedFor: []string{"203.0.113.7, garbage, 10.0.0.5"},
+			expectedIP:    "10.0.0.5",
+		},
+		{
+			name:       "bare trusted IP",
+			trusted:    []string{"10.0.0.1"},
+			remoteAddr: "10.0.0.1:1234",
+			xRealIP:    "203.0.113.7",
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:       "Forwarded header",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.1:1234",
+			forwarded:  `for=1.1.1.1, for="[2001:db8:cafe::17]:4711";proto=https, For=10.0.0.5;by=10.0.0.1`,
+			expectedIP: "2001:db8:cafe::17",
+		},
+		{
+			name:          "Forwarded takes precedence over X-Forwarded-For",
+			trusted:       []string{"10.0.0.0/8"},
+			remoteAddr:    "10.0.0.1:1234",
+			forwarded:     "for=203.0.113.7",
+			xForwardedFor: []string{"203.0.113.8"},
+			expectedIP:    "203.0.113.7",
+		},
+		{
+			name:       "obfuscated Forwarded hop",
+			trusted:    []string{"10.0.0.0/8"},
+			remoteAddr: "10.0.0.1:1234",
+			forwarded:  "for=_hidden",
+			expectedIP: "10.0.0.1",
+		},
+		{
+			name:          "IPv6 trusted proxy",
+			trusted:       []string{"2001:db8::/32"},
+			remoteAddr:    "[2001:db8::1]:1234",
+			xForwardedFor: []string{"203.0.113.7"},
+			expectedIP:    "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver, err := NewResolver(tt.trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xForwardedFor {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if tt.forwarded != "" {
+				req.Header.Set("Forwarded", tt.forwarded)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set("X-Real-IP", tt.xRealIP)
+			}
+
+			if ip := resolver.ClientIP(req); ip != tt.expectedIP {
+				t.Errorf("expected IP %s, got %s", tt.expectedIP, ip)
+			}
+		})
+	}
+}
+
+func TestNewResolver_Invalid(t *testing.T) {
+	for _, proxy := range []string{"10.0.0.0/33", "not-an-ip", ""} {
+		if _, err := NewResolver([]string{proxy}); err == nil {
+			t.Errorf("expected error for %q", proxy)
+		}
+	}
+}
+
+func TestResolver_Nil(t *testing.T) {
+	var resolver *Resolver
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.RemoteAddr = "192.168.1.1:1234"
+	req.Header.Set("X-Forwarded-For", "10.0.0.2")
+
+	if ip := resolver.ClientIP(req); ip != "192.168.1.1" {
+		t.Errorf("expected RemoteAddr, got %s", ip)
+	}
+}
@@ -2,198 +2,469 @@
 package config
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/secrets"
 	"github.com/joho/godotenv"
+	"go.yaml.in/yaml/v3"
 )
 
 // Config holds all configuration needed to run the API
 type Config struct {
-	Server ServerConfig
-	Logger LoggerConfig
-	App    AppConfig
-	Google GoogleConfig
-	Email  EmailConfig
+	Server    ServerConfig    `yaml:"server"`
+	Logger    LoggerConfig    `yaml:"logger"`
+	App       AppConfig       `yaml:"app"`
+	Google    GoogleConfig    `yaml:"google"`
+	Email     EmailConfig     `yaml:"email"`
+	Access    AccessConfig    `yaml:"access"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Lockout   LockoutConfig   `yaml:"lockout"`
+	CORS      CORSConfig      `yaml:"cors"`
+	Secrets   SecretsConfig   `yaml:"secrets"`
+
+	// refs are the references secrets were read from, by environment
+	// variable, to read them again when they are rotated
+	refs map[string]string
+
+	// scope selects the settings that are resolved and validated, nil for all
+	scope func(setting) bool
 }
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
-	Port         string
-	ReadTimeout  time.Duration
-	WriteTimeout time.Duration
-	IdleTimeout  time.Duration
+	Port           string        `yaml:"port"`
+	TrustedProxies []string      `yaml:"trusted_proxies"` // CIDRs of reverse proxies whose forwarding headers are read
+	ReadTimeout    time.Duration `yaml:"read_timeout"`
+	WriteTimeout   time.Duration `yaml:"write_timeout"`
+	IdleTimeout    time.Duration `yaml:"idle_timeout"`
+	// DrainDelay is how long the server keeps serving, not ready, once asked
+	// to shut down, so load balancers stop sending it traffic
+	DrainDelay          time.Duration `yaml:"drain_delay"`
+	HealthCheckInterval time.Duration `yaml:"health_check_interval"`
+	HealthCheckTimeout  time.Duration `yaml:"health_check_timeout"`
+	// IntegrationRetryInterval is how often calendar and email are started
+	// again after failing to, and work they failed to take is retried
+	IntegrationRetryInterval time.Duration `yaml:"integration_retry_interval"`
 }
 
 // LoggerConfig holds logging configuration
 type LoggerConfig struct {
-	Level string // debug, info, warn, error
+	Level string `yaml:"level"` // debug, info, warn, error
 }
 
 // AppConfig holds application-specific configuration
 type AppConfig struct {
-	Env              string
-	DATABASE_URL     string
-	SessionSecret    string
-	ClientID         string
-	ClientSecret     string
-	RedirectURI      string
-	RedirectTokenURI string
-	User42InfoURL    string
-	JWTSecret        string
-	OAuthAuthURI     string
-	OAuthTokenURI    string
+	Env              string `yaml:"env"`
+	DATABASE_URL     string `yaml:"database_url"`
+	SessionSecret    string `yaml:"session_secret"`
+	ClientID         string `yaml:"client_id"`
+	ClientSecret     string `yaml:"client_secret"`
+	RedirectURI      string `yaml:"redirect_uri"`
+	RedirectTokenURI string `yaml:"redirect_token_uri"`
+	User42InfoURL    string `yaml:"user_info_url"`
+	JWTSecret        string `yaml:"jwt_secret"`
+	OAuthAuthURI     string `yaml:"oauth_auth_uri"`
+	OAuthTokenURI    string `yaml:"oauth_token_uri"`
 	// Keycloak
-	KeycloakClientID     string
-	KeycloakClientSecret string
-	KeycloakRedirectURI  string
-	KeycloakAuthURI      string
-	KeycloakTokenURI     string
-	KeycloakUserInfoURL  string
+	KeycloakClientID     string   `yaml:"keycloak_client_id"`
+	KeycloakClientSecret string   `yaml:"keycloak_client_secret"`
+	KeycloakRedirectURI  string   `yaml:"keycloak_redirect_uri"`
+	KeycloakIssuerURL    string   `yaml:"keycloak_issuer_url"`
+	KeycloakStaffRoles   []string `yaml:"keycloak_staff_roles"` // realm or client roles mapped to STAFF
 }
 
 // GoogleConfig holds Google Calendar configuration.
 type GoogleConfig struct {
-	CredentialsBase64 string
-	CalendarScope     string
-	CalendarID        string
+	Enabled           bool   `yaml:"enabled"`
+	CredentialsBase64 string `yaml:"credentials_base64"`
+	CalendarScope     string `yaml:"calendar_scope"`
+	CalendarID        string `yaml:"calendar_id"`
 }
 
 // EmailConfig holds email service configuration.
 type EmailConfig struct {
-	SMTPHost     string
-	SMTPPort     int
-	SMTPUsername string
-	SMTPPassword string
-	FromEmail    string
-	FromName     string
-	UseTLS       bool
-}
-
-// Load loads configuration from environment variables
-func Load() (*Config, error) {
-	// Load .env file if it exists
+	Enabled      bool   `yaml:"enabled"`
+	SMTPHost     string `yaml:"smtp_host"`
+	SMTPPort     int    `yaml:"smtp_port"`
+	SMTPUsername string `yaml:"smtp_username"`
+	SMTPPassword string `yaml:"smtp_password"`
+	FromEmail    string `yaml:"from_email"`
+	FromName     string `yaml:"from_name"`
+	UseTLS       bool   `yaml:"use_tls"`
+}
+
+// AccessConfig holds the 42 login access policy.
+// Empty campus, cursus and pool lists disable that filter.
+type AccessConfig struct {
+	DefaultCampus string   `yaml:"default_campus"` // slug of the campus for users without one, such as Keycloak staff
+	CampusIDs     []int    `yaml:"campus_ids"`
+	CursusIDs     []int    `yaml:"cursus_ids"`
+	PoolYears     []string `yaml:"pool_years"`
+	PoolMonths    []string `yaml:"pool_months"`
+}
+
+// TracingConfig holds the OpenTelemetry trace export settings.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter"` // none or otlp
+	Endpoint    string  `yaml:"endpoint"` // host:port of the OTLP collector
+	Protocol    string  `yaml:"protocol"` // http/protobuf or grpc
+	Insecure    bool    `yaml:"insecure"` // plain text connection to the collector
+	SampleRatio float64 `yaml:"sample_ratio"`
+	ServiceName string  `yaml:"service_name"`
+}
+
+// RateLimitConfig holds the rate limits and where their state is kept.
+type RateLimitConfig struct {
+	Store    string          `yaml:"store"`     // memory or redis
+	RedisURL string          `yaml:"redis_url"` // redis://[:password@]host:port/db, used by the redis store
+	OAuth    RateLimit       `yaml:"oauth"`
+	APIIP    RateLimit       `yaml:"api_ip"` // per client IP, before tokens are checked
+	API      RateLimit       `yaml:"api"`
+	Rules    []RateLimitRule `yaml:"rules"` // overrides of the API limit
+}
+
+// CORSConfig holds the CORS policy. Origins of campuses are allowed on top
+// of Origins.
+type CORSConfig struct {
+	Origins        []string      `yaml:"origins"` // exact origins or https://*.example.com patterns
+	Methods        []string      `yaml:"methods"`
+	Headers        []string      `yaml:"headers"`         // request headers clients may send
+	ExposedHeaders []string      `yaml:"exposed_headers"` // response headers scripts may read
+	MaxAge         time.Duration `yaml:"max_age"`
+}
+
+// LockoutConfig holds when failed OAuth logins lock out an IP or account.
+type LockoutConfig struct {
+	Threshold int           `yaml:"threshold"` // failures within Window that trigger a lockout
+	Window    time.Duration `yaml:"window"`    // failures older than this are forgotten
+	Base      time.Duration `yaml:"base"`      // first lockout, doubled for each one in a row
+	Max       time.Duration `yaml:"max"`       // longest lockout
+}
+
+// SecretsConfig holds where secret references are read from. Secrets may
+// be written as file:/path or vault:mount/path#key instead of their value.
+type SecretsConfig struct {
+	VaultAddr       string        `yaml:"vault_addr"` // base URL of a Vault compatible API
+	VaultToken      string        `yaml:"vault_token"`
+	VaultNamespace  string        `yaml:"vault_namespace"`
+	RefreshInterval time.Duration `yaml:"refresh_interval"` // how often references are read again, 0 disables
+}
+
+// RateLimit allows Requests per Period, all of which may be used at once.
+// It is written as requests/period in the config file and environment.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// RateLimitRule overrides the API limit for a route pattern such as
+// "POST /api/v1/reservations", a role such as STAFF, or both. It is written
+// as [route][@role]=requests/period in the config file and environment.
+type RateLimitRule struct {
+	Route string
+	Role  string
+	Limit RateLimit
+}
+
+// ValidationError lists every missing or invalid setting found while
+// loading the configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration (%d problems): %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// Load loads the configuration. Defaults are overridden by the YAML file at
+// path, or at CONFIG_FILE when path is empty, and both are overridden by
+// environment variables, which may come from a .env file. Every missing or
+// invalid setting is reported at once in a *ValidationError.
+func Load(path string) (*Config, error) {
+	return load(filePath(path), nil)
+}
+
+// LoadDatabase loads the configuration like Load for commands that only use
+// the database, like migrate. Only the database, logger and secret manager
+// settings are resolved and validated, the others may be missing.
+func LoadDatabase(path string) (*Config, error) {
+	return load(filePath(path), databaseOnly)
+}
+
+// filePath loads the .env file if it exists and returns the path of the
+// config file, CONFIG_FILE when path is empty.
+func filePath(path string) string {
 	if err := godotenv.Load(); err != nil {
 		slog.Warn("no .env file found, relying on system environment variables",
 			"error", err,
 		)
 	}
 
-	cfg := &Config{
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	return path
+}
+
+// databaseOnly is the scope of LoadDatabase.
+func databaseOnly(s setting) bool {
+	return s.env == "ENV" || s.env == "DATABASE_URL" ||
+		strings.HasPrefix(s.path, "logger.") || strings.HasPrefix(s.path, "secrets.")
+}
+
+// load builds the configuration from the defaults, the file at path if set
+// and the environment, resolving and validating the settings in scope.
+func load(path string, scope func(setting) bool) (*Config, error) {
+	cfg := defaults()
+	cfg.scope = scope
+
+	var problems []string
+	if path != "" {
+		fileProblems, err := cfg.readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, fileProblems...)
+	}
+
+	problems = append(problems, cfg.applyEnv()...)
+	problems = append(problems, cfg.resolveSecrets(context.Background())...)
+	problems = append(problems, cfg.validate()...)
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return cfg, nil
+}
+
+// defaults returns the configuration used for settings set neither in the
+// config file nor in the environment.
+func defaults() *Config {
+	return &Config{
 		Server: ServerConfig{
-			Port:         getEnv("PORT", "8080"),
-			ReadTimeout:  getEnvAsDuration("SERVER_READ_TIMEOUT", "15s"),
-			WriteTimeout: getEnvAsDuration("SERVER_WRITE_TIMEOUT", "15s"),
-			IdleTimeout:  getEnvAsDuration("SERVER_IDLE_TIMEOUT", "60s"),
+			Port:                     "8080",
+			ReadTimeout:              15 * time.Second,
+			WriteTimeout:             15 * time.Second,
+			IdleTimeout:              60 * time.Second,
+			DrainDelay:               5 * time.Second,
+			HealthCheckInterval:      15 * time.Second,
+			HealthCheckTimeout:       5 * time.Second,
+			IntegrationRetryInterval: time.Minute,
 		},
 		App: AppConfig{
-			Env:              getEnv("ENV", "dev"),
-			DATABASE_URL:     mustGetEnv("DATABASE_URL"),
-			SessionSecret:    mustGetEnv("SESSION_SECRET"),
-			ClientID:         mustGetEnv("CLIENT_ID"),
-			ClientSecret:     mustGetEnv("SECRET"),
-			RedirectURI:      mustGetEnv("REDIRECT_URI"),
-			RedirectTokenURI: mustGetEnv("REDIRECT_TOKEN_URI"),
-			User42InfoURL:    mustGetEnv("USER_INFO_URL"),
-			JWTSecret:        mustGetEnv("JWT_SECRET"),
-			OAuthAuthURI:     mustGetEnv("OAUTH_AUTH_URI"),
-			OAuthTokenURI:    mustGetEnv("OAUTH_TOKEN_URI"),
-			// Keycloak
-			KeycloakClientID:     mustGetEnv("KEYCLOAK_CLIENT_ID"),
-			KeycloakClientSecret: mustGetEnv("KEYCLOAK_CLIENT_SECRET"),
-			KeycloakRedirectURI:  mustGetEnv("KEYCLOAK_REDIRECT_URI"),
-			KeycloakAuthURI:      mustGetEnv("KEYCLOAK_AUTH_URI"),
-			KeycloakTokenURI:     mustGetEnv("KEYCLOAK_TOKEN_URI"),
-			KeycloakUserInfoURL:  mustGetEnv("KEYCLOAK_USERINFO_URL"),
+			Env:                "dev",
+			KeycloakStaffRoles: []string{"staff"},
 		},
 		Google: GoogleConfig{
-			CredentialsBase64: mustGetEnv("GOOGLE_CREDENTIALS_BASE64"),
-			CalendarScope:     getEnv("GOOGLE_CALENDAR_SCOPE", "https://www.googleapis.com/auth/calendar"),
-			CalendarID:        mustGetEnv("GOOGLE_CALENDAR_ID"),
+			Enabled:       true,
+			CalendarScope: "https://www.googleapis.com/auth/calendar",
 		},
 		Email: EmailConfig{
-			SMTPHost:     mustGetEnv("SMTP_HOST"),
-			SMTPPort:     getEnvAsInt("SMTP_PORT", 587),
-			SMTPUsername: mustGetEnv("SMTP_USERNAME"),
-			SMTPPassword: mustGetEnv("SMTP_PASSWORD"),
-			FromEmail:    mustGetEnv("FROM_EMAIL"),
-			FromName:     getEnv("FROM_NAME", "BookMe"),
-			UseTLS:       getEnv("SMTP_USE_TLS", "true") == "true",
+			Enabled:  true,
+			SMTPPort: 587,
+			FromName: "BookMe",
+			UseTLS:   true,
 		},
 		Logger: LoggerConfig{
-			Level: getEnv("LOG_LEVEL", "info"),
+			Level: "info",
+		},
+		Access: AccessConfig{
+			DefaultCampus: "hive-helsinki",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4318",
+			Protocol:    "http/protobuf",
+			SampleRatio: 1,
+			ServiceName: "book-me",
+		},
+		RateLimit: RateLimitConfig{
+			Store:    "memory",
+			RedisURL: "redis://localhost:6379/0",
+			OAuth:    RateLimit{Requests: 20, Period: 20 * time.Second},
+			APIIP:    RateLimit{Requests: 1000, Period: time.Minute},
+			API:      RateLimit{Requests: 100, Period: 100 * time.Second},
+		},
+		CORS: CORSConfig{
+			Origins: []string{
+				"http://localhost:5173",
+				"https://booking-calendar-chi.vercel.app",
+				"https://*.hive.fi",
+				"https://*.jgengo.dev",
+			},
+			Methods: []string{"GET", "POST", "PUT", "DELETE"},
+			Headers: []string{"Content-Type", "Authorization", "X-Request-ID"},
+			ExposedHeaders: []string{
+				"X-Total-Count", "X-Request-ID", "Retry-After",
+				"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+			},
+			MaxAge: 12 * time.Hour,
+		},
+		Lockout: LockoutConfig{
+			Threshold: 5,
+			Window:    15 * time.Minute,
+			Base:      time.Minute,
+			Max:       24 * time.Hour,
+		},
+		Secrets: SecretsConfig{
+			RefreshInterval: 5 * time.Minute,
 		},
 	}
+}
 
-	return cfg, nil
+// readFile overrides the configuration with the YAML file at path. Unknown
+// keys and values of the wrong type are returned as problems, a missing or
+// malformed file as an error.
+func (c *Config) readFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+
+	err = dec.Decode(c)
+	var typeErr *yaml.TypeError
+	switch {
+	case err == nil, errors.Is(err, io.EOF):
+		return nil, nil
+	case errors.As(err, &typeErr):
+		problems := make([]string, 0, len(typeErr.Errors))
+		for _, e := range typeErr.Errors {
+			problems = append(problems, path+": "+e)
+		}
+		return problems, nil
+	default:
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// applyEnv overrides the configuration with the environment variables that
+// are set and not empty, returning the ones that can't be parsed. Secrets
+// may instead be read from the file named by the variable with a _FILE
+// suffix, like JWT_SECRET_FILE.
+func (c *Config) applyEnv() []string {
+	var problems []string
+	for _, s := range c.settings() {
+		value := os.Getenv(s.env)
+		if path := os.Getenv(s.env + "_FILE"); s.secret && path != "" {
+			if value != "" {
+				problems = append(problems, fmt.Sprintf("%s: both %s and %s_FILE are set", s, s.env, s.env))
+				continue
+			}
+			value = secrets.FileScheme + ":" + path
+		}
+		if value == "" {
+			continue
+		}
+		if err := s.set(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", s, err))
+		}
 	}
-	return defaultValue
+	return problems
 }
 
-func mustGetEnv(key string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		slog.Error("required environment variable not set",
-			"key", key,
-		)
-		os.Exit(1)
+// String returns the limit as requests/period.
+func (l RateLimit) String() string {
+	return strconv.Itoa(l.Requests) + "/" + l.Period.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l RateLimit) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *RateLimit) UnmarshalText(text []byte) error {
+	limit, err := ParseRateLimit(string(text))
+	if err != nil {
+		return err
 	}
-	return value
+	*l = limit
+	return nil
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
+// String returns the rule as [route][@role]=requests/period.
+func (r RateLimitRule) String() string {
+	target := r.Route
+	if r.Role != "" {
+		target += "@" + r.Role
 	}
-	value, err := strconv.Atoi(valueStr)
+	return target + "=" + r.Limit.String()
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (r RateLimitRule) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (r *RateLimitRule) UnmarshalText(text []byte) error {
+	rule, err := ParseRateLimitRule(string(text))
 	if err != nil {
-		slog.Warn("invalid int environment variable, using default",
-			"key", key,
-			"value", valueStr,
-			"default", defaultValue,
-			"error", err,
-		)
-		return defaultValue
+		return err
 	}
-	return value
+	*r = rule
+	return nil
 }
 
-//nolint:unused // kept for future use
-func getEnvAsInt64(key string, defaultValue int64) int64 {
-	valueStr := os.Getenv(key)
-	if valueStr == "" {
-		return defaultValue
+// ParseRateLimit parses a limit written as requests/period. The period is a
+// duration, a bare unit counts as one: 10/m is 10/1m.
+func ParseRateLimit(s string) (RateLimit, error) {
+	requestsStr, periodStr, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("expected requests/period, got %q", s)
 	}
-	value, err := strconv.ParseInt(valueStr, 10, 64)
-	if err != nil {
-		slog.Warn("invalid int environment variable, using default",
-			"key", key,
-			"value", valueStr,
-			"default", defaultValue,
-			"error", err,
-		)
-		return defaultValue
+
+	requests, err := strconv.Atoi(requestsStr)
+	if err != nil || requests < 1 {
+		return RateLimit{}, fmt.Errorf("invalid request count %q", requestsStr)
 	}
-	return value
+
+	if periodStr != "" && (periodStr[0] < '0' || periodStr[0] > '9') {
+		periodStr = "1" + periodStr
+	}
+	period, err := time.ParseDuration(periodStr)
+	if err != nil || period <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid period %q", periodStr)
+	}
+
+	return RateLimit{Requests: requests, Period: period}, nil
 }
 
-func getEnvAsDuration(key, defaultValue string) time.Duration {
-	valueStr := getEnv(key, defaultValue)
-	duration, err := time.ParseDuration(valueStr)
+// ParseRateLimitRule parses a rule written as [route][@role]=requests/period.
+func ParseRateLimitRule(s string) (RateLimitRule, error) {
+	target, limitStr, ok := strings.Cut(s, "=")
+	if !ok {
+		return RateLimitRule{}, fmt.Errorf("expected [route][@role]=requests/period, got %q", s)
+	}
+
+	route, role, _ := strings.Cut(target, "@")
+	rule := RateLimitRule{
+		Route: strings.TrimSpace(route),
+		Role:  strings.ToUpper(strings.TrimSpace(role)),
+	}
+	if rule.Route == "" && rule.Role == "" {
+		return RateLimitRule{}, fmt.Errorf("rule %q names neither a route nor a role", s)
+	}
+
+	limit, err := ParseRateLimit(limitStr)
 	if err != nil {
-		// Fallback to parsing the default if provided value is invalid
-		duration, err = time.ParseDuration(defaultValue)
-		if err != nil {
-			return 0
-		}
+		return RateLimitRule{}, err
 	}
-	return duration
+	rule.Limit = limit
+	return rule, nil
 }
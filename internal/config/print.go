@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Redacted returns a copy of c with its secrets replaced. Secrets read from
+// files or secret managers show their reference, URLs keep everything but
+// their password.
+func (c *Config) Redacted() *Config {
+	cp := *c
+	for _, s := range cp.settings() {
+		v, ok := s.value.(*string)
+		if !ok || !s.secret || *v == "" {
+			continue
+		}
+		if ref, ok := c.refs[s.env]; ok {
+			*v = ref
+			continue
+		}
+		if u, err := url.Parse(*v); err == nil && u.Scheme != "" && u.Host != "" {
+			*v = u.Redacted()
+			continue
+		}
+		*v = "[REDACTED]"
+	}
+	return &cp
+}
+
+// Print writes c as a config file with its secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c.Redacted()); err != nil {
+		return fmt.Errorf("failed to print config: %w", err)
+	}
+	return enc.Close()
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/IbnBaqqi/book-me/internal/secrets"
+)
+
+// resolveSecrets replaces the secrets written as references with the
+// secrets they point at, remembering the references. The Vault token is
+// read first, from a file at most, to read the other secrets.
+func (c *Config) resolveSecrets(ctx context.Context) []string {
+	c.refs = make(map[string]string)
+
+	var problems []string
+	resolve := func(resolver *secrets.Resolver, s setting) {
+		value := s.value.(*string)
+		if !resolver.IsReference(*value) {
+			return
+		}
+		secret, err := resolver.Resolve(ctx, *value)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", s, err))
+			return
+		}
+		c.refs[s.env] = *value
+		*value = secret
+	}
+
+	settings := c.settings()
+	for _, s := range settings {
+		if s.env != "VAULT_TOKEN" {
+			continue
+		}
+		if strings.HasPrefix(c.Secrets.VaultToken, secrets.VaultScheme+":") {
+			problems = append(problems, s.String()+": can't be read from vault")
+			continue
+		}
+		resolve(secrets.NewResolver(), s)
+	}
+
+	resolver := secrets.NewResolver(
+		secrets.NewVault(c.Secrets.VaultAddr, c.Secrets.VaultToken, c.Secrets.VaultNamespace, nil),
+	)
+	for _, s := range settings {
+		if _, ok := s.value.(*string); ok && s.secret && s.env != "VAULT_TOKEN" && c.inScope(s) {
+			resolve(resolver, s)
+		}
+	}
+	return problems
+}
+
+// RefreshSecrets returns a copy of c with the secrets read from files or
+// secret managers read again, so rotated secrets are picked up. Secrets set
+// as values can't change without a restart.
+func (c *Config) RefreshSecrets(ctx context.Context) (*Config, error) {
+	next := *c
+	for _, s := range next.settings() {
+		if ref, ok := c.refs[s.env]; ok {
+			*s.value.(*string) = ref
+		}
+	}
+
+	problems := next.resolveSecrets(ctx)
+	problems = append(problems, next.validate()...)
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return &next, nil
+}
+
+// ChangedSecrets returns the environment variables of the secrets that
+// differ between two configurations.
+func ChangedSecrets(old, next *Config) []string {
+	nextSettings := make(map[string]setting)
+	for _, s := range next.settings() {
+		nextSettings[s.env] = s
+	}
+
+	var changed []string
+	for _, s := range old.settings() {
+		value, ok := s.value.(*string)
+		if !ok || !s.secret {
+			continue
+		}
+		if *value != *nextSettings[s.env].value.(*string) {
+			changed = append(changed, s.env)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting ties a configuration field to its key in the config file and its
+// environment variable.
+type setting struct {
+	path     string // key in the config file, like server.port
+	env      string
+	value    any // pointer to the field
+	required bool
+	secret   bool // redacted when printed
+}
+
+// String returns the setting as it is named in problems.
+func (s setting) String() string {
+	return s.path + " (" + s.env + ")"
+}
+
+// settings returns every setting of c, pointing at its fields.
+func (c *Config) settings() []setting {
+	return []setting{
+		// Server
+		{path: "server.port", env: "PORT", value: &c.Server.Port},
+		{path: "server.trusted_proxies", env: "TRUSTED_PROXIES", value: &c.Server.TrustedProxies},
+		{path: "server.read_timeout", env: "SERVER_READ_TIMEOUT", value: &c.Server.ReadTimeout},
+		{path: "server.write_timeout", env: "SERVER_WRITE_TIMEOUT", value: &c.Server.WriteTimeout},
+		{path: "server.idle_timeout", env: "SERVER_IDLE_TIMEOUT", value: &c.Server.IdleTimeout},
+		{path: "server.drain_delay", env: "SERVER_DRAIN_DELAY", value: &c.Server.DrainDelay},
+		{path: "server.health_check_interval", env: "HEALTH_CHECK_INTERVAL", value: &c.Server.HealthCheckInterval},
+		{path: "server.health_check_timeout", env: "HEALTH_CHECK_TIMEOUT", value: &c.Server.HealthCheckTimeout},
+		{path: "server.integration_retry_interval", env: "INTEGRATION_RETRY_INTERVAL", value: &c.Server.IntegrationRetryInterval},
+
+		// Logger
+		{path: "logger.level", env: "LOG_LEVEL", value: &c.Logger.Level},
+
+		// App
+		{path: "app.env", env: "ENV", value: &c.App.Env},
+		{path: "app.database_url", env: "DATABASE_URL", value: &c.App.DATABASE_URL, required: true, secret: true},
+		{path: "app.session_secret", env: "SESSION_SECRET", value: &c.App.SessionSecret, required: true, secret: true},
+		{path: "app.client_id", env: "CLIENT_ID", value: &c.App.ClientID, required: true},
+		{path: "app.client_secret", env: "SECRET", value: &c.App.ClientSecret, required: true, secret: true},
+		{path: "app.redirect_uri", env: "REDIRECT_URI", value: &c.App.RedirectURI, required: true},
+		{path: "app.redirect_token_uri", env: "REDIRECT_TOKEN_URI", value: &c.App.RedirectTokenURI, required: true},
+		{path: "app.user_info_url", env: "USER_INFO_URL", value: &c.App.User42InfoURL, required: true},
+		{path: "app.jwt_secret", env: "JWT_SECRET", value: &c.App.JWTSecret, required: true, secret: true},
+		{path: "app.oauth_auth_uri", env: "OAUTH_AUTH_URI", value: &c.App.OAuthAuthURI, required: true},
+		{path: "app.oauth_token_uri", env: "OAUTH_TOKEN_URI", value: &c.App.OAuthTokenURI, required: true},
+		{path: "app.keycloak_client_id", env: "KEYCLOAK_CLIENT_ID", value: &c.App.KeycloakClientID, required: true},
+		{path: "app.keycloak_client_secret", env: "KEYCLOAK_CLIENT_SECRET", value: &c.App.KeycloakClientSecret, required: true, secret: true},
+		{path: "app.keycloak_redirect_uri", env: "KEYCLOAK_REDIRECT_URI", value: &c.App.KeycloakRedirectURI, required: true},
+		{path: "app.keycloak_issuer_url", env: "KEYCLOAK_ISSUER_URL", value: &c.App.KeycloakIssuerURL, required: true},
+		{path: "app.keycloak_staff_roles", env: "KEYCLOAK_STAFF_ROLES", value: &c.App.KeycloakStaffRoles},
+
+		// Google, settings are only required while it is enabled
+		{path: "google.enabled", env: "GOOGLE_CALENDAR_ENABLED", value: &c.Google.Enabled},
+		{path: "google.credentials_base64", env: "GOOGLE_CREDENTIALS_BASE64", value: &c.Google.CredentialsBase64, required: true, secret: true},
+		{path: "google.calendar_scope", env: "GOOGLE_CALENDAR_SCOPE", value: &c.Google.CalendarScope},
+		{path: "google.calendar_id", env: "GOOGLE_CALENDAR_ID", value: &c.Google.CalendarID, required: true},
+
+		// Email, settings are only required while it is enabled
+		{path: "email.enabled", env: "EMAIL_ENABLED", value: &c.Email.Enabled},
+		{path: "email.smtp_host", env: "SMTP_HOST", value: &c.Email.SMTPHost, required: true},
+		{path: "email.smtp_port", env: "SMTP_PORT", value: &c.Email.SMTPPort},
+		{path: "email.smtp_username", env: "SMTP_USERNAME", value: &c.Email.SMTPUsername, required: true},
+		{path: "email.smtp_password", env: "SMTP_PASSWORD", value: &c.Email.SMTPPassword, required: true, secret: true},
+		{path: "email.from_email", env: "FROM_EMAIL", value: &c.Email.FromEmail, required: true},
+		{path: "email.from_name", env: "FROM_NAME", value: &c.Email.FromName},
+		{path: "email.use_tls", env: "SMTP_USE_TLS", value: &c.Email.UseTLS},
+
+		// Access
+		{path: "access.default_campus", env: "DEFAULT_CAMPUS", value: &c.Access.DefaultCampus},
+		{path: "access.campus_ids", env: "FT_ALLOWED_CAMPUS_IDS", value: &c.Access.CampusIDs},
+		{path: "access.cursus_ids", env: "FT_ALLOWED_CURSUS_IDS", value: &c.Access.CursusIDs},
+		{path: "access.pool_years", env: "FT_ALLOWED_POOL_YEARS", value: &c.Access.PoolYears},
+		{path: "access.pool_months", env: "FT_ALLOWED_POOL_MONTHS", value: &c.Access.PoolMonths},
+
+		// Tracing
+		{path: "tracing.exporter", env: "OTEL_TRACES_EXPORTER", value: &c.Tracing.Exporter},
+		{path: "tracing.endpoint", env: "OTEL_EXPORTER_OTLP_ENDPOINT", value: &c.Tracing.Endpoint},
+		{path: "tracing.protocol", env: "OTEL_EXPORTER_OTLP_PROTOCOL", value: &c.Tracing.Protocol},
+		{path: "tracing.insecure", env: "OTEL_EXPORTER_OTLP_INSECURE", value: &c.Tracing.Insecure},
+		{path: "tracing.sample_ratio", env: "OTEL_TRACES_SAMPLER_ARG", value: &c.Tracing.SampleRatio},
+		{path: "tracing.service_name", env: "OTEL_SERVICE_NAME", value: &c.Tracing.ServiceName},
+
+		// Rate limits
+		{path: "rate_limit.store", env: "RATE_LIMIT_STORE", value: &c.RateLimit.Store},
+		{path: "rate_limit.redis_url", env: "REDIS_URL", value: &c.RateLimit.RedisURL, secret: true},
+		{path: "rate_limit.oauth", env: "RATE_LIMIT_OAUTH", value: &c.RateLimit.OAuth},
+		{path: "rate_limit.api_ip", env: "RATE_LIMIT_API_IP", value: &c.RateLimit.APIIP},
+		{path: "rate_limit.api", env: "RATE_LIMIT_API", value: &c.RateLimit.API},
+		{path: "rate_limit.rules", env: "RATE_LIMIT_RULES", value: &c.RateLimit.Rules},
+
+		// CORS
+		{path: "cors.origins", env: "CORS_ORIGINS", value: &c.CORS.Origins},
+		{path: "cors.methods", env: "CORS_METHODS", value: &c.CORS.Methods},
+		{path: "cors.headers", env: "CORS_HEADERS", value: &c.CORS.Headers},
+		{path: "cors.exposed_headers", env: "CORS_EXPOSED_HEADERS", value: &c.CORS.ExposedHeaders},
+		{path: "cors.max_age", env: "CORS_MAX_AGE", value: &c.CORS.MaxAge},
+
+		// Login lockouts
+		{path: "lockout.threshold", env: "LOGIN_LOCKOUT_THRESHOLD", value: &c.Lockout.Threshold},
+		{path: "lockout.window", env: "LOGIN_LOCKOUT_WINDOW", value: &c.Lockout.Window},
+		{path: "lockout.base", env: "LOGIN_LOCKOUT_BASE", value: &c.Lockout.Base},
+		{path: "lockout.max", env: "LOGIN_LOCKOUT_MAX", value: &c.Lockout.Max},
+
+		// Secrets
+		{path: "secrets.vault_addr", env: "VAULT_ADDR", value: &c.Secrets.VaultAddr},
+		{path: "secrets.vault_token", env: "VAULT_TOKEN", value: &c.Secrets.VaultToken, secret: true},
+		{path: "secrets.vault_namespace", env: "VAULT_NAMESPACE", value: &c.Secrets.VaultNamespace},
+		{path: "secrets.refresh_interval", env: "SECRETS_REFRESH_INTERVAL", value: &c.Secrets.RefreshInterval},
+	}
+}
+
+// set parses value, as written in an environment variable, into the field.
+// Lists are comma-separated, empty entries are ignored.
+func (s setting) set(value string) error {
+	switch field := s.value.(type) {
+	case *string:
+		*field = value
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		*field = b
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*field = n
+	case *float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		*field = f
+	case *time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		*field = d
+	case *[]string:
+		*field = splitList(value)
+	case *[]int:
+		var values []int
+		for _, v := range splitList(value) {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid integer %q in list", v)
+			}
+			values = append(values, n)
+		}
+		*field = values
+	case *RateLimit:
+		limit, err := ParseRateLimit(value)
+		if err != nil {
+			return err
+		}
+		*field = limit
+	case *[]RateLimitRule:
+		// POST /api/v1/reservations=10/1m,@STAFF=300/1m,POST /api/v1/reservations@STAFF=60/1m
+		var rules []RateLimitRule
+		for _, v := range splitList(value) {
+			rule, err := ParseRateLimitRule(v)
+			if err != nil {
+				return err
+			}
+			rules = append(rules, rule)
+		}
+		*field = rules
+	default:
+		return fmt.Errorf("unsupported setting type %T", s.value)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated list, ignoring empty entries.
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,158 @@
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// checker collects the problems found while validating a configuration.
+type checker struct {
+	settings map[string]setting // by environment variable
+	problems []string
+}
+
+// check records a problem with the setting of env unless ok. Settings out
+// of the scope of the configuration are not checked.
+func (c *checker) check(ok bool, env, format string, args ...any) {
+	s, inScope := c.settings[env]
+	if !ok && inScope {
+		c.problems = append(c.problems, s.String()+": "+fmt.Sprintf(format, args...))
+	}
+}
+
+// inScope reports whether the setting is resolved and validated.
+func (c *Config) inScope(s setting) bool {
+	return c.scope == nil || c.scope(s)
+}
+
+// uses reports whether c uses the setting, the settings of disabled
+// integrations aren't.
+func (c *Config) uses(s setting) bool {
+	switch {
+	case strings.HasPrefix(s.path, "google."):
+		return c.Google.Enabled
+	case strings.HasPrefix(s.path, "email."):
+		return c.Email.Enabled
+	}
+	return true
+}
+
+// validate returns every missing or invalid setting of c.
+func (c *Config) validate() []string {
+	settings := c.settings()
+	ch := &checker{settings: make(map[string]setting, len(settings))}
+	for _, s := range settings {
+		if !c.inScope(s) {
+			continue
+		}
+		ch.settings[s.env] = s
+		if v, ok := s.value.(*string); ok && s.required && c.uses(s) {
+			ch.check(*v != "", s.env, "is required")
+		}
+	}
+
+	// Server
+	ch.check(isPort(c.Server.Port), "PORT", "invalid port %q", c.Server.Port)
+	for _, proxy := range c.Server.TrustedProxies {
+		ch.check(isPrefixOrAddr(proxy), "TRUSTED_PROXIES", "invalid CIDR or IP %q", proxy)
+	}
+	ch.check(c.Server.ReadTimeout > 0, "SERVER_READ_TIMEOUT", "must be positive")
+	ch.check(c.Server.WriteTimeout > 0, "SERVER_WRITE_TIMEOUT", "must be positive")
+	ch.check(c.Server.IdleTimeout > 0, "SERVER_IDLE_TIMEOUT", "must be positive")
+	ch.check(c.Server.DrainDelay >= 0, "SERVER_DRAIN_DELAY", "must not be negative")
+	ch.check(c.Server.HealthCheckInterval > 0, "HEALTH_CHECK_INTERVAL", "must be positive")
+	ch.check(c.Server.HealthCheckTimeout > 0, "HEALTH_CHECK_TIMEOUT", "must be positive")
+	ch.check(c.Server.IntegrationRetryInterval > 0, "INTEGRATION_RETRY_INTERVAL", "must be positive")
+
+	// Logger
+	ch.check(slices.Contains([]string{"debug", "info", "warn", "warning", "error"}, strings.ToLower(c.Logger.Level)),
+		"LOG_LEVEL", "expected debug, info, warn or error, got %q", c.Logger.Level)
+
+	// App, required URLs are only checked once set
+	for env, value := range map[string]string{
+		"REDIRECT_URI":          c.App.RedirectURI,
+		"REDIRECT_TOKEN_URI":    c.App.RedirectTokenURI,
+		"USER_INFO_URL":         c.App.User42InfoURL,
+		"OAUTH_AUTH_URI":        c.App.OAuthAuthURI,
+		"OAUTH_TOKEN_URI":       c.App.OAuthTokenURI,
+		"KEYCLOAK_REDIRECT_URI": c.App.KeycloakRedirectURI,
+		"KEYCLOAK_ISSUER_URL":   c.App.KeycloakIssuerURL,
+	} {
+		ch.check(value == "" || isHTTPURL(value), env, "invalid http(s) URL %q", value)
+	}
+
+	// Email
+	ch.check(c.Email.SMTPPort > 0 && c.Email.SMTPPort <= 65535, "SMTP_PORT", "invalid port %d", c.Email.SMTPPort)
+	if c.Email.FromEmail != "" {
+		_, err := mail.ParseAddress(c.Email.FromEmail)
+		ch.check(err == nil, "FROM_EMAIL", "invalid email address %q", c.Email.FromEmail)
+	}
+
+	// Tracing
+	ch.check(slices.Contains([]string{"none", "otlp"}, c.Tracing.Exporter),
+		"OTEL_TRACES_EXPORTER", "expected none or otlp, got %q", c.Tracing.Exporter)
+	ch.check(slices.Contains([]string{"http/protobuf", "grpc"}, c.Tracing.Protocol),
+		"OTEL_EXPORTER_OTLP_PROTOCOL", "expected http/protobuf or grpc, got %q", c.Tracing.Protocol)
+	ch.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"OTEL_TRACES_SAMPLER_ARG", "must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+
+	// Rate limits
+	ch.check(slices.Contains([]string{"memory", "redis"}, c.RateLimit.Store),
+		"RATE_LIMIT_STORE", "expected memory or redis, got %q", c.RateLimit.Store)
+	if c.RateLimit.Store == "redis" {
+		u, err := url.Parse(c.RateLimit.RedisURL)
+		ch.check(err == nil && (u.Scheme == "redis" || u.Scheme == "rediss"), "REDIS_URL", "invalid redis URL")
+	}
+	ch.check(validLimit(c.RateLimit.OAuth), "RATE_LIMIT_OAUTH", "must allow at least one request per positive period")
+	ch.check(validLimit(c.RateLimit.APIIP), "RATE_LIMIT_API_IP", "must allow at least one request per positive period")
+	ch.check(validLimit(c.RateLimit.API), "RATE_LIMIT_API", "must allow at least one request per positive period")
+	for _, rule := range c.RateLimit.Rules {
+		ch.check((rule.Route != "" || rule.Role != "") && validLimit(rule.Limit),
+			"RATE_LIMIT_RULES", "invalid rule %q", rule.String())
+	}
+
+	// CORS
+	ch.check(len(c.CORS.Methods) > 0, "CORS_METHODS", "must not be empty")
+	ch.check(c.CORS.MaxAge >= 0, "CORS_MAX_AGE", "must not be negative")
+
+	// Login lockouts
+	ch.check(c.Lockout.Threshold > 0, "LOGIN_LOCKOUT_THRESHOLD", "must be positive")
+	ch.check(c.Lockout.Window > 0, "LOGIN_LOCKOUT_WINDOW", "must be positive")
+	ch.check(c.Lockout.Base > 0, "LOGIN_LOCKOUT_BASE", "must be positive")
+	ch.check(c.Lockout.Max >= c.Lockout.Base, "LOGIN_LOCKOUT_MAX", "must not be shorter than %s", c.Lockout.Base)
+
+	// Secrets
+	ch.check(c.Secrets.VaultAddr == "" || isHTTPURL(c.Secrets.VaultAddr),
+		"VAULT_ADDR", "invalid http(s) URL %q", c.Secrets.VaultAddr)
+	ch.check(c.Secrets.RefreshInterval >= 0, "SECRETS_REFRESH_INTERVAL", "must not be negative")
+
+	slices.Sort(ch.problems)
+	return ch.problems
+}
+
+func isPort(s string) bool {
+	port, err := strconv.Atoi(s)
+	return err == nil && port > 0 && port <= 65535
+}
+
+func isPrefixOrAddr(s string) bool {
+	if _, err := netip.ParsePrefix(s); err == nil {
+		return true
+	}
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
+
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validLimit(l RateLimit) bool {
+	return l.Requests > 0 && l.Period > 0
+}
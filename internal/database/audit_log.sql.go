@@ -0,0 +1,127 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: audit_log.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+)
+
+const createAuditEntry = `-- name: CreateAuditEntry :exec
+INSERT INTO audit_log (action, actor_id, user_id, room_id, target_type, target_id, before, after, ip, request_id, campus_id)
+VALUES (
+	$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+	COALESCE(
+		$11::BIGINT,
+		(SELECT campus_id FROM rooms WHERE id = $4),
+		(SELECT campus_id FROM users WHERE id = $3),
+		(SELECT campus_id FROM users WHERE id = $2)
+	)
+)
+`
+
+type CreateAuditEntryParams struct {
+	Action     string
+	ActorID    sql.NullInt64
+	UserID     sql.NullInt64
+	RoomID     sql.NullInt64
+	TargetType string
+	TargetID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	Ip         string
+	RequestID  string
+	CampusID   sql.NullInt64
+}
+
+// Without a campus, the entry belongs to the campus of its room, the user
+// acted on or the actor
+func (q *Queries) CreateAuditEntry(ctx context.Context, arg CreateAuditEntryParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditEntry,
+		arg.Action,
+		arg.ActorID,
+		arg.UserID,
+		arg.RoomID,
+		arg.TargetType,
+		arg.TargetID,
+		arg.Before,
+		arg.After,
+		arg.Ip,
+		arg.RequestID,
+		arg.CampusID,
+	)
+	return err
+}
+
+const listAuditEntries = `-- name: ListAuditEntries :many
+SELECT id, occurred_at, action, actor_id, user_id, room_id, target_type, target_id, before, after, ip, request_id, campus_id FROM audit_log
+WHERE ($1::BIGINT IS NULL OR actor_id = $1 OR user_id = $1)
+  AND ($2::BIGINT IS NULL OR room_id = $2)
+  AND ($3::BIGINT IS NULL OR campus_id = $3)
+  AND ($4::TEXT IS NULL OR action = $4)
+  AND ($5::TIMESTAMPTZ IS NULL OR occurred_at >= $5)
+  AND ($6::TIMESTAMPTZ IS NULL OR occurred_at < $6)
+ORDER BY occurred_at DESC, id DESC
+LIMIT $8 OFFSET $7
+`
+
+type ListAuditEntriesParams struct {
+	UserID   sql.NullInt64
+	RoomID   sql.NullInt64
+	CampusID sql.NullInt64
+	Action   sql.NullString
+	FromTime sql.NullTime
+	ToTime   sql.NullTime
+	SkipRows int32
+	MaxRows  int32
+}
+
+func (q *Queries) ListAuditEntries(ctx context.Context, arg ListAuditEntriesParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, listAuditEntries,
+		arg.UserID,
+		arg.RoomID,
+		arg.CampusID,
+		arg.Action,
+		arg.FromTime,
+		arg.ToTime,
+		arg.SkipRows,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.OccurredAt,
+			&i.Action,
+			&i.ActorID,
+			&i.UserID,
+			&i.RoomID,
+			&i.TargetType,
+			&i.TargetID,
+			&i.Before,
+			&i.After,
+			&i.Ip,
+			&i.RequestID,
+			&i.CampusID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
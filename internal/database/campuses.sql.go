@@ -0,0 +1,186 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: campuses.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+const createCampus = `-- name: CreateCampus :one
+INSERT INTO campuses (slug, name, intra_campus_id, timezone, open_minute, close_minute, login_providers, calendar_id, cors_origins)
+VALUES (
+	$1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING id, slug, name, intra_campus_id, timezone, open_minute, close_minute, login_providers, calendar_id, cors_origins, created_at, updated_at
+`
+
+type CreateCampusParams struct {
+	Slug           string
+	Name           string
+	IntraCampusID  sql.NullInt32
+	Timezone       string
+	OpenMinute     int32
+	CloseMinute    int32
+	LoginProviders []string
+	CalendarID     string
+	CorsOrigins    []string
+}
+
+func (q *Queries) CreateCampus(ctx context.Context, arg CreateCampusParams) (Campus, error) {
+	row := q.db.QueryRowContext(ctx, createCampus,
+		arg.Slug,
+		arg.Name,
+		arg.IntraCampusID,
+		arg.Timezone,
+		arg.OpenMinute,
+		arg.CloseMinute,
+		pq.Array(arg.LoginProviders),
+		arg.CalendarID,
+		pq.Array(arg.CorsOrigins),
+	)
+	var i Campus
+	err := row.Scan(
+		&i.ID,
+		&i.Slug,
+		&i.Name,
+		&i.IntraCampusID,
+		&i.Timezone,
+		&i.OpenMinute,
+		&i.CloseMinute,
+		pq.Array(&i.LoginProviders),
+		&i.CalendarID,
+		pq.Array(&i.CorsOrigins),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getCampus = `-- name: GetCampus :one
+SELECT id, slug, name, intra_campus_id, timezone, open_minute, close_minute, login_providers, calendar_id, cors_origins, created_at, updated_at FROM campuses
+WHERE id = $1
+`
+
+func (q *Queries) GetCampus(ctx context.Context, id int64) (Campus, error) {
+	row := q.db.QueryRowContext(ctx, getCampus, id)
+	var i Campus
+	err := row.Scan(
+		&i.ID,
+		&i.Slug,
+		&i.Name,
+		&i.IntraCampusID,
+		&i.Timezone,
+		&i.OpenMinute,
+		&i.CloseMinute,
+		pq.Array(&i.LoginProviders),
+		&i.CalendarID,
+		pq.Array(&i.CorsOrigins),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listCampuses = `-- name: ListCampuses :many
+SELECT id, slug, name, intra_campus_id, timezone, open_minute, close_minute, login_providers, calendar_id, cors_origins, created_at, updated_at FROM campuses
+ORDER BY id
+`
+
+func (q *Queries) ListCampuses(ctx context.Context) ([]Campus, error) {
+	rows, err := q.db.QueryContext(ctx, listCampuses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Campus
+	for rows.Next() {
+		var i Campus
+		if err := rows.Scan(
+			&i.ID,
+			&i.Slug,
+			&i.Name,
+			&i.IntraCampusID,
+			&i.Timezone,
+			&i.OpenMinute,
+			&i.CloseMinute,
+			pq.Array(&i.LoginProviders),
+			&i.CalendarID,
+			pq.Array(&i.CorsOrigins),
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCampus = `-- name: UpdateCampus :one
+UPDATE campuses
+SET name = $2,
+    intra_campus_id = $3,
+    timezone = $4,
+    open_minute = $5,
+    close_minute = $6,
+    login_providers = $7,
+    calendar_id = $8,
+    cors_origins = $9,
+    updated_at = now()
+WHERE id = $1
+RETURNING id, slug, name, intra_campus_id, timezone, open_minute, close_minute, login_providers, calendar_id, cors_origins, created_at, updated_at
+`
+
+type UpdateCampusParams struct {
+	ID             int64
+	Name           string
+	IntraCampusID  sql.NullInt32
+	Timezone       string
+	OpenMinute     int32
+	CloseMinute    int32
+	LoginProviders []string
+	CalendarID     string
+	CorsOrigins    []string
+}
+
+func (q *Queries) UpdateCampus(ctx context.Context, arg UpdateCampusParams) (Campus, error) {
+	row := q.db.QueryRowContext(ctx, updateCampus,
+		arg.ID,
+		arg.Name,
+		arg.IntraCampusID,
+		arg.Timezone,
+		arg.OpenMinute,
+		arg.CloseMinute,
+		pq.Array(arg.LoginProviders),
+		arg.CalendarID,
+		pq.Array(arg.CorsOrigins),
+	)
+	var i Campus
+	err := row.Scan(
+		&i.ID,
+		&i.Slug,
+		&i.Name,
+		&i.IntraCampusID,
+		&i.Timezone,
+		&i.OpenMinute,
+		&i.CloseMinute,
+		pq.Array(&i.LoginProviders),
+		&i.CalendarID,
+		pq.Array(&i.CorsOrigins),
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
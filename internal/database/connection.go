@@ -10,7 +10,7 @@ import (
 	"time"
 
 	"github.com/IbnBaqqi/book-me/internal/config"
-	_ "github.com/lib/pq" // PostgreSQL driver registration
+	"github.com/lib/pq" // PostgreSQL driver registration
 )
 
 // DB wraps the database connection pool & queries
@@ -45,7 +45,7 @@ func Connect(ctx context.Context, cfg *config.AppConfig) (*DB, error) {
 	dbConn.SetMaxIdleConns(5)
 	dbConn.SetConnMaxLifetime(5 * time.Minute)
 
-	queries := New(dbConn)
+	queries := New(tracedDBTX{db: dbConn})
 
 	return &DB{
 		DB:      dbConn,
@@ -59,7 +59,8 @@ func (db *DB) Close() error {
 	return db.DB.Close()
 }
 
-// BeginTx starts a new database transaction with the specified isolation level
+// BeginTx starts a new database transaction with the specified isolation level.
+// Queries in it are traced when run through WithTx.
 func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
 	tx, err := db.DB.BeginTx(ctx, opts)
 	if err != nil {
@@ -98,3 +99,9 @@ func (tx *Tx) Rollback() error {
 	slog.Debug("transaction rolled back")
 	return nil
 }
+
+// IsUniqueViolation reports whether err is a PostgreSQL unique constraint violation.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
@@ -0,0 +1,138 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: display_devices.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createDisplayDevice = `-- name: CreateDisplayDevice :one
+INSERT INTO display_devices (room_id, name, token_hash, token_prefix, created_by)
+VALUES (
+	$1, $2, $3, $4, $5
+)
+RETURNING id, room_id, name, token_hash, token_prefix, created_by, last_seen_at, created_at
+`
+
+type CreateDisplayDeviceParams struct {
+	RoomID      int64
+	Name        string
+	TokenHash   string
+	TokenPrefix string
+	CreatedBy   sql.NullInt64
+}
+
+func (q *Queries) CreateDisplayDevice(ctx context.Context, arg CreateDisplayDeviceParams) (DisplayDevice, error) {
+	row := q.db.QueryRowContext(ctx, createDisplayDevice,
+		arg.RoomID,
+		arg.Name,
+		arg.TokenHash,
+		arg.TokenPrefix,
+		arg.CreatedBy,
+	)
+	var i DisplayDevice
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Name,
+		&i.TokenHash,
+		&i.TokenPrefix,
+		&i.CreatedBy,
+		&i.LastSeenAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteDisplayDevice = `-- name: DeleteDisplayDevice :execrows
+DELETE FROM display_devices
+WHERE id = $1 AND room_id = $2
+`
+
+type DeleteDisplayDeviceParams struct {
+	ID     int64
+	RoomID int64
+}
+
+func (q *Queries) DeleteDisplayDevice(ctx context.Context, arg DeleteDisplayDeviceParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteDisplayDevice, arg.ID, arg.RoomID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDisplayDeviceByHash = `-- name: GetDisplayDeviceByHash :one
+SELECT id, room_id, name, token_hash, token_prefix, created_by, last_seen_at, created_at FROM display_devices
+WHERE token_hash = $1
+`
+
+func (q *Queries) GetDisplayDeviceByHash(ctx context.Context, tokenHash string) (DisplayDevice, error) {
+	row := q.db.QueryRowContext(ctx, getDisplayDeviceByHash, tokenHash)
+	var i DisplayDevice
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Name,
+		&i.TokenHash,
+		&i.TokenPrefix,
+		&i.CreatedBy,
+		&i.LastSeenAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listDisplayDevicesByRoom = `-- name: ListDisplayDevicesByRoom :many
+SELECT id, room_id, name, token_hash, token_prefix, created_by, last_seen_at, created_at FROM display_devices
+WHERE room_id = $1
+ORDER BY name
+`
+
+func (q *Queries) ListDisplayDevicesByRoom(ctx context.Context, roomID int64) ([]DisplayDevice, error) {
+	rows, err := q.db.QueryContext(ctx, listDisplayDevicesByRoom, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DisplayDevice
+	for rows.Next() {
+		var i DisplayDevice
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Name,
+			&i.TokenHash,
+			&i.TokenPrefix,
+			&i.CreatedBy,
+			&i.LastSeenAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const touchDisplayDevice = `-- name: TouchDisplayDevice :exec
+UPDATE display_devices
+SET last_seen_at = now()
+WHERE id = $1
+  AND (last_seen_at IS NULL OR last_seen_at < now() - INTERVAL '1 minute')
+`
+
+func (q *Queries) TouchDisplayDevice(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, touchDisplayDevice, id)
+	return err
+}
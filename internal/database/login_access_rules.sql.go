@@ -0,0 +1,117 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: login_access_rules.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+const deleteLoginAccessRule = `-- name: DeleteLoginAccessRule :execrows
+DELETE FROM login_access_rules
+WHERE login = $1
+`
+
+func (q *Queries) DeleteLoginAccessRule(ctx context.Context, login string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteLoginAccessRule, login)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getLoginAccessRule = `-- name: GetLoginAccessRule :one
+SELECT id, login, effect, reason, created_by, created_at FROM login_access_rules
+WHERE login = $1
+`
+
+func (q *Queries) GetLoginAccessRule(ctx context.Context, login string) (LoginAccessRule, error) {
+	row := q.db.QueryRowContext(ctx, getLoginAccessRule, login)
+	var i LoginAccessRule
+	err := row.Scan(
+		&i.ID,
+		&i.Login,
+		&i.Effect,
+		&i.Reason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listLoginAccessRules = `-- name: ListLoginAccessRules :many
+SELECT id, login, effect, reason, created_by, created_at FROM login_access_rules
+ORDER BY login
+`
+
+func (q *Queries) ListLoginAccessRules(ctx context.Context) ([]LoginAccessRule, error) {
+	rows, err := q.db.QueryContext(ctx, listLoginAccessRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoginAccessRule
+	for rows.Next() {
+		var i LoginAccessRule
+		if err := rows.Scan(
+			&i.ID,
+			&i.Login,
+			&i.Effect,
+			&i.Reason,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertLoginAccessRule = `-- name: UpsertLoginAccessRule :one
+INSERT INTO login_access_rules (login, effect, reason, created_by)
+VALUES (
+	$1, $2, $3, $4
+)
+ON CONFLICT (login) DO UPDATE
+SET effect = EXCLUDED.effect,
+    reason = EXCLUDED.reason,
+    created_by = EXCLUDED.created_by,
+    created_at = now()
+RETURNING id, login, effect, reason, created_by, created_at
+`
+
+type UpsertLoginAccessRuleParams struct {
+	Login     string
+	Effect    string
+	Reason    string
+	CreatedBy sql.NullInt64
+}
+
+func (q *Queries) UpsertLoginAccessRule(ctx context.Context, arg UpsertLoginAccessRuleParams) (LoginAccessRule, error) {
+	row := q.db.QueryRowContext(ctx, upsertLoginAccessRule,
+		arg.Login,
+		arg.Effect,
+		arg.Reason,
+		arg.CreatedBy,
+	)
+	var i LoginAccessRule
+	err := row.Scan(
+		&i.ID,
+		&i.Login,
+		&i.Effect,
+		&i.Reason,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,182 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: login_lockouts.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createLoginLockout = `-- name: CreateLoginLockout :exec
+INSERT INTO login_lockouts (kind, subject)
+VALUES ($1, $2)
+ON CONFLICT (kind, subject) DO NOTHING
+`
+
+type CreateLoginLockoutParams struct {
+	Kind    string
+	Subject string
+}
+
+func (q *Queries) CreateLoginLockout(ctx context.Context, arg CreateLoginLockoutParams) error {
+	_, err := q.db.ExecContext(ctx, createLoginLockout, arg.Kind, arg.Subject)
+	return err
+}
+
+const deleteLoginLockout = `-- name: DeleteLoginLockout :execrows
+DELETE FROM login_lockouts
+WHERE kind = $1 AND subject = $2
+`
+
+type DeleteLoginLockoutParams struct {
+	Kind    string
+	Subject string
+}
+
+func (q *Queries) DeleteLoginLockout(ctx context.Context, arg DeleteLoginLockoutParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteLoginLockout, arg.Kind, arg.Subject)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getLoginLockout = `-- name: GetLoginLockout :one
+SELECT kind, subject, failures, lockouts, last_reason, last_failure_at, locked_until, id FROM login_lockouts
+WHERE kind = $1 AND subject = $2
+`
+
+type GetLoginLockoutParams struct {
+	Kind    string
+	Subject string
+}
+
+func (q *Queries) GetLoginLockout(ctx context.Context, arg GetLoginLockoutParams) (LoginLockout, error) {
+	row := q.db.QueryRowContext(ctx, getLoginLockout, arg.Kind, arg.Subject)
+	var i LoginLockout
+	err := row.Scan(
+		&i.Kind,
+		&i.Subject,
+		&i.Failures,
+		&i.Lockouts,
+		&i.LastReason,
+		&i.LastFailureAt,
+		&i.LockedUntil,
+		&i.ID,
+	)
+	return i, err
+}
+
+const getLoginLockoutForUpdate = `-- name: GetLoginLockoutForUpdate :one
+SELECT kind, subject, failures, lockouts, last_reason, last_failure_at, locked_until, id FROM login_lockouts
+WHERE kind = $1 AND subject = $2
+FOR UPDATE
+`
+
+type GetLoginLockoutForUpdateParams struct {
+	Kind    string
+	Subject string
+}
+
+func (q *Queries) GetLoginLockoutForUpdate(ctx context.Context, arg GetLoginLockoutForUpdateParams) (LoginLockout, error) {
+	row := q.db.QueryRowContext(ctx, getLoginLockoutForUpdate, arg.Kind, arg.Subject)
+	var i LoginLockout
+	err := row.Scan(
+		&i.Kind,
+		&i.Subject,
+		&i.Failures,
+		&i.Lockouts,
+		&i.LastReason,
+		&i.LastFailureAt,
+		&i.LockedUntil,
+		&i.ID,
+	)
+	return i, err
+}
+
+const listActiveLoginLockouts = `-- name: ListActiveLoginLockouts :many
+SELECT kind, subject, failures, lockouts, last_reason, last_failure_at, locked_until, id FROM login_lockouts
+WHERE locked_until > $1
+ORDER BY locked_until DESC
+`
+
+func (q *Queries) ListActiveLoginLockouts(ctx context.Context, lockedUntil sql.NullTime) ([]LoginLockout, error) {
+	rows, err := q.db.QueryContext(ctx, listActiveLoginLockouts, lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoginLockout
+	for rows.Next() {
+		var i LoginLockout
+		if err := rows.Scan(
+			&i.Kind,
+			&i.Subject,
+			&i.Failures,
+			&i.Lockouts,
+			&i.LastReason,
+			&i.LastFailureAt,
+			&i.LockedUntil,
+			&i.ID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateLoginLockout = `-- name: UpdateLoginLockout :one
+UPDATE login_lockouts
+SET failures = $3,
+    lockouts = $4,
+    last_reason = $5,
+    last_failure_at = $6,
+    locked_until = $7
+WHERE kind = $1 AND subject = $2
+RETURNING kind, subject, failures, lockouts, last_reason, last_failure_at, locked_until, id
+`
+
+type UpdateLoginLockoutParams struct {
+	Kind          string
+	Subject       string
+	Failures      int32
+	Lockouts      int32
+	LastReason    string
+	LastFailureAt time.Time
+	LockedUntil   sql.NullTime
+}
+
+func (q *Queries) UpdateLoginLockout(ctx context.Context, arg UpdateLoginLockoutParams) (LoginLockout, error) {
+	row := q.db.QueryRowContext(ctx, updateLoginLockout,
+		arg.Kind,
+		arg.Subject,
+		arg.Failures,
+		arg.Lockouts,
+		arg.LastReason,
+		arg.LastFailureAt,
+		arg.LockedUntil,
+	)
+	var i LoginLockout
+	err := row.Scan(
+		&i.Kind,
+		&i.Subject,
+		&i.Failures,
+		&i.Lockouts,
+		&i.LastReason,
+		&i.LastFailureAt,
+		&i.LockedUntil,
+		&i.ID,
+	)
+	return i, err
+}
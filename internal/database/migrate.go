@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/sql/schema"
+	"github.com/pressly/goose/v3"
+)
+
+// Migration commands
+const (
+	MigrateUp     = "up"
+	MigrateDown   = "down"
+	MigrateStatus = "status"
+)
+
+// newMigrator creates a goose provider for the migrations embedded in the
+// binary.
+func newMigrator(db *sql.DB) (*goose.Provider, error) {
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, schema.FS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	return provider, nil
+}
+
+// Migrate applies every pending migration, rolls back the last one or lists
+// them all, writing what it did to w.
+func Migrate(ctx context.Context, db *sql.DB, command string, w io.Writer) error {
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case MigrateUp:
+		results, err := migrator.Up(ctx)
+		for _, result := range results {
+			printMigration(w, result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to migrate up: %w", err)
+		}
+		if len(results) == 0 {
+			_, _ = fmt.Fprintln(w, "no pending migrations")
+		}
+	case MigrateDown:
+		result, err := migrator.Down(ctx)
+		if result != nil {
+			printMigration(w, result)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to migrate down: %w", err)
+		}
+	case MigrateStatus:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get migration status: %w", err)
+		}
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.State == goose.StateApplied {
+				appliedAt = status.AppliedAt.Local().Format(time.DateTime)
+			}
+			_, _ = fmt.Fprintf(w, "%-20s %s\n", appliedAt, status.Source.Path)
+		}
+	default:
+		return fmt.Errorf("unknown migration command %q, expected up, down or status", command)
+	}
+	return nil
+}
+
+// printMigration writes the outcome of one applied or rolled back migration.
+func printMigration(w io.Writer, result *goose.MigrationResult) {
+	if result.Error != nil {
+		_, _ = fmt.Fprintf(w, "FAIL %-6s %s: %v\n", result.Direction, result.Source.Path, result.Error)
+		return
+	}
+	_, _ = fmt.Fprintf(w, "OK   %-6s %s (%s)\n", result.Direction, result.Source.Path, result.Duration.Round(time.Millisecond))
+}
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/IbnBaqqi/book-me/sql/schema"
+)
+
+func TestNewMigrator_EmbedsEveryMigration(t *testing.T) {
+	// sql.Open doesn't connect, listing the sources needs no database.
+	db, err := sql.Open("postgres", "postgres://localhost/bookme?sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	migrator, err := newMigrator(db)
+	if err != nil {
+		t.Fatalf("newMigrator() error = %v", err)
+	}
+
+	files, err := fs.Glob(schema.FS, "*.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sources := migrator.ListSources()
+	if len(files) == 0 || len(sources) != len(files) {
+		t.Fatalf("got %d migrations, want the %d embedded files", len(sources), len(files))
+	}
+	for i, source := range sources {
+		if source.Version != int64(i+1) {
+			t.Errorf("migration %s has version %d, want %d", source.Path, source.Version, i+1)
+		}
+	}
+}
+
+func TestMigrate_UnknownCommand(t *testing.T) {
+	db, err := sql.Open("postgres", "postgres://localhost/bookme?sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	var out bytes.Buffer
+	err = Migrate(context.Background(), db, "sideways", &out)
+	if err == nil || !strings.Contains(err.Error(), "unknown migration command") {
+		t.Errorf("Migrate() error = %v, want unknown migration command", err)
+	}
+}
@@ -6,9 +6,98 @@ package database
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+type ApiToken struct {
+	ID          int64
+	UserID      int64
+	Name        string
+	TokenHash   string
+	TokenPrefix string
+	Scopes      []string
+	ExpiresAt   time.Time
+	LastUsedAt  sql.NullTime
+	CreatedAt   time.Time
+}
+
+type AuditLog struct {
+	ID         int64
+	OccurredAt time.Time
+	Action     string
+	ActorID    sql.NullInt64
+	UserID     sql.NullInt64
+	RoomID     sql.NullInt64
+	TargetType string
+	TargetID   string
+	Before     json.RawMessage
+	After      json.RawMessage
+	Ip         string
+	RequestID  string
+	CampusID   sql.NullInt64
+}
+
+type Campus struct {
+	ID             int64
+	Slug           string
+	Name           string
+	IntraCampusID  sql.NullInt32
+	Timezone       string
+	OpenMinute     int32
+	CloseMinute    int32
+	LoginProviders []string
+	CalendarID     string
+	CorsOrigins    []string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type DisplayDevice struct {
+	ID          int64
+	RoomID      int64
+	Name        string
+	TokenHash   string
+	TokenPrefix string
+	CreatedBy   sql.NullInt64
+	LastSeenAt  sql.NullTime
+	CreatedAt   time.Time
+}
+
+type LoginAccessRule struct {
+	ID        int64
+	Login     string
+	Effect    string
+	Reason    string
+	CreatedBy sql.NullInt64
+	CreatedAt time.Time
+}
+
+type LoginLockout struct {
+	Kind          string
+	Subject       string
+	Failures      int32
+	Lockouts      int32
+	LastReason    string
+	LastFailureAt time.Time
+	LockedUntil   sql.NullTime
+	ID            int64
+}
+
+type OpeningHoursException struct {
+	ID          int64
+	Date        time.Time
+	Closed      bool
+	OpenMinute  int32
+	CloseMinute int32
+	Name        string
+	SourceUid   string
+	CreatedBy   sql.NullInt64
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	CampusID    int64
+}
+
 type Reservation struct {
 	ID          int64
 	UserID      int64
@@ -17,16 +106,57 @@ type Reservation struct {
 	EndTime     time.Time
 	Status      string
 	GcalEventID sql.NullString
+	CampusID    int64
+}
+
+type RoleAssignment struct {
+	ID        int64
+	UserID    int64
+	Role      string
+	RoomID    sql.NullInt64
+	GrantedBy sql.NullInt64
+	CreatedAt time.Time
 }
 
 type Room struct {
-	ID   int64
-	Name string
+	ID       int64
+	Name     string
+	CampusID int64
+}
+
+type RoomBlackout struct {
+	ID          int64
+	RoomID      int64
+	Reason      string
+	StartTime   time.Time
+	EndTime     time.Time
+	Recurrence  string
+	RepeatUntil sql.NullTime
+	CreatedBy   sql.NullInt64
+	CreatedAt   time.Time
 }
 
 type User struct {
-	ID    int64
-	Email string
-	Name  string
-	Role  string
+	ID               int64
+	Email            string
+	Name             string
+	Role             string
+	Login            sql.NullString
+	AvatarUrl        sql.NullString
+	IntraCampusID    sql.NullInt32
+	UpdatedAt        time.Time
+	SuspendedAt      sql.NullTime
+	SuspendedReason  string
+	TokensValidAfter sql.NullTime
+	AnonymizedAt     sql.NullTime
+	CampusID         int64
+}
+
+type UserIdentity struct {
+	ID          int64
+	UserID      int64
+	Provider    string
+	Subject     string
+	CreatedAt   time.Time
+	LastLoginAt time.Time
 }
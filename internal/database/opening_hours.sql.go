@@ -0,0 +1,195 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: opening_hours.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const deleteOpeningHoursException = `-- name: DeleteOpeningHoursException :execrows
+DELETE FROM opening_hours_exceptions
+WHERE campus_id = $1 AND date = $2
+`
+
+type DeleteOpeningHoursExceptionParams struct {
+	CampusID int64
+	Date     time.Time
+}
+
+func (q *Queries) DeleteOpeningHoursException(ctx context.Context, arg DeleteOpeningHoursExceptionParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOpeningHoursException, arg.CampusID, arg.Date)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getOpeningHoursException = `-- name: GetOpeningHoursException :one
+SELECT id, date, closed, open_minute, close_minute, name, source_uid, created_by, created_at, updated_at, campus_id FROM opening_hours_exceptions
+WHERE campus_id = $1 AND date = $2
+`
+
+type GetOpeningHoursExceptionParams struct {
+	CampusID int64
+	Date     time.Time
+}
+
+func (q *Queries) GetOpeningHoursException(ctx context.Context, arg GetOpeningHoursExceptionParams) (OpeningHoursException, error) {
+	row := q.db.QueryRowContext(ctx, getOpeningHoursException, arg.CampusID, arg.Date)
+	var i OpeningHoursException
+	err := row.Scan(
+		&i.ID,
+		&i.Date,
+		&i.Closed,
+		&i.OpenMinute,
+		&i.CloseMinute,
+		&i.Name,
+		&i.SourceUid,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CampusID,
+	)
+	return i, err
+}
+
+const listOpeningHoursExceptions = `-- name: ListOpeningHoursExceptions :many
+SELECT id, date, closed, open_minute, close_minute, name, source_uid, created_by, created_at, updated_at, campus_id FROM opening_hours_exceptions
+ORDER BY campus_id, date
+`
+
+func (q *Queries) ListOpeningHoursExceptions(ctx context.Context) ([]OpeningHoursException, error) {
+	rows, err := q.db.QueryContext(ctx, listOpeningHoursExceptions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OpeningHoursException
+	for rows.Next() {
+		var i OpeningHoursException
+		if err := rows.Scan(
+			&i.ID,
+			&i.Date,
+			&i.Closed,
+			&i.OpenMinute,
+			&i.CloseMinute,
+			&i.Name,
+			&i.SourceUid,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CampusID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpeningHoursExceptionsByCampus = `-- name: ListOpeningHoursExceptionsByCampus :many
+SELECT id, date, closed, open_minute, close_minute, name, source_uid, created_by, created_at, updated_at, campus_id FROM opening_hours_exceptions
+WHERE campus_id = $1
+ORDER BY date
+`
+
+func (q *Queries) ListOpeningHoursExceptionsByCampus(ctx context.Context, campusID int64) ([]OpeningHoursException, error) {
+	rows, err := q.db.QueryContext(ctx, listOpeningHoursExceptionsByCampus, campusID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OpeningHoursException
+	for rows.Next() {
+		var i OpeningHoursException
+		if err := rows.Scan(
+			&i.ID,
+			&i.Date,
+			&i.Closed,
+			&i.OpenMinute,
+			&i.CloseMinute,
+			&i.Name,
+			&i.SourceUid,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CampusID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertOpeningHoursException = `-- name: UpsertOpeningHoursException :one
+INSERT INTO opening_hours_exceptions (campus_id, date, closed, open_minute, close_minute, name, source_uid, created_by)
+VALUES (
+	$1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (campus_id, date) DO UPDATE
+SET closed = EXCLUDED.closed,
+    open_minute = EXCLUDED.open_minute,
+    close_minute = EXCLUDED.close_minute,
+    name = EXCLUDED.name,
+    source_uid = EXCLUDED.source_uid,
+    created_by = EXCLUDED.created_by,
+    updated_at = now()
+RETURNING id, date, closed, open_minute, close_minute, name, source_uid, created_by, created_at, updated_at, campus_id
+`
+
+type UpsertOpeningHoursExceptionParams struct {
+	CampusID    int64
+	Date        time.Time
+	Closed      bool
+	OpenMinute  int32
+	CloseMinute int32
+	Name        string
+	SourceUid   string
+	CreatedBy   sql.NullInt64
+}
+
+func (q *Queries) UpsertOpeningHoursException(ctx context.Context, arg UpsertOpeningHoursExceptionParams) (OpeningHoursException, error) {
+	row := q.db.QueryRowContext(ctx, upsertOpeningHoursException,
+		arg.CampusID,
+		arg.Date,
+		arg.Closed,
+		arg.OpenMinute,
+		arg.CloseMinute,
+		arg.Name,
+		arg.SourceUid,
+		arg.CreatedBy,
+	)
+	var i OpeningHoursException
+	err := row.Scan(
+		&i.ID,
+		&i.Date,
+		&i.Closed,
+		&i.OpenMinute,
+		&i.CloseMinute,
+		&i.Name,
+		&i.SourceUid,
+		&i.CreatedBy,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CampusID,
+	)
+	return i, err
+}
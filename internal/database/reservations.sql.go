@@ -12,11 +12,11 @@ import (
 )
 
 const createReservation = `-- name: CreateReservation :one
-INSERT INTO reservations (user_id, room_id, start_time, end_time, status)
+INSERT INTO reservations (user_id, room_id, start_time, end_time, status, campus_id)
 VALUES (
-	$1, $2, $3, $4, $5
+	$1, $2, $3, $4, $5, $6
 )
-RETURNING id, user_id, room_id, start_time, end_time, status, gcal_event_id
+RETURNING id, user_id, room_id, start_time, end_time, status, gcal_event_id, campus_id
 `
 
 type CreateReservationParams struct {
@@ -25,6 +25,7 @@ type CreateReservationParams struct {
 	StartTime time.Time
 	EndTime   time.Time
 	Status    string
+	CampusID  int64
 }
 
 func (q *Queries) CreateReservation(ctx context.Context, arg CreateReservationParams) (Reservation, error) {
@@ -34,6 +35,7 @@ func (q *Queries) CreateReservation(ctx context.Context, arg CreateReservationPa
 		arg.StartTime,
 		arg.EndTime,
 		arg.Status,
+		arg.CampusID,
 	)
 	var i Reservation
 	err := row.Scan(
@@ -44,10 +46,22 @@ func (q *Queries) CreateReservation(ctx context.Context, arg CreateReservationPa
 		&i.EndTime,
 		&i.Status,
 		&i.GcalEventID,
+		&i.CampusID,
 	)
 	return i, err
 }
 
+const deleteFutureReservationsByUser = `-- name: DeleteFutureReservationsByUser :exec
+DELETE FROM reservations
+WHERE user_id = $1
+  AND start_time > now()
+`
+
+func (q *Queries) DeleteFutureReservationsByUser(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteFutureReservationsByUser, userID)
+	return err
+}
+
 const deleteReservation = `-- name: DeleteReservation :exec
 DELETE FROM reservations
 WHERE id = $1
@@ -96,12 +110,14 @@ INNER JOIN users u ON r.user_id = u.id
 INNER JOIN rooms room ON r.room_id = room.id
 WHERE r.start_time >= $1
   AND r.end_time <= $2
+  AND r.campus_id = $3
 ORDER BY r.room_id, r.start_time
 `
 
 type GetAllBetweenDatesParams struct {
 	StartTime time.Time
 	EndTime   time.Time
+	CampusID  int64
 }
 
 type GetAllBetweenDatesRow struct {
@@ -115,7 +131,7 @@ type GetAllBetweenDatesRow struct {
 }
 
 func (q *Queries) GetAllBetweenDates(ctx context.Context, arg GetAllBetweenDatesParams) ([]GetAllBetweenDatesRow, error) {
-	rows, err := q.db.QueryContext(ctx, getAllBetweenDates, arg.StartTime, arg.EndTime)
+	rows, err := q.db.QueryContext(ctx, getAllBetweenDates, arg.StartTime, arg.EndTime, arg.CampusID)
 	if err != nil {
 		return nil, err
 	}
@@ -146,7 +162,7 @@ func (q *Queries) GetAllBetweenDates(ctx context.Context, arg GetAllBetweenDates
 }
 
 const getReservationByID = `-- name: GetReservationByID :one
-SELECT id, user_id, room_id, start_time, end_time, status, gcal_event_id FROM reservations
+SELECT id, user_id, room_id, start_time, end_time, status, gcal_event_id, campus_id FROM reservations
 WHERE id = $1
 `
 
@@ -161,12 +177,128 @@ func (q *Queries) GetReservationByID(ctx context.Context, id int64) (Reservation
 		&i.EndTime,
 		&i.Status,
 		&i.GcalEventID,
+		&i.CampusID,
 	)
 	return i, err
 }
 
+const listFutureReservationsByUser = `-- name: ListFutureReservationsByUser :many
+SELECT id, user_id, room_id, start_time, end_time, status, gcal_event_id, campus_id FROM reservations
+WHERE user_id = $1
+  AND start_time > now()
+`
+
+func (q *Queries) ListFutureReservationsByUser(ctx context.Context, userID int64) ([]Reservation, error) {
+	rows, err := q.db.QueryContext(ctx, listFutureReservationsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reservation
+	for rows.Next() {
+		var i Reservation
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RoomID,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.GcalEventID,
+			&i.CampusID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReservationsBetween = `-- name: ListReservationsBetween :many
+SELECT
+    r.id,
+    r.campus_id,
+    r.room_id,
+    r.user_id,
+    r.start_time,
+    r.end_time,
+    r.status,
+    r.gcal_event_id,
+    room.name as room_name,
+    u.name as user_name,
+    u.email as user_email
+FROM reservations r
+INNER JOIN users u ON r.user_id = u.id
+INNER JOIN rooms room ON r.room_id = room.id
+WHERE r.end_time > $1
+  AND r.start_time < $2
+  AND ($3::BIGINT IS NULL OR r.campus_id = $3)
+ORDER BY r.start_time, r.room_id
+`
+
+type ListReservationsBetweenParams struct {
+	FromTime time.Time
+	ToTime   time.Time
+	CampusID sql.NullInt64
+}
+
+type ListReservationsBetweenRow struct {
+	ID          int64
+	CampusID    int64
+	RoomID      int64
+	UserID      int64
+	StartTime   time.Time
+	EndTime     time.Time
+	Status      string
+	GcalEventID sql.NullString
+	RoomName    string
+	UserName    string
+	UserEmail   string
+}
+
+func (q *Queries) ListReservationsBetween(ctx context.Context, arg ListReservationsBetweenParams) ([]ListReservationsBetweenRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReservationsBetween, arg.FromTime, arg.ToTime, arg.CampusID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReservationsBetweenRow
+	for rows.Next() {
+		var i ListReservationsBetweenRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CampusID,
+			&i.RoomID,
+			&i.UserID,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.GcalEventID,
+			&i.RoomName,
+			&i.UserName,
+			&i.UserEmail,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listReservationsByRoom = `-- name: ListReservationsByRoom :many
-SELECT id, user_id, room_id, start_time, end_time, status, gcal_event_id FROM reservations
+SELECT id, user_id, room_id, start_time, end_time, status, gcal_event_id, campus_id FROM reservations
 WHERE room_id = $1
 ORDER BY start_time ASC
 `
@@ -188,6 +320,7 @@ func (q *Queries) ListReservationsByRoom(ctx context.Context, roomID int64) ([]R
 			&i.EndTime,
 			&i.Status,
 			&i.GcalEventID,
+			&i.CampusID,
 		); err != nil {
 			return nil, err
 		}
@@ -202,10 +335,171 @@ func (q *Queries) ListReservationsByRoom(ctx context.Context, roomID int64) ([]R
 	return items, nil
 }
 
-const updateGoogleCalID = `-- name: UpdateGoogleCalID :exec
+const listReservationsByRoomBetween = `-- name: ListReservationsByRoomBetween :many
+SELECT id, user_id, room_id, start_time, end_time, status, gcal_event_id, campus_id FROM reservations
+WHERE room_id = $1
+  AND start_time < $2
+  AND end_time > $3
+ORDER BY start_time ASC
+FOR UPDATE
+`
+
+type ListReservationsByRoomBetweenParams struct {
+	RoomID   int64
+	ToTime   time.Time
+	FromTime time.Time
+}
+
+func (q *Queries) ListReservationsByRoomBetween(ctx context.Context, arg ListReservationsByRoomBetweenParams) ([]Reservation, error) {
+	rows, err := q.db.QueryContext(ctx, listReservationsByRoomBetween, arg.RoomID, arg.ToTime, arg.FromTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reservation
+	for rows.Next() {
+		var i Reservation
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RoomID,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.GcalEventID,
+			&i.CampusID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReservationsByUser = `-- name: ListReservationsByUser :many
+SELECT
+    r.id,
+    r.room_id,
+    r.start_time,
+    r.end_time,
+    r.status,
+    room.name as room_name
+FROM reservations r
+INNER JOIN rooms room ON r.room_id = room.id
+WHERE r.user_id = $1
+  AND ($2::TIMESTAMPTZ IS NULL OR r.end_time > $2)
+  AND ($3::TIMESTAMPTZ IS NULL OR r.start_time < $3)
+ORDER BY r.start_time DESC
+LIMIT $4
+`
+
+type ListReservationsByUserParams struct {
+	UserID   int64
+	FromTime sql.NullTime
+	ToTime   sql.NullTime
+	MaxRows  int32
+}
+
+type ListReservationsByUserRow struct {
+	ID        int64
+	RoomID    int64
+	StartTime time.Time
+	EndTime   time.Time
+	Status    string
+	RoomName  string
+}
+
+func (q *Queries) ListReservationsByUser(ctx context.Context, arg ListReservationsByUserParams) ([]ListReservationsByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, listReservationsByUser,
+		arg.UserID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReservationsByUserRow
+	for rows.Next() {
+		var i ListReservationsByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.RoomName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUpcomingReservationsByRoom = `-- name: ListUpcomingReservationsByRoom :many
+SELECT id, user_id, room_id, start_time, end_time, status, gcal_event_id, campus_id FROM reservations
+WHERE room_id = $1
+  AND end_time > $2
+ORDER BY start_time ASC
+LIMIT $3
+`
+
+type ListUpcomingReservationsByRoomParams struct {
+	RoomID  int64
+	EndTime time.Time
+	Limit   int32
+}
+
+func (q *Queries) ListUpcomingReservationsByRoom(ctx context.Context, arg ListUpcomingReservationsByRoomParams) ([]Reservation, error) {
+	rows, err := q.db.QueryContext(ctx, listUpcomingReservationsByRoom, arg.RoomID, arg.EndTime, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Reservation
+	for rows.Next() {
+		var i Reservation
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RoomID,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Status,
+			&i.GcalEventID,
+			&i.CampusID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateGoogleCalID = `-- name: UpdateGoogleCalID :execrows
 UPDATE reservations
 SET gcal_event_id = $2
-WHERE id = $1
+WHERE id = $1 AND gcal_event_id IS NULL
 `
 
 type UpdateGoogleCalIDParams struct {
@@ -213,7 +507,10 @@ type UpdateGoogleCalIDParams struct {
 	GcalEventID sql.NullString
 }
 
-func (q *Queries) UpdateGoogleCalID(ctx context.Context, arg UpdateGoogleCalIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateGoogleCalID, arg.ID, arg.GcalEventID)
-	return err
+func (q *Queries) UpdateGoogleCalID(ctx context.Context, arg UpdateGoogleCalIDParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateGoogleCalID, arg.ID, arg.GcalEventID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
@@ -0,0 +1,120 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: role_assignments.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+const createRoleAssignment = `-- name: CreateRoleAssignment :one
+INSERT INTO role_assignments (user_id, role, room_id, granted_by)
+VALUES (
+	$1, $2, $3, $4
+)
+RETURNING id, user_id, role, room_id, granted_by, created_at
+`
+
+type CreateRoleAssignmentParams struct {
+	UserID    int64
+	Role      string
+	RoomID    sql.NullInt64
+	GrantedBy sql.NullInt64
+}
+
+func (q *Queries) CreateRoleAssignment(ctx context.Context, arg CreateRoleAssignmentParams) (RoleAssignment, error) {
+	row := q.db.QueryRowContext(ctx, createRoleAssignment,
+		arg.UserID,
+		arg.Role,
+		arg.RoomID,
+		arg.GrantedBy,
+	)
+	var i RoleAssignment
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Role,
+		&i.RoomID,
+		&i.GrantedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteRoleAssignment = `-- name: DeleteRoleAssignment :exec
+DELETE FROM role_assignments
+WHERE id = $1
+`
+
+func (q *Queries) DeleteRoleAssignment(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteRoleAssignment, id)
+	return err
+}
+
+const deleteRoleAssignmentsByUser = `-- name: DeleteRoleAssignmentsByUser :exec
+DELETE FROM role_assignments
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteRoleAssignmentsByUser(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteRoleAssignmentsByUser, userID)
+	return err
+}
+
+const getRoleAssignment = `-- name: GetRoleAssignment :one
+SELECT id, user_id, role, room_id, granted_by, created_at FROM role_assignments
+WHERE id = $1
+`
+
+func (q *Queries) GetRoleAssignment(ctx context.Context, id int64) (RoleAssignment, error) {
+	row := q.db.QueryRowContext(ctx, getRoleAssignment, id)
+	var i RoleAssignment
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Role,
+		&i.RoomID,
+		&i.GrantedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listRoleAssignmentsByUser = `-- name: ListRoleAssignmentsByUser :many
+SELECT id, user_id, role, room_id, granted_by, created_at FROM role_assignments
+WHERE user_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListRoleAssignmentsByUser(ctx context.Context, userID int64) ([]RoleAssignment, error) {
+	rows, err := q.db.QueryContext(ctx, listRoleAssignmentsByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoleAssignment
+	for rows.Next() {
+		var i RoleAssignment
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Role,
+			&i.RoomID,
+			&i.GrantedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,216 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: room_blackouts.sql
+
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const createRoomBlackout = `-- name: CreateRoomBlackout :one
+INSERT INTO room_blackouts (room_id, reason, start_time, end_time, recurrence, repeat_until, created_by)
+VALUES (
+	$1, $2, $3, $4, $5, $6, $7
+)
+RETURNING id, room_id, reason, start_time, end_time, recurrence, repeat_until, created_by, created_at
+`
+
+type CreateRoomBlackoutParams struct {
+	RoomID      int64
+	Reason      string
+	StartTime   time.Time
+	EndTime     time.Time
+	Recurrence  string
+	RepeatUntil sql.NullTime
+	CreatedBy   sql.NullInt64
+}
+
+func (q *Queries) CreateRoomBlackout(ctx context.Context, arg CreateRoomBlackoutParams) (RoomBlackout, error) {
+	row := q.db.QueryRowContext(ctx, createRoomBlackout,
+		arg.RoomID,
+		arg.Reason,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Recurrence,
+		arg.RepeatUntil,
+		arg.CreatedBy,
+	)
+	var i RoomBlackout
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Reason,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Recurrence,
+		&i.RepeatUntil,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteRoomBlackout = `-- name: DeleteRoomBlackout :execrows
+DELETE FROM room_blackouts
+WHERE id = $1 AND room_id = $2
+`
+
+type DeleteRoomBlackoutParams struct {
+	ID     int64
+	RoomID int64
+}
+
+func (q *Queries) DeleteRoomBlackout(ctx context.Context, arg DeleteRoomBlackoutParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteRoomBlackout, arg.ID, arg.RoomID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getRoomBlackout = `-- name: GetRoomBlackout :one
+SELECT id, room_id, reason, start_time, end_time, recurrence, repeat_until, created_by, created_at FROM room_blackouts
+WHERE id = $1 AND room_id = $2
+`
+
+type GetRoomBlackoutParams struct {
+	ID     int64
+	RoomID int64
+}
+
+func (q *Queries) GetRoomBlackout(ctx context.Context, arg GetRoomBlackoutParams) (RoomBlackout, error) {
+	row := q.db.QueryRowContext(ctx, getRoomBlackout, arg.ID, arg.RoomID)
+	var i RoomBlackout
+	err := row.Scan(
+		&i.ID,
+		&i.RoomID,
+		&i.Reason,
+		&i.StartTime,
+		&i.EndTime,
+		&i.Recurrence,
+		&i.RepeatUntil,
+		&i.CreatedBy,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listRoomBlackoutsBetween = `-- name: ListRoomBlackoutsBetween :many
+SELECT
+    b.id, b.room_id, b.reason, b.start_time, b.end_time, b.recurrence, b.repeat_until, b.created_by, b.created_at,
+    room.name as room_name,
+    room.campus_id
+FROM room_blackouts b
+INNER JOIN rooms room ON b.room_id = room.id
+WHERE ($1::BIGINT IS NULL OR b.room_id = $1)
+  AND ($2::BIGINT IS NULL OR room.campus_id = $2)
+  AND b.start_time < $3
+  AND COALESCE(b.repeat_until + (b.end_time - b.start_time), b.end_time) > $4::TIMESTAMPTZ
+ORDER BY b.room_id, b.start_time
+`
+
+type ListRoomBlackoutsBetweenParams struct {
+	RoomID   sql.NullInt64
+	CampusID sql.NullInt64
+	ToTime   time.Time
+	FromTime time.Time
+}
+
+type ListRoomBlackoutsBetweenRow struct {
+	ID          int64
+	RoomID      int64
+	Reason      string
+	StartTime   time.Time
+	EndTime     time.Time
+	Recurrence  string
+	RepeatUntil sql.NullTime
+	CreatedBy   sql.NullInt64
+	CreatedAt   time.Time
+	RoomName    string
+	CampusID    int64
+}
+
+// Blackouts with an occurrence that may overlap [from_time, to_time),
+// recurring ones are expanded by the caller.
+func (q *Queries) ListRoomBlackoutsBetween(ctx context.Context, arg ListRoomBlackoutsBetweenParams) ([]ListRoomBlackoutsBetweenRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRoomBlackoutsBetween,
+		arg.RoomID,
+		arg.CampusID,
+		arg.ToTime,
+		arg.FromTime,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRoomBlackoutsBetweenRow
+	for rows.Next() {
+		var i ListRoomBlackoutsBetweenRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Reason,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Recurrence,
+			&i.RepeatUntil,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.RoomName,
+			&i.CampusID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRoomBlackoutsByRoom = `-- name: ListRoomBlackoutsByRoom :many
+SELECT id, room_id, reason, start_time, end_time, recurrence, repeat_until, created_by, created_at FROM room_blackouts
+WHERE room_id = $1
+ORDER BY start_time
+`
+
+func (q *Queries) ListRoomBlackoutsByRoom(ctx context.Context, roomID int64) ([]RoomBlackout, error) {
+	rows, err := q.db.QueryContext(ctx, listRoomBlackoutsByRoom, roomID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RoomBlackout
+	for rows.Next() {
+		var i RoomBlackout
+		if err := rows.Scan(
+			&i.ID,
+			&i.RoomID,
+			&i.Reason,
+			&i.StartTime,
+			&i.EndTime,
+			&i.Recurrence,
+			&i.RepeatUntil,
+			&i.CreatedBy,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
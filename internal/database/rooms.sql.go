@@ -9,14 +9,76 @@ import (
 	"context"
 )
 
+const createRoom = `-- name: CreateRoom :one
+INSERT INTO rooms (campus_id, name)
+VALUES (
+	$1, $2
+)
+RETURNING id, name, campus_id
+`
+
+type CreateRoomParams struct {
+	CampusID int64
+	Name     string
+}
+
+func (q *Queries) CreateRoom(ctx context.Context, arg CreateRoomParams) (Room, error) {
+	row := q.db.QueryRowContext(ctx, createRoom, arg.CampusID, arg.Name)
+	var i Room
+	err := row.Scan(&i.ID, &i.Name, &i.CampusID)
+	return i, err
+}
+
 const getRoomByID = `-- name: GetRoomByID :one
-SELECT id, name FROM rooms
+SELECT id, name, campus_id FROM rooms
 WHERE id = $1
 `
 
 func (q *Queries) GetRoomByID(ctx context.Context, id int64) (Room, error) {
 	row := q.db.QueryRowContext(ctx, getRoomByID, id)
 	var i Room
-	err := row.Scan(&i.ID, &i.Name)
+	err := row.Scan(&i.ID, &i.Name, &i.CampusID)
 	return i, err
 }
+
+const listRoomsByCampus = `-- name: ListRoomsByCampus :many
+SELECT id, name, campus_id FROM rooms
+WHERE campus_id = $1
+ORDER BY name
+`
+
+func (q *Queries) ListRoomsByCampus(ctx context.Context, campusID int64) ([]Room, error) {
+	rows, err := q.db.QueryContext(ctx, listRoomsByCampus, campusID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Room
+	for rows.Next() {
+		var i Room
+		if err := rows.Scan(&i.ID, &i.Name, &i.CampusID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockRoom = `-- name: LockRoom :one
+SELECT id FROM rooms
+WHERE id = $1
+FOR UPDATE
+`
+
+// Serializes the bookings and blackouts of a room until the transaction ends
+func (q *Queries) LockRoom(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, lockRoom, id)
+	err := row.Scan(&id)
+	return id, err
+}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/IbnBaqqi/book-me/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedDBTX starts a span for every sqlc query, named after the query.
+type tracedDBTX struct {
+	db DBTX
+}
+
+func (t tracedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuery(ctx, query)
+	result, err := t.db.ExecContext(ctx, query, args...)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (t tracedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	ctx, span := startQuery(ctx, query)
+	stmt, err := t.db.PrepareContext(ctx, query)
+	tracing.End(span, err)
+	return stmt, err
+}
+
+func (t tracedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuery(ctx, query)
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	tracing.End(span, err)
+	return rows, err
+}
+
+func (t tracedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuery(ctx, query)
+	row := t.db.QueryRowContext(ctx, query, args...)
+	tracing.End(span, row.Err())
+	return row
+}
+
+// WithTx returns queries running in tx, traced like the ones of db.
+func (db *DB) WithTx(tx *sql.Tx) *Queries {
+	return New(tracedDBTX{db: tx})
+}
+
+func startQuery(ctx context.Context, query string) (context.Context, trace.Span) {
+	name := queryName(query)
+	return tracing.Start(ctx, "db."+name,
+		attribute.String("db.system.name", "postgresql"),
+		attribute.String("db.operation.name", name),
+	)
+}
+
+// queryName reads the name sqlc puts in front of every query as
+// "-- name: GetUser :one", falling back to "query" for others.
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return "query"
+	}
+	if name, _, ok := strings.Cut(rest, " "); ok && name != "" {
+		return name
+	}
+	return "query"
+}
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/IbnBaqqi/book-me/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// failingDBTX fails every query, it only needs to be called.
+type failingDBTX struct{}
+
+var errQuery = errors.New("connection refused")
+
+func (failingDBTX) ExecContext(context.Context, string, ...interface{}) (sql.Result, error) {
+	return nil, errQuery
+}
+
+func (failingDBTX) PrepareContext(context.Context, string) (*sql.Stmt, error) {
+	return nil, errQuery
+}
+
+func (failingDBTX) QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error) {
+	return nil, errQuery
+}
+
+func (failingDBTX) QueryRowContext(context.Context, string, ...interface{}) *sql.Row {
+	return &sql.Row{}
+}
+
+func TestTracedQueries(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(exporter, "book-me-test", 1)
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	q := New(tracedDBTX{db: failingDBTX{}})
+	if _, err := q.ListCampuses(context.Background()); !errors.Is(err, errQuery) {
+		t.Fatalf("expected the query error, got %v", err)
+	}
+	if err := q.DeleteAPITokensByUser(context.Background(), 1); !errors.Is(err, errQuery) {
+		t.Fatalf("expected the query error, got %v", err)
+	}
+
+	if err := provider.ForceFlush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	for i, name := range []string{"db.ListCampuses", "db.DeleteAPITokensByUser"} {
+		if spans[i].Name != name {
+			t.Errorf("span %d: expected %s, got %s", i, name, spans[i].Name)
+		}
+		if spans[i].Status.Code != codes.Error {
+			t.Errorf("span %d: expected error status, got %v", i, spans[i].Status.Code)
+		}
+	}
+}
+
+func TestQueryName(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"-- name: GetUser :one\nSELECT * FROM users WHERE id = $1", "GetUser"},
+		{"-- name: DeleteUser :execrows\nDELETE FROM users", "DeleteUser"},
+		{"SELECT 1", "query"},
+		{"-- name: ", "query"},
+	}
+
+	for _, tt := range tests {
+		if got := queryName(tt.query); got != tt.want {
+			t.Errorf("queryName(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
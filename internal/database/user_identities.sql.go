@@ -0,0 +1,83 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.30.0
+// source: user_identities.sql
+
+package database
+
+import (
+	"context"
+)
+
+const createUserIdentity = `-- name: CreateUserIdentity :one
+INSERT INTO user_identities (user_id, provider, subject)
+VALUES (
+	$1, $2, $3
+)
+RETURNING id, user_id, provider, subject, created_at, last_login_at
+`
+
+type CreateUserIdentityParams struct {
+	UserID   int64
+	Provider string
+	Subject  string
+}
+
+func (q *Queries) CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, createUserIdentity, arg.UserID, arg.Provider, arg.Subject)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.Subject,
+		&i.CreatedAt,
+		&i.LastLoginAt,
+	)
+	return i, err
+}
+
+const deleteUserIdentities = `-- name: DeleteUserIdentities :exec
+DELETE FROM user_identities
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteUserIdentities(ctx context.Context, userID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteUserIdentities, userID)
+	return err
+}
+
+const getUserIdentity = `-- name: GetUserIdentity :one
+SELECT id, user_id, provider, subject, created_at, last_login_at FROM user_identities
+WHERE provider = $1 AND subject = $2
+`
+
+type GetUserIdentityParams struct {
+	Provider string
+	Subject  string
+}
+
+func (q *Queries) GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error) {
+	row := q.db.QueryRowContext(ctx, getUserIdentity, arg.Provider, arg.Subject)
+	var i UserIdentity
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Provider,
+		&i.Subject,
+		&i.CreatedAt,
+		&i.LastLoginAt,
+	)
+	return i, err
+}
+
+const touchUserIdentity = `-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET last_login_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) TouchUserIdentity(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, touchUserIdentity, id)
+	return err
+}
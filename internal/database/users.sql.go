@@ -7,36 +7,131 @@ package database
 
 import (
 	"context"
+	"database/sql"
 )
 
+const anonymizeUser = `-- name: AnonymizeUser :one
+UPDATE users
+SET email = 'deleted-' || id || '@anonymized.invalid',
+    name = 'Deleted user',
+    login = NULL,
+    avatar_url = NULL,
+    intra_campus_id = NULL,
+    suspended_at = COALESCE(suspended_at, now()),
+    anonymized_at = now(),
+    updated_at = now()
+WHERE id = $1
+RETURNING id, email, name, role, login, avatar_url, intra_campus_id, updated_at, suspended_at, suspended_reason, tokens_valid_after, anonymized_at, campus_id
+`
+
+func (q *Queries) AnonymizeUser(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, anonymizeUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.Role,
+		&i.Login,
+		&i.AvatarUrl,
+		&i.IntraCampusID,
+		&i.UpdatedAt,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+		&i.TokensValidAfter,
+		&i.AnonymizedAt,
+		&i.CampusID,
+	)
+	return i, err
+}
+
+const countUsers = `-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE ($1::TEXT IS NULL
+       OR name ILIKE '%' || $1 || '%'
+       OR email ILIKE '%' || $1 || '%'
+       OR login ILIKE '%' || $1 || '%')
+  AND ($2::TEXT IS NULL OR role = $2)
+  AND ($3::BOOLEAN IS NULL OR (suspended_at IS NOT NULL) = $3)
+  AND ($4::BIGINT IS NULL OR campus_id = $4)
+  AND anonymized_at IS NULL
+`
+
+type CountUsersParams struct {
+	Search    sql.NullString
+	Role      sql.NullString
+	Suspended sql.NullBool
+	CampusID  sql.NullInt64
+}
+
+func (q *Queries) CountUsers(ctx context.Context, arg CountUsersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUsers,
+		arg.Search,
+		arg.Role,
+		arg.Suspended,
+		arg.CampusID,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (email, name, role)
+INSERT INTO users (email, name, role, campus_id)
 VALUES (
-	$1, $2, $3
+	$1, $2, $3, $4
 )
-RETURNING id, email, name, role
+RETURNING id, email, name, role, login, avatar_url, intra_campus_id, updated_at, suspended_at, suspended_reason, tokens_valid_after, anonymized_at, campus_id
 `
 
 type CreateUserParams struct {
-	Email string
-	Name  string
-	Role  string
+	Email    string
+	Name     string
+	Role     string
+	CampusID int64
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, createUser, arg.Email, arg.Name, arg.Role)
+	row := q.db.QueryRowContext(ctx, createUser,
+		arg.Email,
+		arg.Name,
+		arg.Role,
+		arg.CampusID,
+	)
 	var i User
 	err := row.Scan(
 		&i.ID,
 		&i.Email,
 		&i.Name,
 		&i.Role,
+		&i.Login,
+		&i.AvatarUrl,
+		&i.IntraCampusID,
+		&i.UpdatedAt,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+		&i.TokensValidAfter,
+		&i.AnonymizedAt,
+		&i.CampusID,
 	)
 	return i, err
 }
 
+const deleteUser = `-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getUser = `-- name: GetUser :one
-SELECT id, email, name, role FROM users
+SELECT id, email, name, role, login, avatar_url, intra_campus_id, updated_at, suspended_at, suspended_reason, tokens_valid_after, anonymized_at, campus_id FROM users
 WHERE id = $1
 `
 
@@ -48,12 +143,21 @@ func (q *Queries) GetUser(ctx context.Context, id int64) (User, error) {
 		&i.Email,
 		&i.Name,
 		&i.Role,
+		&i.Login,
+		&i.AvatarUrl,
+		&i.IntraCampusID,
+		&i.UpdatedAt,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+		&i.TokensValidAfter,
+		&i.AnonymizedAt,
+		&i.CampusID,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, email, name, role FROM users
+SELECT id, email, name, role, login, avatar_url, intra_campus_id, updated_at, suspended_at, suspended_reason, tokens_valid_after, anonymized_at, campus_id FROM users
 WHERE email = $1
 `
 
@@ -65,6 +169,207 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.Name,
 		&i.Role,
+		&i.Login,
+		&i.AvatarUrl,
+		&i.IntraCampusID,
+		&i.UpdatedAt,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+		&i.TokensValidAfter,
+		&i.AnonymizedAt,
+		&i.CampusID,
+	)
+	return i, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT id, email, name, role, login, avatar_url, intra_campus_id, updated_at, suspended_at, suspended_reason, tokens_valid_after, anonymized_at, campus_id FROM users
+WHERE ($1::TEXT IS NULL
+       OR name ILIKE '%' || $1 || '%'
+       OR email ILIKE '%' || $1 || '%'
+       OR login ILIKE '%' || $1 || '%')
+  AND ($2::TEXT IS NULL OR role = $2)
+  AND ($3::BOOLEAN IS NULL OR (suspended_at IS NOT NULL) = $3)
+  AND ($4::BIGINT IS NULL OR campus_id = $4)
+  AND anonymized_at IS NULL
+ORDER BY name, id
+LIMIT $6 OFFSET $5
+`
+
+type ListUsersParams struct {
+	Search    sql.NullString
+	Role      sql.NullString
+	Suspended sql.NullBool
+	CampusID  sql.NullInt64
+	SkipRows  int32
+	MaxRows   int32
+}
+
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, listUsers,
+		arg.Search,
+		arg.Role,
+		arg.Suspended,
+		arg.CampusID,
+		arg.SkipRows,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Name,
+			&i.Role,
+			&i.Login,
+			&i.AvatarUrl,
+			&i.IntraCampusID,
+			&i.UpdatedAt,
+			&i.SuspendedAt,
+			&i.SuspendedReason,
+			&i.TokensValidAfter,
+			&i.AnonymizedAt,
+			&i.CampusID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const revokeUserTokens = `-- name: RevokeUserTokens :exec
+UPDATE users
+SET tokens_valid_after = now()
+WHERE id = $1
+`
+
+func (q *Queries) RevokeUserTokens(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, revokeUserTokens, id)
+	return err
+}
+
+const suspendUser = `-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = now(), suspended_reason = $2, updated_at = now()
+WHERE id = $1
+RETURNING id, email, name, role, login, avatar_url, intra_campus_id, updated_at, suspended_at, suspended_reason, tokens_valid_after, anonymized_at, campus_id
+`
+
+type SuspendUserParams struct {
+	ID              int64
+	SuspendedReason string
+}
+
+func (q *Queries) SuspendUser(ctx context.Context, arg SuspendUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, suspendUser, arg.ID, arg.SuspendedReason)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.Role,
+		&i.Login,
+		&i.AvatarUrl,
+		&i.IntraCampusID,
+		&i.UpdatedAt,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+		&i.TokensValidAfter,
+		&i.AnonymizedAt,
+		&i.CampusID,
+	)
+	return i, err
+}
+
+const unsuspendUser = `-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL, suspended_reason = '', updated_at = now()
+WHERE id = $1
+RETURNING id, email, name, role, login, avatar_url, intra_campus_id, updated_at, suspended_at, suspended_reason, tokens_valid_after, anonymized_at, campus_id
+`
+
+func (q *Queries) UnsuspendUser(ctx context.Context, id int64) (User, error) {
+	row := q.db.QueryRowContext(ctx, unsuspendUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.Role,
+		&i.Login,
+		&i.AvatarUrl,
+		&i.IntraCampusID,
+		&i.UpdatedAt,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+		&i.TokensValidAfter,
+		&i.AnonymizedAt,
+		&i.CampusID,
+	)
+	return i, err
+}
+
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE users
+SET name = $1,
+    login = $2,
+    avatar_url = $3,
+    intra_campus_id = $4,
+    role = $5,
+    campus_id = COALESCE($6, campus_id),
+    updated_at = now()
+WHERE id = $7
+RETURNING id, email, name, role, login, avatar_url, intra_campus_id, updated_at, suspended_at, suspended_reason, tokens_valid_after, anonymized_at, campus_id
+`
+
+type UpdateUserProfileParams struct {
+	Name          string
+	Login         sql.NullString
+	AvatarUrl     sql.NullString
+	IntraCampusID sql.NullInt32
+	Role          string
+	CampusID      sql.NullInt64
+	ID            int64
+}
+
+// A NULL campus_id keeps the user's campus.
+func (q *Queries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.Name,
+		arg.Login,
+		arg.AvatarUrl,
+		arg.IntraCampusID,
+		arg.Role,
+		arg.CampusID,
+		arg.ID,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Name,
+		&i.Role,
+		&i.Login,
+		&i.AvatarUrl,
+		&i.IntraCampusID,
+		&i.UpdatedAt,
+		&i.SuspendedAt,
+		&i.SuspendedReason,
+		&i.TokensValidAfter,
+		&i.AnonymizedAt,
+		&i.CampusID,
 	)
 	return i, err
 }
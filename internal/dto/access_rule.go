@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// AccessRuleRequest is used to allow or deny a 42 login
+type AccessRuleRequest struct {
+	Effect string `json:"effect" validate:"required,oneof=ALLOW DENY"`
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// AccessRuleDto is the returned dto for a login access rule.
+type AccessRuleDto struct {
+	Login     string    `json:"login"`
+	Effect    string    `json:"effect"`
+	Reason    string    `json:"reason"`
+	CreatedBy *int64    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// CreateAPITokenRequest is used to create a personal access token
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,oneof=reservations:read reservations:create reservations:cancel"`
+	ExpiresInDays int      `json:"expiresInDays" validate:"required,gte=1,lte=365"`
+}
+
+// APITokenDto is the returned dto for a personal access token.
+type APITokenDto struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreatedAPITokenDto is returned once on creation and carries the plain token.
+type CreatedAPITokenDto struct {
+	APITokenDto
+	Token string `json:"token"`
+}
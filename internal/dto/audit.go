@@ -0,0 +1,23 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditEntryDto is the returned dto for an audit log entry.
+type AuditEntryDto struct {
+	ID         int64           `json:"id"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Action     string          `json:"action"`
+	ActorID    *int64          `json:"actorId,omitempty"`
+	UserID     *int64          `json:"userId,omitempty"`
+	RoomID     *int64          `json:"roomId,omitempty"`
+	CampusID   *int64          `json:"campusId,omitempty"`
+	TargetType string          `json:"targetType"`
+	TargetID   string          `json:"targetId"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	IP         string          `json:"ip"`
+	RequestID  string          `json:"requestId"`
+}
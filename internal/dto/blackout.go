@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+// CreateBlackoutRequest is used to black out a room, once or on a recurring basis
+type CreateBlackoutRequest struct {
+	Reason      string     `json:"reason" validate:"required,max=255"`
+	StartTime   time.Time  `json:"startTime" validate:"required"`
+	EndTime     time.Time  `json:"endTime" validate:"required,gtfield=StartTime"`
+	Recurrence  string     `json:"recurrence" validate:"omitempty,oneof=NONE DAILY WEEKLY"`
+	RepeatUntil *time.Time `json:"repeatUntil"`
+}
+
+// BlackoutDto is the returned dto for a room blackout.
+type BlackoutDto struct {
+	ID          int64      `json:"id"`
+	RoomID      int64      `json:"roomId"`
+	Reason      string     `json:"reason"`
+	StartTime   time.Time  `json:"startTime"`
+	EndTime     time.Time  `json:"endTime"`
+	Recurrence  string     `json:"recurrence"`
+	RepeatUntil *time.Time `json:"repeatUntil,omitempty"`
+	CreatedBy   *int64     `json:"createdBy,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// CreatedBlackoutDto is returned on creation together with the reservations
+// that were cancelled because they fell inside the blackout.
+type CreatedBlackoutDto struct {
+	BlackoutDto
+	CancelledReservations []int64 `json:"cancelledReservations"`
+}
+
+// BlackoutSlotDto is a single occurrence of a blackout in a reservation listing.
+type BlackoutSlotDto struct {
+	ID        int64     `json:"id"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Reason    string    `json:"reason"`
+}
@@ -0,0 +1,54 @@
+package dto
+
+import "time"
+
+// CampusRequest is used to update the settings of a campus.
+// Open and Close are the regular "HH:MM" local opening hours.
+type CampusRequest struct {
+	Name           string   `json:"name" validate:"required,max=100"`
+	IntraCampusID  int      `json:"intraCampusId" validate:"gte=0"`
+	Timezone       string   `json:"timezone" validate:"required,max=64"`
+	Open           string   `json:"open" validate:"required"`
+	Close          string   `json:"close" validate:"required"`
+	LoginProviders []string `json:"loginProviders" validate:"dive,oneof=42 keycloak"`
+	CalendarID     string   `json:"calendarId" validate:"max=255"`
+	CORSOrigins    []string `json:"corsOrigins" validate:"dive,max=255"`
+}
+
+// CreateCampusRequest is used to add a campus.
+type CreateCampusRequest struct {
+	Slug string `json:"slug" validate:"required,max=50,lowercase"`
+	CampusRequest
+}
+
+// CampusDto is the public view of a campus.
+type CampusDto struct {
+	ID             int64    `json:"id"`
+	Slug           string   `json:"slug"`
+	Name           string   `json:"name"`
+	Timezone       string   `json:"timezone"`
+	LoginProviders []string `json:"loginProviders"`
+}
+
+// CampusSettingsDto is the full campus settings shown to campus admins.
+type CampusSettingsDto struct {
+	CampusDto
+	IntraCampusID *int      `json:"intraCampusId,omitempty"`
+	Open          string    `json:"open"`
+	Close         string    `json:"close"`
+	CalendarID    string    `json:"calendarId"`
+	CORSOrigins   []string  `json:"corsOrigins"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// CreateRoomRequest is used to add a room to a campus.
+type CreateRoomRequest struct {
+	Name string `json:"name" validate:"required,max=30"`
+}
+
+// RoomDto is the returned dto for a room.
+type RoomDto struct {
+	ID       int64  `json:"id"`
+	CampusID int64  `json:"campusId"`
+	Name     string `json:"name"`
+}
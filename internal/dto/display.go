@@ -0,0 +1,40 @@
+package dto
+
+import "time"
+
+// CreateDisplayDeviceRequest is used to register a room display device
+type CreateDisplayDeviceRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// DisplayDeviceDto is the returned dto for a room display device.
+type DisplayDeviceDto struct {
+	ID         int64      `json:"id"`
+	RoomID     int64      `json:"roomId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// CreatedDisplayDeviceDto is returned once on registration and carries the device token.
+type CreatedDisplayDeviceDto struct {
+	DisplayDeviceDto
+	Token string `json:"token"`
+}
+
+// DisplaySlotDto is a booked slot shown on a room display.
+type DisplaySlotDto struct {
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+}
+
+// RoomDisplayDto is the compact room status shown on a room display.
+type RoomDisplayDto struct {
+	RoomID   int64           `json:"roomId"`
+	RoomName string          `json:"roomName"`
+	Free     bool            `json:"free"`
+	Current  *DisplaySlotDto `json:"current"`
+	Next     *DisplaySlotDto `json:"next"`
+	Now      time.Time       `json:"now"`
+}
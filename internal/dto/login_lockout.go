@@ -0,0 +1,15 @@
+package dto
+
+import "time"
+
+// LoginLockoutDto is the returned dto for an IP or account locked out
+// after failed logins.
+type LoginLockoutDto struct {
+	ID            int64     `json:"id"`
+	Kind          string    `json:"kind"`
+	Subject       string    `json:"subject"`
+	Lockouts      int32     `json:"lockouts"`
+	LastReason    string    `json:"lastReason"`
+	LastFailureAt time.Time `json:"lastFailureAt"`
+	LockedUntil   time.Time `json:"lockedUntil"`
+}
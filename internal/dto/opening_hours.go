@@ -0,0 +1,38 @@
+package dto
+
+import "time"
+
+// OpeningHoursRequest is used to close the campus or set special hours on a date.
+// Open and Close are "HH:MM" local times, Close may be "24:00".
+type OpeningHoursRequest struct {
+	Closed bool   `json:"closed"`
+	Open   string `json:"open"`
+	Close  string `json:"close"`
+	Name   string `json:"name" validate:"max=255"`
+}
+
+// OpeningHoursExceptionDto is an exception to the regular opening hours.
+type OpeningHoursExceptionDto struct {
+	Date      string    `json:"date"`
+	Closed    bool      `json:"closed"`
+	Open      string    `json:"open,omitempty"`
+	Close     string    `json:"close,omitempty"`
+	Name      string    `json:"name"`
+	SourceUID string    `json:"sourceUid,omitempty"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// OpeningDayDto is the effective opening hours of one date.
+type OpeningDayDto struct {
+	Date      string `json:"date"`
+	Closed    bool   `json:"closed"`
+	Open      string `json:"open,omitempty"`
+	Close     string `json:"close,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Exception bool   `json:"exception"`
+}
+
+// OpeningHoursImportDto is returned after importing an iCalendar file.
+type OpeningHoursImportDto struct {
+	Imported int `json:"imported"`
+}
@@ -4,10 +4,12 @@ package dto
 import "time"
 
 // ReservedDto represents a room with its reservation slots
+// and the periods it is blacked out
 type ReservedDto struct {
-	RoomID   int64             `json:"roomId"`
-	RoomName string            `json:"roomName"`
-	Slots    []ReservedSlotDto `json:"slots"`
+	RoomID    int64             `json:"roomId"`
+	RoomName  string            `json:"roomName"`
+	Slots     []ReservedSlotDto `json:"slots"`
+	Blackouts []BlackoutSlotDto `json:"blackouts,omitempty"`
 }
 
 // ReservedSlotDto represents a single reservation time slot.
@@ -33,9 +35,10 @@ type UserDto struct {
 	Name string `json:"name"`
 }
 
-// CreateReservationRequest is used to create reservation
+// CreateReservationRequest is used to create reservation.
+// Times are RFC 3339 with any offset, e.g. 2026-02-23T08:00:00+02:00
 type CreateReservationRequest struct {
 	RoomID    int64     `json:"roomId" validate:"required,gt=0"`
-	StartTime time.Time `json:"startTime" validate:"required,utc,futureTime,schoolHours"`
-	EndTime   time.Time `json:"endTime" validate:"required,utc,gtfield=StartTime,schoolHours"`
+	StartTime time.Time `json:"startTime" validate:"required,futureTime,schoolHours"`
+	EndTime   time.Time `json:"endTime" validate:"required,gtfield=StartTime,schoolHours"`
 }
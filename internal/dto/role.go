@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// AssignRoleRequest is used to grant a role, optionally scoped to a room
+type AssignRoleRequest struct {
+	Role   string `json:"role" validate:"required"`
+	RoomID int64  `json:"roomId" validate:"gte=0"`
+}
+
+// RoleAssignmentDto represents a role granted to a user.
+type RoleAssignmentDto struct {
+	ID        int64     `json:"id"`
+	Role      string    `json:"role"`
+	RoomID    *int64    `json:"roomId,omitempty"`
+	GrantedBy *int64    `json:"grantedBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// UserRolesDto is the returned dto listing a user's roles.
+type UserRolesDto struct {
+	UserID      int64               `json:"userId"`
+	BaseRole    string              `json:"baseRole"`
+	Assignments []RoleAssignmentDto `json:"assignments"`
+}
@@ -0,0 +1,33 @@
+package dto
+
+import "time"
+
+// AdminUserDto is the returned dto for a user in the staff admin API.
+type AdminUserDto struct {
+	ID              int64      `json:"id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	Login           *string    `json:"login,omitempty"`
+	Role            string     `json:"role"`
+	CampusID        int64      `json:"campusId"`
+	AvatarURL       *string    `json:"avatarUrl,omitempty"`
+	Suspended       bool       `json:"suspended"`
+	SuspendedAt     *time.Time `json:"suspendedAt,omitempty"`
+	SuspendedReason string     `json:"suspendedReason,omitempty"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// SuspendUserRequest is used to suspend a user
+type SuspendUserRequest struct {
+	Reason string `json:"reason" validate:"max=500"`
+}
+
+// UserReservationDto is a booking in a user's booking history.
+type UserReservationDto struct {
+	ID        int64     `json:"id"`
+	RoomID    int64     `json:"roomId"`
+	RoomName  string    `json:"roomName"`
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Status    string    `json:"status"`
+}
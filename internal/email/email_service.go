@@ -5,24 +5,42 @@ import (
 	"bytes"
 	"context"
 	"embed"
+	"errors"
 	"fmt"
 	"html/template"
 	"log/slog"
+	"net"
+	"net/textproto"
+	"sync"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/integration"
+	"github.com/IbnBaqqi/book-me/internal/metrics"
+	"github.com/IbnBaqqi/book-me/internal/tracing"
 	"github.com/avast/retry-go/v5"
 	"github.com/wneessen/go-mail"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 //go:embed templates/*.html
 var templateFS embed.FS
 
+// Sender is implemented by Service and NullSender.
+type Sender interface {
+	SendConfirmation(ctx context.Context, toEmail, room, startTime, endTime string) error
+	SendCancellation(ctx context.Context, toEmail, room, startTime, endTime, reason string) error
+}
+
 // Service handles email operations
 type Service struct {
-	client    *mail.Client
 	from      string
 	fromName  string
 	templates *template.Template
+	retryWait time.Duration // delay before the first retry of a failed send
+
+	mu     sync.RWMutex
+	cfg    Config
+	client *mail.Client
 }
 
 // Config holds email service configuration
@@ -36,26 +54,20 @@ type Config struct {
 	UseTLS       bool
 }
 
-// BookingData holds data for booking confirmation email
+// BookingData holds data for booking confirmation and cancellation emails
 type BookingData struct {
 	RoomName  string
 	StartTime string
 	EndTime   string
+	Reason    string
 }
 
 // NewService creates a new email service
 func NewService(cfg Config) (*Service, error) {
 
-	client, err := mail.NewClient(
-		cfg.SMTPHost,
-		mail.WithPort(cfg.SMTPPort),
-		mail.WithSMTPAuth(mail.SMTPAuthPlain),
-		mail.WithUsername(cfg.SMTPUsername),
-		mail.WithPassword(cfg.SMTPPassword),
-		mail.WithTLSPolicy(mail.TLSMandatory),
-	)
+	client, err := newClient(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create mail client: %w", err)
+		return nil, err
 	}
 
 	// Parse all templates
@@ -65,15 +77,84 @@ func NewService(cfg Config) (*Service, error) {
 	}
 
 	return &Service{
-		client:    client,
 		from:      cfg.FromEmail,
 		fromName:  cfg.FromName,
 		templates: tmpl,
+		retryWait: 4 * time.Second,
+		cfg:       cfg,
+		client:    client,
 	}, nil
 }
 
+// SetPassword rotates the SMTP password. Emails being sent finish with the
+// previous one.
+func (s *Service) SetPassword(password string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cfg := s.cfg
+	cfg.SMTPPassword = password
+	client, err := newClient(cfg)
+	if err != nil {
+		return err
+	}
+	s.cfg = cfg
+	s.client = client
+	return nil
+}
+
+// newClient creates an SMTP client authenticating with the configured
+// credentials.
+func newClient(cfg Config) (*mail.Client, error) {
+	client, err := mail.NewClient(
+		cfg.SMTPHost,
+		mail.WithPort(cfg.SMTPPort),
+		mail.WithSMTPAuth(mail.SMTPAuthPlain),
+		mail.WithUsername(cfg.SMTPUsername),
+		mail.WithPassword(cfg.SMTPPassword),
+		mail.WithTLSPolicy(mail.TLSMandatory),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mail client: %w", err)
+	}
+	return client, nil
+}
+
 // SendConfirmation sends a confirmation email for reservation
 func (s *Service) SendConfirmation(ctx context.Context, toEmail, room, startTime, endTime string) error {
+	data := BookingData{
+		RoomName:  room,
+		StartTime: startTime,
+		EndTime:   endTime,
+	}
+
+	// plain text fallback
+	// plainText := fmt.Sprintf(
+	// 	"Hi, the %s meeting room has been reserved for you from %s to %s.",
+	// 	room, startTime, endTime,
+	// )
+	// msg.AddAlternativeString(mail.TypeTextPlain, plainText)
+
+	return s.send(ctx, toEmail, "Hive / Meeting Room Confirmation", "confirmation_email_v2.html", data)
+}
+
+// SendCancellation tells the owner that their reservation was cancelled
+// because the room became unavailable.
+func (s *Service) SendCancellation(ctx context.Context, toEmail, room, startTime, endTime, reason string) error {
+	data := BookingData{
+		RoomName:  room,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Reason:    reason,
+	}
+
+	return s.send(ctx, toEmail, "Hive / Meeting Room Cancelled", "cancellation_email.html", data)
+}
+
+// send renders the template and sends it as an HTML email
+func (s *Service) send(ctx context.Context, toEmail, subject, templateName string, data BookingData) (err error) {
+	ctx, span := tracing.Start(ctx, "email.Send", attribute.String("email.template", templateName))
+	defer func() { tracing.End(span, err) }()
 
 	msg := mail.NewMsg()
 
@@ -85,40 +166,58 @@ func (s *Service) SendConfirmation(ctx context.Context, toEmail, room, startTime
 		return fmt.Errorf("failed to set recipient: %w", err)
 	}
 
-	msg.Subject("Hive / Meeting Room Confirmation")
-
-	data := BookingData{
-		RoomName:  room,
-		StartTime: startTime,
-		EndTime:   endTime,
-	}
+	msg.Subject(subject)
 
 	var htmlBody bytes.Buffer
-	if err := s.templates.ExecuteTemplate(&htmlBody, "confirmation_email_v2.html", data); err != nil {
+	if err := s.templates.ExecuteTemplate(&htmlBody, templateName, data); err != nil {
 		return fmt.Errorf("failed to render email template: %w", err)
 	}
 
 	msg.SetBodyString(mail.TypeTextHTML, htmlBody.String())
 
-	// plain text fallback
-	// plainText := fmt.Sprintf(
-	// 	"Hi, the %s meeting room has been reserved for you from %s to %s.",
-	// 	room, startTime, endTime,
-	// )
-	// msg.AddAlternativeString(mail.TypeTextPlain, plainText)
+	s.mu.RLock()
+	client := s.client
+	s.mu.RUnlock()
 
-	// Send email with context and backoff retries
-	return retry.New(
+	// Send email with context and backoff retries, one span per attempt
+	err = retry.New(
 		retry.Attempts(3),
-		retry.Delay(4*time.Second),
+		retry.Delay(s.retryWait),
 		retry.MaxDelay(10*time.Second),
 		retry.Context(ctx),
 		retry.OnRetry(func(n uint, err error) {
 			slog.Warn("retrying email send", "attempt", n+1, "error", err)
+			metrics.JobRetries.WithLabelValues(metrics.JobEmail).Inc()
 		}),
 	).Do(func() error {
-		return s.client.DialAndSendWithContext(ctx, msg)
+		attemptCtx, attempt := tracing.Start(ctx, "smtp.DialAndSend")
+		err := client.DialAndSendWithContext(attemptCtx, msg)
+		tracing.End(attempt, err)
+		return err
 	})
+	metrics.JobDone(metrics.JobEmail, err)
+	if err != nil && transient(err) {
+		return fmt.Errorf("failed to send email: %w: %w", integration.ErrUnavailable, err)
+	}
+	return err
+}
+
+// transient reports whether err is a network error or a temporary (4xx)
+// SMTP failure. Jobs failing with it are queued and retried later.
+func transient(err error) bool {
+	var sendErr *mail.SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.IsTemp()
+	}
+
+	// Replies rejecting the connection, like 421 on greeting
+	var replyErr *textproto.Error
+	if errors.As(err, &replyErr) {
+		return replyErr.Code >= 400 && replyErr.Code < 500
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
 
 // Close closes the email client connection
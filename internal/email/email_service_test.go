@@ -3,12 +3,15 @@ package email
 import (
 	"bytes"
 	"context"
+	"errors"
 	"html/template"
+	"net"
 	"os"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/integration"
 	"github.com/joho/godotenv"
 )
 
@@ -121,3 +124,71 @@ func TestTemplateRendering(t *testing.T) {
 		t.Log("Template rendered successfully! Open email/test_output.html in your browser.")
 	}
 }
+
+// serveSMTP accepts connections on a local port and greets each with reply.
+// It returns the port.
+func serveSMTP(t *testing.T, reply string) int {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte(reply + "\r\n"))
+			_ = conn.Close()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestSendErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	closed := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	tests := []struct {
+		name        string
+		port        int
+		unavailable bool
+	}{
+		{name: "server busy", port: serveSMTP(t, "421 Service not available"), unavailable: true},
+		{name: "connection refused", port: closed, unavailable: true},
+		{name: "rejected", port: serveSMTP(t, "554 No SMTP service here")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, err := NewService(Config{
+				SMTPHost:     "127.0.0.1",
+				SMTPPort:     tt.port,
+				SMTPUsername: "user",
+				SMTPPassword: "password",
+				FromEmail:    "bookme@example.com",
+				FromName:     "BookMe Test",
+			})
+			if err != nil {
+				t.Fatalf("failed to create email service: %v", err)
+			}
+			svc.retryWait = time.Millisecond
+
+			err = svc.SendConfirmation(context.Background(), "student@example.com", "Room", "start", "end")
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if got := errors.Is(err, integration.ErrUnavailable); got != tt.unavailable {
+				t.Errorf("errors.Is(err, ErrUnavailable) = %v, want %v (err: %v)", got, tt.unavailable, err)
+			}
+		})
+	}
+}
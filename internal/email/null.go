@@ -0,0 +1,22 @@
+package email
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IbnBaqqi/book-me/internal/integration"
+)
+
+// NullSender stands in for the email service while it is disabled or failed
+// to start. Every email fails with integration.ErrUnavailable.
+type NullSender struct{}
+
+// SendConfirmation fails with integration.ErrUnavailable.
+func (NullSender) SendConfirmation(context.Context, string, string, string, string) error {
+	return fmt.Errorf("email: %w", integration.ErrUnavailable)
+}
+
+// SendCancellation fails with integration.ErrUnavailable.
+func (NullSender) SendCancellation(context.Context, string, string, string, string, string) error {
+	return fmt.Errorf("email: %w", integration.ErrUnavailable)
+}
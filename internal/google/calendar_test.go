@@ -2,11 +2,18 @@ package google
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/integration"
 	"github.com/joho/godotenv"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
 )
 
 const testCalendarScope = "https://www.googleapis.com/auth/calendar"
@@ -109,7 +116,7 @@ func TestCreateAndDeleteEvent_Integration(t *testing.T) {
 	defer func() {
 		delCtx, delCancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer delCancel()
-		if err := svc.DeleteGoogleEvent(delCtx, eventID); err != nil {
+		if err := svc.DeleteGoogleEvent(delCtx, "", eventID); err != nil {
 			t.Errorf("failed to delete event: %v", err)
 		}
 	}()
@@ -121,8 +128,73 @@ func TestDeleteGoogleEvent_InvalidID_Integration(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	err := svc.DeleteGoogleEvent(ctx, "nonexistent-event-id")
+	err := svc.DeleteGoogleEvent(ctx, "", "nonexistent-event-id")
 	if err == nil {
 		t.Error("expected error when deleting nonexistent event")
 	}
 }
+
+// newTestCalendar returns a CalendarService calling the API at url.
+func newTestCalendar(t *testing.T, url string) *CalendarService {
+	t.Helper()
+
+	service, err := calendar.NewService(context.Background(),
+		option.WithHTTPClient(http.DefaultClient),
+		option.WithEndpoint(url+"/"),
+	)
+	if err != nil {
+		t.Fatalf("failed to create calendar client: %v", err)
+	}
+	return &CalendarService{calendarID: "test-calendar", service: service}
+}
+
+func TestCreateGoogleEvent_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		queued bool
+	}{
+		{name: "server error is queued", status: http.StatusServiceUnavailable, queued: true},
+		{name: "rate limit is queued", status: http.StatusTooManyRequests, queued: true},
+		{name: "not found fails", status: http.StatusNotFound},
+		{name: "forbidden fails", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"error": {"code": ` + strconv.Itoa(tt.status) + `, "message": "test"}}`))
+			}))
+			defer server.Close()
+
+			calendars := integration.NewOptional[Calendar]("calendar", newTestCalendar(t, server.URL), integration.Available())
+			err := calendars.Do(context.Background(), "create event", func(ctx context.Context, c Calendar) error {
+				_, err := c.CreateGoogleEvent(ctx, &Reservation{
+					StartTime: time.Now(),
+					EndTime:   time.Now().Add(time.Hour),
+				})
+				return err
+			})
+
+			queued := calendars.State().Queued == 1
+			if queued != tt.queued {
+				t.Errorf("queued = %v, want %v (err: %v)", queued, tt.queued, err)
+			}
+			if !tt.queued && err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestDeleteGoogleEvent_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	err := newTestCalendar(t, server.URL).DeleteGoogleEvent(context.Background(), "", "event-id")
+	if !errors.Is(err, integration.ErrUnavailable) {
+		t.Errorf("expected ErrUnavailable for an unreachable API, got %v", err)
+	}
+}
@@ -4,14 +4,24 @@ package google
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
+	"sync"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/integration"
 	"github.com/IbnBaqqi/book-me/internal/logger"
+	"github.com/IbnBaqqi/book-me/internal/metrics"
+	"github.com/IbnBaqqi/book-me/internal/tracing"
 	"github.com/hashicorp/go-retryablehttp"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
@@ -21,16 +31,66 @@ type Reservation struct {
 	EndTime   time.Time
 	CreatedBy string
 	Room      string
+	// CalendarID is the calendar of the room's campus, empty for the default calendar
+	CalendarID string
+	// TimeZone is the IANA name of the campus timezone, empty for UTC
+	TimeZone string
+}
+
+// Calendar is implemented by CalendarService and NullCalendar.
+type Calendar interface {
+	CreateGoogleEvent(ctx context.Context, reservation *Reservation) (string, error)
+	DeleteGoogleEvent(ctx context.Context, calendarID, eventID string) error
+	HealthCheck(ctx context.Context) error
 }
 
 // CalendarService manages Google Calendar operations.
 type CalendarService struct {
-	service    *calendar.Service
 	calendarID string
+	scope      string
+
+	mu      sync.RWMutex
+	service *calendar.Service
 }
 
 // NewCalendarService creates a new calendar service
 func NewCalendarService(credentialsBase64, calendarScope, calendarID string) (*CalendarService, error) {
+	service, err := newCalendarClient(credentialsBase64, calendarScope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CalendarService{
+		calendarID: calendarID,
+		scope:      calendarScope,
+		service:    service,
+	}, nil
+}
+
+// SetCredentials rotates the service account credentials. Calls in flight
+// finish with the previous ones.
+func (s *CalendarService) SetCredentials(credentialsBase64 string) error {
+	service, err := newCalendarClient(credentialsBase64, s.scope)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.service = service
+	return nil
+}
+
+// client returns the calendar client with the current credentials.
+func (s *CalendarService) client() *calendar.Service {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.service
+}
+
+// newCalendarClient creates a calendar client authenticated as the service
+// account, retrying failed requests.
+func newCalendarClient(credentialsBase64, calendarScope string) (*calendar.Service, error) {
 
 	ctx := context.Background()
 
@@ -58,9 +118,18 @@ func NewCalendarService(credentialsBase64, calendarScope, calendarID string) (*C
 	retryClient.RetryWaitMin = 4 * time.Second
 	retryClient.RetryWaitMax = 10 * time.Second
 	retryClient.Logger = &logger.RetryLogger{}
+	// Hand the last response to the API client once retries are exhausted,
+	// so a 5xx is reported with its status code
+	retryClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	retryClient.RequestLogHook = func(_ retryablehttp.Logger, _ *http.Request, attempt int) {
+		if attempt > 0 {
+			metrics.JobRetries.WithLabelValues(metrics.JobCalendar).Inc()
+		}
+	}
 
-	// Wrap jwt httpclient with retry
+	// Wrap jwt httpclient with retry, tracing every attempt
 	jwtClient := config.Client(ctx)
+	jwtClient.Transport = tracing.Transport(jwtClient.Transport)
 	retryClient.HTTPClient = jwtClient
 	retryableClient := retryClient.StandardClient()
 
@@ -70,15 +139,21 @@ func NewCalendarService(credentialsBase64, calendarScope, calendarID string) (*C
 		return nil, fmt.Errorf("failed to create calendar service: %w", err)
 	}
 
-	return &CalendarService{
-		service:    service,
-		calendarID: calendarID,
-	}, nil
+	return service, nil
 }
 
 // CreateGoogleEvent creates a calendar event
-func (s *CalendarService) CreateGoogleEvent(ctx context.Context, reservation *Reservation) (string, error) {
-	location, err := time.LoadLocation("Europe/Helsinki")
+func (s *CalendarService) CreateGoogleEvent(ctx context.Context, reservation *Reservation) (eventID string, err error) {
+	ctx, span := tracing.Start(ctx, "calendar.CreateEvent",
+		attribute.String("calendar.id", s.calendarFor(reservation.CalendarID)),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	timeZone := reservation.TimeZone
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	location, err := time.LoadLocation(timeZone)
 	if err != nil {
 		return "", fmt.Errorf("failed to load location: %w", err)
 	}
@@ -91,19 +166,20 @@ func (s *CalendarService) CreateGoogleEvent(ctx context.Context, reservation *Re
 		Description: "Created via BookMe",
 		Start: &calendar.EventDateTime{
 			DateTime: start.Format(time.RFC3339),
-			TimeZone: "Europe/Helsinki",
+			TimeZone: timeZone,
 		},
 		End: &calendar.EventDateTime{
 			DateTime: end.Format(time.RFC3339),
-			TimeZone: "Europe/Helsinki",
+			TimeZone: timeZone,
 		},
 	}
 
 	// Create the event
-	createdEvent, err := s.service.Events.Insert(s.calendarID, event).Context(ctx).Do()
+	createdEvent, err := s.client().Events.Insert(s.calendarFor(reservation.CalendarID), event).Context(ctx).Do()
+	metrics.JobDone(metrics.JobCalendar, err)
 	if err != nil {
 		slog.Error("failed to create calendar event", "error", err)
-		return "", fmt.Errorf("failed to create event: %w", err)
+		return "", wrapErr("failed to create event", err)
 	}
 
 	if createdEvent.Id == "" {
@@ -116,19 +192,65 @@ func (s *CalendarService) CreateGoogleEvent(ctx context.Context, reservation *Re
 // HealthCheck verifies the calendar service is accessible.
 // Simple check to try to get calendar metadata
 func (s *CalendarService) HealthCheck(ctx context.Context) error {
-	_, err := s.service.Calendars.Get(s.calendarID).Context(ctx).Do()
+	_, err := s.client().Calendars.Get(s.calendarID).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("calendar API unreachable: %w", err)
 	}
 	return nil
 }
 
-// DeleteGoogleEvent deletes a calendar event
-func (s *CalendarService) DeleteGoogleEvent(ctx context.Context, eventID string) error {
-	err := s.service.Events.Delete(s.calendarID, eventID).Context(ctx).Do()
+// DeleteGoogleEvent deletes a calendar event. An empty calendarID uses the
+// default calendar.
+func (s *CalendarService) DeleteGoogleEvent(ctx context.Context, calendarID, eventID string) error {
+	ctx, span := tracing.Start(ctx, "calendar.DeleteEvent",
+		attribute.String("calendar.id", s.calendarFor(calendarID)),
+		attribute.String("calendar.event_id", eventID),
+	)
+	err := s.client().Events.Delete(s.calendarFor(calendarID), eventID).Context(ctx).Do()
+	metrics.JobDone(metrics.JobCalendar, err)
+	tracing.End(span, err)
 	if err != nil {
-		return fmt.Errorf("failed to delete event: %w", err)
+		return wrapErr("failed to delete event", err)
 	}
 
 	return nil
 }
+
+// calendarFor returns the calendar to use, the default one when id is empty.
+func (s *CalendarService) calendarFor(id string) string {
+	if id == "" {
+		return s.calendarID
+	}
+	return id
+}
+
+// wrapErr adds msg to err. Errors the calendar may recover from, like an
+// unreachable API or a failure on Google's side, also wrap
+// integration.ErrUnavailable so the job is queued and retried.
+func wrapErr(msg string, err error) error {
+	if transient(err) {
+		return fmt.Errorf("%s: %w: %w", msg, integration.ErrUnavailable, err)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// transient reports whether err is a network error, a 5xx or a rate limit.
+func transient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= http.StatusInternalServerError || apiErr.Code == http.StatusTooManyRequests
+	}
+
+	// Token requests fail with the response of the token endpoint
+	var tokenErr *oauth2.RetrieveError
+	if errors.As(err, &tokenErr) {
+		return tokenErr.Response == nil || tokenErr.Response.StatusCode >= http.StatusInternalServerError
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,27 @@
+package google
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/IbnBaqqi/book-me/internal/integration"
+)
+
+// NullCalendar stands in for the calendar while it is disabled or failed
+// to start. Every call fails with integration.ErrUnavailable.
+type NullCalendar struct{}
+
+// CreateGoogleEvent fails with integration.ErrUnavailable.
+func (NullCalendar) CreateGoogleEvent(context.Context, *Reservation) (string, error) {
+	return "", fmt.Errorf("google calendar: %w", integration.ErrUnavailable)
+}
+
+// DeleteGoogleEvent fails with integration.ErrUnavailable.
+func (NullCalendar) DeleteGoogleEvent(context.Context, string, string) error {
+	return fmt.Errorf("google calendar: %w", integration.ErrUnavailable)
+}
+
+// HealthCheck fails with integration.ErrUnavailable.
+func (NullCalendar) HealthCheck(context.Context) error {
+	return fmt.Errorf("google calendar: %w", integration.ErrUnavailable)
+}
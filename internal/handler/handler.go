@@ -2,22 +2,38 @@
 package handler
 
 import (
+	"github.com/IbnBaqqi/book-me/internal/audit"
 	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/campus"
 	"github.com/IbnBaqqi/book-me/internal/database"
 	"github.com/IbnBaqqi/book-me/internal/email"
 	"github.com/IbnBaqqi/book-me/internal/google"
+	"github.com/IbnBaqqi/book-me/internal/health"
+	"github.com/IbnBaqqi/book-me/internal/integration"
 	"github.com/IbnBaqqi/book-me/internal/oauth"
 	"github.com/IbnBaqqi/book-me/internal/service"
 )
 
 // Handler holds all dependencies for HTTP handlers
 type Handler struct {
-	db          *database.DB
-	oauth       *oauth.Service
-	auth        *auth.Service
-	email       *email.Service
-	calendar    *google.CalendarService
-	reservation *service.ReservationService
+	db           *database.DB
+	oauth        *oauth.Service
+	auth         *auth.Service
+	email        *integration.Optional[email.Sender]
+	calendar     *integration.Optional[google.Calendar]
+	reservation  *service.ReservationService
+	accessRules  *service.AccessRuleService
+	roles        *service.RoleService
+	apiTokens    *service.APITokenService
+	displays     *service.DisplayService
+	audit        *audit.Service
+	users        *service.UserService
+	blackouts    *service.BlackoutService
+	openingHours *service.OpeningHoursService
+	campusAdmin  *service.CampusService
+	campuses     *campus.Registry
+	loginGuard   *service.LoginGuardService
+	health       *health.Checker
 }
 
 // New creates a new Handler with all dependencies injected
@@ -25,16 +41,40 @@ func New(
 	db *database.DB,
 	oauthService *oauth.Service,
 	authService *auth.Service,
-	emailService *email.Service,
-	calendarService *google.CalendarService,
+	emailService *integration.Optional[email.Sender],
+	calendarService *integration.Optional[google.Calendar],
 	reservationService *service.ReservationService,
+	accessRuleService *service.AccessRuleService,
+	roleService *service.RoleService,
+	apiTokenService *service.APITokenService,
+	displayService *service.DisplayService,
+	auditService *audit.Service,
+	userService *service.UserService,
+	blackoutService *service.BlackoutService,
+	openingHoursService *service.OpeningHoursService,
+	campusService *service.CampusService,
+	campuses *campus.Registry,
+	loginGuardService *service.LoginGuardService,
+	healthChecker *health.Checker,
 ) *Handler {
 	return &Handler{
-		db:          db,
-		oauth:       oauthService,
-		auth:        authService,
-		email:       emailService,
-		calendar:    calendarService,
-		reservation: reservationService,
+		db:           db,
+		oauth:        oauthService,
+		auth:         authService,
+		email:        emailService,
+		calendar:     calendarService,
+		reservation:  reservationService,
+		accessRules:  accessRuleService,
+		roles:        roleService,
+		apiTokens:    apiTokenService,
+		displays:     displayService,
+		audit:        auditService,
+		users:        userService,
+		blackouts:    blackoutService,
+		openingHours: openingHoursService,
+		campusAdmin:  campusService,
+		campuses:     campuses,
+		loginGuard:   loginGuardService,
+		health:       healthChecker,
 	}
 }
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/service"
+	appvalidator "github.com/IbnBaqqi/book-me/internal/validator"
+)
+
+// ListAccessRules handler returns the login allow/deny list.
+//
+// GET /admin/access-rules
+func (h *Handler) ListAccessRules(w http.ResponseWriter, r *http.Request) {
+
+	rules, err := h.accessRules.ListRules(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := make([]dto.AccessRuleDto, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, toAccessRuleDto(rule))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// SetAccessRule handler allows or denies a 42 login.
+//
+// PUT /admin/access-rules/{login}
+func (h *Handler) SetAccessRule(w http.ResponseWriter, r *http.Request) {
+
+	login, err := parseLogin(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	req := dto.AccessRuleRequest{}
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := appvalidator.Validate(req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	rule, err := h.accessRules.SetRule(r.Context(), service.SetAccessRuleInput{
+		Login:     login,
+		Effect:    req.Effect,
+		Reason:    req.Reason,
+		CreatedBy: currentUser.ID,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toAccessRuleDto(rule))
+}
+
+// DeleteAccessRule handler removes a login from the allow/deny list.
+//
+// DELETE /admin/access-rules/{login}
+func (h *Handler) DeleteAccessRule(w http.ResponseWriter, r *http.Request) {
+
+	login, err := parseLogin(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.accessRules.DeleteRule(r.Context(), login, currentUser.ID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toAccessRuleDto(rule database.LoginAccessRule) dto.AccessRuleDto {
+	var createdBy *int64
+	if rule.CreatedBy.Valid {
+		createdBy = &rule.CreatedBy.Int64
+	}
+	return dto.AccessRuleDto{
+		Login:     rule.Login,
+		Effect:    rule.Effect,
+		Reason:    rule.Reason,
+		CreatedBy: createdBy,
+		CreatedAt: rule.CreatedAt.UTC(),
+	}
+}
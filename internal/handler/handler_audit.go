@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+)
+
+// ListAuditLog handler returns audit log entries filtered by user, room,
+// action and time range, newest first. With format=csv the entries are
+// exported as a CSV file.
+//
+// GET /admin/audit
+func (h *Handler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	loc, err := parseLocation(r, h.campuses.Resolve(currentUser.CampusID).Location)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	export := r.URL.Query().Get("format") == "csv"
+
+	filter, err := parseAuditFilter(r, loc, export)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	// Staff read the entries of their own campus
+	filter.CampusID = campusScope(currentUser)
+
+	entries, err := h.audit.List(r.Context(), filter)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if export {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="audit-log.csv"`)
+		w.WriteHeader(http.StatusOK)
+		if err := audit.WriteCSV(w, entries); err != nil {
+			slog.Error("failed to write audit csv", "error", err)
+		}
+		return
+	}
+
+	result := make([]dto.AuditEntryDto, 0, len(entries))
+	for _, e := range entries {
+		result = append(result, toAuditEntryDto(e))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+func toAuditEntryDto(e database.AuditLog) dto.AuditEntryDto {
+	result := dto.AuditEntryDto{
+		ID:         e.ID,
+		OccurredAt: e.OccurredAt.UTC(),
+		Action:     e.Action,
+		TargetType: e.TargetType,
+		TargetID:   e.TargetID,
+		Before:     e.Before,
+		After:      e.After,
+		IP:         e.Ip,
+		RequestID:  e.RequestID,
+	}
+	if e.ActorID.Valid {
+		result.ActorID = &e.ActorID.Int64
+	}
+	if e.UserID.Valid {
+		result.UserID = &e.UserID.Int64
+	}
+	if e.RoomID.Valid {
+		result.RoomID = &e.RoomID.Int64
+	}
+	if e.CampusID.Valid {
+		result.CampusID = &e.CampusID.Int64
+	}
+	return result
+}
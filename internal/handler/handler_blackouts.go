@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/service"
+	appvalidator "github.com/IbnBaqqi/book-me/internal/validator"
+)
+
+// CreateBlackout handler blacks out a room for maintenance or events.
+// Reservations inside the blackout are cancelled and their owners notified.
+//
+// POST /rooms/{id}/blackouts
+func (h *Handler) CreateBlackout(w http.ResponseWriter, r *http.Request) {
+
+	roomID, err := parsePathID(r, "id", "Room ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	req := dto.CreateBlackoutRequest{}
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := appvalidator.Validate(req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	input := service.CreateBlackoutInput{
+		RoomID:     roomID,
+		Reason:     req.Reason,
+		StartTime:  req.StartTime.UTC(),
+		EndTime:    req.EndTime.UTC(),
+		Recurrence: req.Recurrence,
+		CreatedBy:  currentUser.ID,
+		CampusID:   currentUser.CampusID,
+		Grants:     currentUser.Grants,
+	}
+	if req.RepeatUntil != nil {
+		input.RepeatUntil = req.RepeatUntil.UTC()
+	}
+
+	blackout, cancelled, err := h.blackouts.CreateBlackout(r.Context(), input)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	cancelledIDs := make([]int64, 0, len(cancelled))
+	for _, res := range cancelled {
+		cancelledIDs = append(cancelledIDs, res.ID)
+	}
+
+	respondWithJSON(w, http.StatusCreated, dto.CreatedBlackoutDto{
+		BlackoutDto:           toBlackoutDto(blackout),
+		CancelledReservations: cancelledIDs,
+	})
+}
+
+// ListBlackouts handler returns the blackouts of a room.
+//
+// GET /rooms/{id}/blackouts
+func (h *Handler) ListBlackouts(w http.ResponseWriter, r *http.Request) {
+
+	roomID, err := parsePathID(r, "id", "Room ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	blackouts, err := h.blackouts.ListBlackouts(r.Context(), roomID, currentUser.CampusID, currentUser.Grants)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := make([]dto.BlackoutDto, 0, len(blackouts))
+	for _, b := range blackouts {
+		result = append(result, toBlackoutDto(b))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// DeleteBlackout handler removes a blackout of a room.
+//
+// DELETE /rooms/{id}/blackouts/{blackoutId}
+func (h *Handler) DeleteBlackout(w http.ResponseWriter, r *http.Request) {
+
+	roomID, err := parsePathID(r, "id", "Room ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	blackoutID, err := parsePathID(r, "blackoutId", "Blackout ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.blackouts.DeleteBlackout(r.Context(), roomID, blackoutID, currentUser.ID, currentUser.CampusID, currentUser.Grants); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toBlackoutDto(b database.RoomBlackout) dto.BlackoutDto {
+	result := dto.BlackoutDto{
+		ID:         b.ID,
+		RoomID:     b.RoomID,
+		Reason:     b.Reason,
+		StartTime:  b.StartTime.UTC(),
+		EndTime:    b.EndTime.UTC(),
+		Recurrence: b.Recurrence,
+		CreatedAt:  b.CreatedAt.UTC(),
+	}
+	if b.RepeatUntil.Valid {
+		repeatUntil := b.RepeatUntil.Time.UTC()
+		result.RepeatUntil = &repeatUntil
+	}
+	if b.CreatedBy.Valid {
+		result.CreatedBy = &b.CreatedBy.Int64
+	}
+	return result
+}
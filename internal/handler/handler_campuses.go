@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/campus"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+	"github.com/IbnBaqqi/book-me/internal/schedule"
+	"github.com/IbnBaqqi/book-me/internal/service"
+	appvalidator "github.com/IbnBaqqi/book-me/internal/validator"
+)
+
+// ListCampuses handler returns all campuses.
+//
+// GET /campuses
+func (h *Handler) ListCampuses(w http.ResponseWriter, _ *http.Request) {
+
+	campuses := h.campuses.All()
+
+	result := make([]dto.CampusDto, 0, len(campuses))
+	for _, c := range campuses {
+		result = append(result, dto.CampusDto{
+			ID:             c.ID,
+			Slug:           c.Slug,
+			Name:           c.Name,
+			Timezone:       c.Location.String(),
+			LoginProviders: c.Providers,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// ListRooms handler returns the rooms of the user's campus.
+//
+// GET /rooms
+func (h *Handler) ListRooms(w http.ResponseWriter, r *http.Request) {
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	home, err := h.campusFor(r, currentUser)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	rooms, err := h.campusAdmin.ListRooms(r.Context(), home.ID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := make([]dto.RoomDto, 0, len(rooms))
+	for _, room := range rooms {
+		result = append(result, toRoomDto(room))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// ListCampusSettings handler returns the full settings of all campuses.
+//
+// GET /admin/campuses
+func (h *Handler) ListCampusSettings(w http.ResponseWriter, r *http.Request) {
+
+	rows, err := h.campusAdmin.ListCampuses(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := make([]dto.CampusSettingsDto, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, toCampusSettingsDto(row))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// CreateCampus handler adds a campus.
+//
+// POST /admin/campuses
+func (h *Handler) CreateCampus(w http.ResponseWriter, r *http.Request) {
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	req := dto.CreateCampusRequest{}
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := appvalidator.Validate(req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	input, err := campusInput(req.CampusRequest, currentUser.ID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+	input.Slug = req.Slug
+
+	row, err := h.campusAdmin.CreateCampus(r.Context(), input)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toCampusSettingsDto(row))
+}
+
+// UpdateCampus handler replaces the settings of a campus.
+//
+// PUT /admin/campuses/{id}
+func (h *Handler) UpdateCampus(w http.ResponseWriter, r *http.Request) {
+
+	campusID, err := parsePathID(r, "id", "Campus ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	req := dto.CampusRequest{}
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := appvalidator.Validate(req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	input, err := campusInput(req, currentUser.ID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	row, err := h.campusAdmin.UpdateCampus(r.Context(), campusID, input)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toCampusSettingsDto(row))
+}
+
+// CreateRoom handler adds a room to a campus.
+//
+// POST /admin/campuses/{id}/rooms
+func (h *Handler) CreateRoom(w http.ResponseWriter, r *http.Request) {
+
+	campusID, err := parsePathID(r, "id", "Campus ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	req := dto.CreateRoomRequest{}
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := appvalidator.Validate(req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	room, err := h.campusAdmin.CreateRoom(r.Context(), service.CreateRoomInput{
+		CampusID:  campusID,
+		Name:      req.Name,
+		CreatedBy: currentUser.ID,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toRoomDto(room))
+}
+
+// campusFor returns the campus a request acts on: the user's own campus, or
+// the one named by the campus query parameter. Only users who manage all
+// campuses may name another campus than their own.
+func (h *Handler) campusFor(r *http.Request, user auth.User) (*campus.Campus, error) {
+	home := h.campuses.Resolve(user.CampusID)
+
+	param := r.URL.Query().Get("campus")
+	if param == "" {
+		return home, nil
+	}
+
+	id, err := strconv.ParseInt(param, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, appvalidator.NewValidationError(map[string]string{
+			"campus": "Campus must be a positive number",
+		})
+	}
+	if id == home.ID {
+		return home, nil
+	}
+	if !user.Has(rbac.CampusesManage) {
+		return nil, service.ErrUnauthorized
+	}
+
+	c, ok := h.campuses.Get(id)
+	if !ok {
+		return nil, service.ErrCampusNotFound
+	}
+	return c, nil
+}
+
+// campusScope returns the campus staff actions of the user are limited to,
+// 0 for users who manage all campuses.
+func campusScope(user auth.User) int64 {
+	if user.Has(rbac.CampusesManage) {
+		return 0
+	}
+	return user.CampusID
+}
+
+// campusInput converts a campus request, parsing its opening hours.
+func campusInput(req dto.CampusRequest, actorID int64) (service.CampusInput, error) {
+	open, closing, err := parseOpeningHours(dto.OpeningHoursRequest{Open: req.Open, Close: req.Close})
+	if err != nil {
+		return service.CampusInput{}, err
+	}
+
+	return service.CampusInput{
+		Name:           req.Name,
+		IntraCampusID:  req.IntraCampusID,
+		Timezone:       req.Timezone,
+		Open:           open,
+		Close:          closing,
+		LoginProviders: req.LoginProviders,
+		CalendarID:     req.CalendarID,
+		CORSOrigins:    req.CORSOrigins,
+		ActorID:        actorID,
+	}, nil
+}
+
+func toCampusSettingsDto(row database.Campus) dto.CampusSettingsDto {
+	var intraCampusID *int
+	if row.IntraCampusID.Valid {
+		id := int(row.IntraCampusID.Int32)
+		intraCampusID = &id
+	}
+	return dto.CampusSettingsDto{
+		CampusDto: dto.CampusDto{
+			ID:             row.ID,
+			Slug:           row.Slug,
+			Name:           row.Name,
+			Timezone:       row.Timezone,
+			LoginProviders: row.LoginProviders,
+		},
+		IntraCampusID: intraCampusID,
+		Open:          schedule.FormatMinute(int(row.OpenMinute)),
+		Close:         schedule.FormatMinute(int(row.CloseMinute)),
+		CalendarID:    row.CalendarID,
+		CORSOrigins:   row.CorsOrigins,
+		UpdatedAt:     row.UpdatedAt.UTC(),
+	}
+}
+
+func toRoomDto(room database.Room) dto.RoomDto {
+	return dto.RoomDto{
+		ID:       room.ID,
+		CampusID: room.CampusID,
+		Name:     room.Name,
+	}
+}
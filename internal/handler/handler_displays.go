@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/service"
+	appvalidator "github.com/IbnBaqqi/book-me/internal/validator"
+)
+
+// GetRoomDisplay handler returns the compact status shown on a room display:
+// whether the room is free now and the current and next booking.
+//
+// GET /rooms/{id}/display
+func (h *Handler) GetRoomDisplay(w http.ResponseWriter, r *http.Request) {
+
+	roomID, err := parsePathID(r, "id", "Room ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	result, err := h.displays.GetRoomDisplay(r.Context(), roomID, currentUser.Grants)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// CreateDisplayDevice handler registers a display device for a room.
+// The device token is only returned in this response.
+//
+// POST /rooms/{id}/displays
+func (h *Handler) CreateDisplayDevice(w http.ResponseWriter, r *http.Request) {
+
+	roomID, err := parsePathID(r, "id", "Room ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	req := dto.CreateDisplayDeviceRequest{}
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := appvalidator.Validate(req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	device, token, err := h.displays.CreateDevice(r.Context(), service.CreateDisplayDeviceInput{
+		RoomID:    roomID,
+		Name:      req.Name,
+		CreatedBy: currentUser.ID,
+		CampusID:  currentUser.CampusID,
+		Grants:    currentUser.Grants,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, dto.CreatedDisplayDeviceDto{
+		DisplayDeviceDto: toDisplayDeviceDto(device),
+		Token:            token,
+	})
+}
+
+// ListDisplayDevices handler returns the display devices of a room.
+//
+// GET /rooms/{id}/displays
+func (h *Handler) ListDisplayDevices(w http.ResponseWriter, r *http.Request) {
+
+	roomID, err := parsePathID(r, "id", "Room ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	devices, err := h.displays.ListDevices(r.Context(), roomID, currentUser.CampusID, currentUser.Grants)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := make([]dto.DisplayDeviceDto, 0, len(devices))
+	for _, d := range devices {
+		result = append(result, toDisplayDeviceDto(d))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// RevokeDisplayDevice handler removes a display device of a room.
+//
+// DELETE /rooms/{id}/displays/{deviceId}
+func (h *Handler) RevokeDisplayDevice(w http.ResponseWriter, r *http.Request) {
+
+	roomID, err := parsePathID(r, "id", "Room ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	deviceID, err := parsePathID(r, "deviceId", "Device ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.displays.RevokeDevice(r.Context(), roomID, deviceID, currentUser.ID, currentUser.CampusID, currentUser.Grants); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toDisplayDeviceDto(d database.DisplayDevice) dto.DisplayDeviceDto {
+	result := dto.DisplayDeviceDto{
+		ID:        d.ID,
+		RoomID:    d.RoomID,
+		Name:      d.Name,
+		Prefix:    d.TokenPrefix,
+		CreatedAt: d.CreatedAt.UTC(),
+	}
+	if d.LastSeenAt.Valid {
+		lastSeen := d.LastSeenAt.Time.UTC()
+		result.LastSeenAt = &lastSeen
+	}
+	return result
+}
@@ -1,88 +1,102 @@
 package handler
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"log/slog"
 	"net/http"
 	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/health"
+	"github.com/IbnBaqqi/book-me/internal/integration"
 )
 
+// ProbeResponse is the body of the liveness and readiness probes.
+type ProbeResponse struct {
+	Status string `json:"status"`
+}
+
 // HealthResponse represents the health check response
 type HealthResponse struct {
-	Status string            `json:"status"`
-	Checks map[string]string `json:"checks"`
+	Status       string                         `json:"status"` // healthy, degraded or unhealthy
+	Ready        bool                           `json:"ready"`
+	Draining     bool                           `json:"draining"`
+	Checks       map[string]HealthCheckResponse `json:"checks"`
+	Integrations map[string]integration.State   `json:"integrations"`
 }
 
-// Health returns the server and dependencies health status.
-func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 6*time.Second)
-	defer cancel()
+// HealthCheckResponse is the last run of a dependency check.
+type HealthCheckResponse struct {
+	Status      string     `json:"status"`
+	Critical    bool       `json:"critical"`
+	LatencyMs   float64    `json:"latencyMs"`
+	CheckedAt   *time.Time `json:"checkedAt,omitempty"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	Failures    int        `json:"failures"`
+}
 
-	checks := make(map[string]string)
-	allHealthy := true
+// Livez tells the orchestrator the process is up. It checks no dependency,
+// so a failing database or calendar doesn't get the server restarted.
+func (h *Handler) Livez(w http.ResponseWriter, r *http.Request) {
+	respondWithJSON(w, http.StatusOK, ProbeResponse{Status: "alive"})
+}
 
-	// Check database connectivity
-	if err := h.checkDatabase(ctx); err != nil {
-		checks["database"] = "unhealthy"
-		allHealthy = false
-		slog.Error("health check: database unhealthy", "error", err)
-	} else {
-		checks["database"] = "healthy"
+// Readyz tells load balancers whether to send traffic, from the cached
+// dependency checks. It fails while the server drains before shutting down.
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	report := h.health.Report()
+	switch {
+	case report.Draining:
+		respondWithJSON(w, http.StatusServiceUnavailable, ProbeResponse{Status: "draining"})
+	case !report.Ready:
+		respondWithJSON(w, http.StatusServiceUnavailable, ProbeResponse{Status: "not ready"})
+	default:
+		respondWithJSON(w, http.StatusOK, ProbeResponse{Status: "ready"})
 	}
+}
 
-	// Check Google Calendar API
-	// (calendar is non-critical so it doesn't fail health check)
-	if err := h.checkCalendar(ctx); err != nil {
-		checks["calendar"] = "degraded"
-		slog.Warn("health check: calendar degraded", "error", err)
-	} else {
-		checks["calendar"] = "healthy"
-	}
+// Health returns the cached result of every dependency check, with its
+// latency and last error, and the state of the optional integrations.
+func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+	report := h.health.Report()
 
-	// Email service check
-	if h.email == nil {
-		checks["email"] = "unhealthy: service not initialized"
-		allHealthy = false
-	} else {
-		checks["email"] = "healthy"
+	status := "healthy"
+	checks := make(map[string]HealthCheckResponse, len(report.Checks))
+	for _, result := range report.Checks {
+		if result.Status == health.StatusDegraded && status == "healthy" {
+			status = "degraded"
+		}
+		checks[result.Name] = HealthCheckResponse{
+			Status:      result.Status,
+			Critical:    result.Critical,
+			LatencyMs:   float64(result.Latency.Microseconds()) / 1000,
+			CheckedAt:   timeOrNil(result.CheckedAt),
+			LastError:   result.LastError,
+			LastErrorAt: timeOrNil(result.LastErrorAt),
+			Failures:    result.Failures,
+		}
 	}
 
-	// Determine overall status
-	status := "healthy"
 	statusCode := http.StatusOK
-	if !allHealthy {
+	if !report.Ready {
 		status = "unhealthy"
 		statusCode = http.StatusServiceUnavailable
 	}
 
-	response := HealthResponse{
-		Status: status,
-		Checks: checks,
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		slog.Error("failed to encode health response", "error", err)
-	}
+	respondWithJSON(w, statusCode, HealthResponse{
+		Status:   status,
+		Ready:    report.Ready,
+		Draining: report.Draining,
+		Checks:   checks,
+		Integrations: map[string]integration.State{
+			"calendar": h.calendar.State(),
+			"email":    h.email.State(),
+		},
+	})
 }
 
-// checkDatabase verifies database connectivity
-func (h *Handler) checkDatabase(ctx context.Context) error {
-	if h.db == nil {
+// timeOrNil returns nil for the zero time, so it is left out of responses.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
 		return nil
 	}
-	return h.db.PingContext(ctx)
-}
-
-// checkCalendar verifies Google Calendar API is reachable
-func (h *Handler) checkCalendar(ctx context.Context) error {
-	if h.calendar == nil {
-		return fmt.Errorf("database connection not initialized")
-	}
-
-	return h.calendar.HealthCheck(ctx)
+	return &t
 }
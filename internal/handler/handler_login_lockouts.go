@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+)
+
+// ListLoginLockouts handler returns the IPs and accounts locked out after
+// failed logins.
+//
+// GET /admin/login-lockouts
+func (h *Handler) ListLoginLockouts(w http.ResponseWriter, r *http.Request) {
+
+	lockouts, err := h.loginGuard.ListLockouts(r.Context())
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := make([]dto.LoginLockoutDto, 0, len(lockouts))
+	for _, lockout := range lockouts {
+		result = append(result, toLoginLockoutDto(lockout))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// UnblockLogin handler lifts the lockout of an IP or account.
+//
+// DELETE /admin/login-lockouts/{kind}/{subject}
+func (h *Handler) UnblockLogin(w http.ResponseWriter, r *http.Request) {
+
+	kind, subject, err := parseLockoutKey(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.loginGuard.Unblock(r.Context(), kind, subject, currentUser.ID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toLoginLockoutDto(lockout database.LoginLockout) dto.LoginLockoutDto {
+	return dto.LoginLockoutDto{
+		ID:            lockout.ID,
+		Kind:          lockout.Kind,
+		Subject:       lockout.Subject,
+		Lockouts:      lockout.Lockouts,
+		LastReason:    lockout.LastReason,
+		LastFailureAt: lockout.LastFailureAt.UTC(),
+		LockedUntil:   lockout.LockedUntil.Time.UTC(),
+	}
+}
@@ -1,13 +1,33 @@
 package handler
 
 import (
+	"context"
+	"embed"
+	"errors"
 	"fmt"
+	"html/template"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/metrics"
+	"github.com/IbnBaqqi/book-me/internal/oauth"
 )
 
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var pageTemplates = template.Must(template.ParseFS(templateFS, "templates/*.html"))
+
+// accessDeniedPage holds data for the login rejection page
+type accessDeniedPage struct {
+	Reason string
+}
+
 // Login42 handles user login / sign-in
 func (h *Handler) Login42(w http.ResponseWriter, r *http.Request) {
 
@@ -26,6 +46,12 @@ func (h *Handler) Login42(w http.ResponseWriter, r *http.Request) {
 // Callback42 handles callback from Oauth flow
 func (h *Handler) Callback42(w http.ResponseWriter, r *http.Request) {
 
+	// Reject clients locked out after failed logins
+	if err := h.oauth.CheckLockout(r.Context()); err != nil {
+		handleError(w, err)
+		return
+	}
+
 	// Validate CSRF state
 	if err := h.oauth.ValidateState(w, r); err != nil {
 		handleError(w, err)
@@ -33,8 +59,9 @@ func (h *Handler) Callback42(w http.ResponseWriter, r *http.Request) {
 	}
 
 	user, err := h.oauth.Handle42Callback(r)
+	h.auditLogin(r.Context(), oauth.Provider42Name, user, err)
 	if err != nil {
-		handleError(w, err)
+		h.handleCallbackError(w, err)
 		return
 	}
 
@@ -48,7 +75,7 @@ func (h *Handler) Callback42(w http.ResponseWriter, r *http.Request) {
 
 	params := url.Values{}
 	params.Add("token", jwtToken)
-	params.Add("intra", user.Name)
+	params.Add("intra", displayLogin(user))
 	params.Add("role", strings.ToLower(user.Role))
 
 	// final redirect
@@ -56,6 +83,15 @@ func (h *Handler) Callback42(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, finalRedirectURL, http.StatusFound)
 }
 
+// displayLogin returns the user's login, falling back to the name
+// for accounts that predate stored logins
+func displayLogin(user database.User) string {
+	if user.Login.Valid {
+		return user.Login.String
+	}
+	return user.Name
+}
+
 // LoginKeycloak handles user Hive's keycloak login / sign-in
 func (h *Handler) LoginKeycloak(w http.ResponseWriter, r *http.Request) {
 
@@ -75,15 +111,22 @@ func (h *Handler) LoginKeycloak(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) CallbackKeycloak(w http.ResponseWriter, r *http.Request) {
 
 	slog.Debug("got to callback") // remove later
+	// Reject clients locked out after failed logins
+	if err := h.oauth.CheckLockout(r.Context()); err != nil {
+		handleError(w, err)
+		return
+	}
+
 	// Validate CSRF state
 	if err := h.oauth.ValidateState(w, r); err != nil {
 		handleError(w, err)
 		return
 	}
 
-	user, err := h.oauth.HandleKeycloakCallback(r)
+	user, err := h.oauth.HandleKeycloakCallback(w, r)
+	h.auditLogin(r.Context(), oauth.ProviderKeycloakName, user, err)
 	if err != nil {
-		handleError(w, err)
+		h.handleCallbackError(w, err)
 		return
 	}
 
@@ -97,10 +140,74 @@ func (h *Handler) CallbackKeycloak(w http.ResponseWriter, r *http.Request) {
 
 	params := url.Values{}
 	params.Add("token", jwtToken)
-	params.Add("intra", user.Name)
+	params.Add("intra", displayLogin(user))
 	params.Add("role", strings.ToLower(user.Role))
 
 	// final redirect
 	finalRedirectURL := fmt.Sprintf("%s?%s", h.oauth.GetRedirectTokenURL(), params.Encode())
 	http.Redirect(w, r, finalRedirectURL, http.StatusFound)
 }
+
+// auditLogin records a successful or failed login in the audit log and
+// the login metrics.
+func (h *Handler) auditLogin(ctx context.Context, provider string, user database.User, err error) {
+	metrics.OAuthLogins.WithLabelValues(provider, loginOutcome(err)).Inc()
+
+	if err != nil {
+		h.audit.Record(ctx, audit.Entry{
+			Action:     audit.LoginFailure,
+			TargetType: audit.TargetLogin,
+			TargetID:   provider,
+			After: map[string]string{
+				"provider": provider,
+				"error":    err.Error(),
+			},
+		})
+		return
+	}
+
+	h.audit.Record(ctx, audit.Entry{
+		Action:     audit.LoginSuccess,
+		ActorID:    user.ID,
+		UserID:     user.ID,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatInt(user.ID, 10),
+		After: map[string]string{
+			"provider": provider,
+			"role":     user.Role,
+		},
+	})
+}
+
+// loginOutcome tells logins refused by the access policy apart from failures.
+func loginOutcome(err error) string {
+	var oauthErr *oauth.OauthError
+	switch {
+	case err == nil:
+		return metrics.OutcomeSuccess
+	case errors.As(err, &oauthErr) && oauthErr.StatusCode == http.StatusForbidden:
+		return metrics.OutcomeDenied
+	default:
+		return metrics.OutcomeFailure
+	}
+}
+
+// handleCallbackError renders an explanation page for rejected logins,
+// since the user lands on the callback from the provider in a browser.
+// Other errors are returned as JSON.
+func (h *Handler) handleCallbackError(w http.ResponseWriter, err error) {
+	var oauthErr *oauth.OauthError
+	if !errors.As(err, &oauthErr) || oauthErr.StatusCode != http.StatusForbidden {
+		handleError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+	page := accessDeniedPage{
+		Reason: oauthErr.Message,
+	}
+	if err := pageTemplates.ExecuteTemplate(w, "access_denied.html", page); err != nil {
+		slog.Error("failed to render access denied page", "error", err)
+	}
+}
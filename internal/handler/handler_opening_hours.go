@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/schedule"
+	"github.com/IbnBaqqi/book-me/internal/service"
+	appvalidator "github.com/IbnBaqqi/book-me/internal/validator"
+)
+
+// GetOpeningHours handler returns the effective opening hours of the
+// user's campus on every date in the range, with holidays and special
+// hours applied.
+//
+// GET /opening-hours?start=2026-12-20&end=2026-12-31
+func (h *Handler) GetOpeningHours(w http.ResponseWriter, r *http.Request) {
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	home, err := h.campusFor(r, currentUser)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	// Dates are campus days, whatever the tz param says
+	startDate, endDate, err := parseDateRange(r, home.Location)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	days := h.openingHours.Days(home, startDate, endDate)
+
+	result := make([]dto.OpeningDayDto, 0, len(days))
+	for _, d := range days {
+		day := dto.OpeningDayDto{
+			Date:      d.Date,
+			Closed:    d.Hours.Closed,
+			Name:      d.Name,
+			Exception: d.Exception,
+		}
+		if !d.Hours.Closed {
+			day.Open = schedule.FormatMinute(d.Hours.Open)
+			day.Close = schedule.FormatMinute(d.Hours.Close)
+		}
+		result = append(result, day)
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// ListOpeningHoursExceptions handler returns all holidays and special hours
+// of the user's campus.
+//
+// GET /admin/opening-hours
+func (h *Handler) ListOpeningHoursExceptions(w http.ResponseWriter, r *http.Request) {
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	home, err := h.campusFor(r, currentUser)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	rows, err := h.openingHours.ListExceptions(r.Context(), home.ID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := make([]dto.OpeningHoursExceptionDto, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, toOpeningHoursExceptionDto(row))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// SetOpeningHours handler closes the campus or sets special hours on a date.
+//
+// PUT /admin/opening-hours/{date}
+func (h *Handler) SetOpeningHours(w http.ResponseWriter, r *http.Request) {
+
+	date, err := parsePathDate(r, "date")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	req := dto.OpeningHoursRequest{}
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := appvalidator.Validate(req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	open, closing, err := parseOpeningHours(req)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	home, err := h.campusFor(r, currentUser)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	row, err := h.openingHours.SetException(r.Context(), service.SetOpeningHoursInput{
+		CampusID:  home.ID,
+		Date:      date,
+		Closed:    req.Closed,
+		Open:      open,
+		Close:     closing,
+		Name:      req.Name,
+		CreatedBy: currentUser.ID,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toOpeningHoursExceptionDto(row))
+}
+
+// DeleteOpeningHours handler restores the regular hours on a date.
+//
+// DELETE /admin/opening-hours/{date}
+func (h *Handler) DeleteOpeningHours(w http.ResponseWriter, r *http.Request) {
+
+	date, err := parsePathDate(r, "date")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	home, err := h.campusFor(r, currentUser)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	if err := h.openingHours.DeleteException(r.Context(), home.ID, date, currentUser.ID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportOpeningHours handler imports holidays and special hours from an
+// iCalendar (.ics) file sent as the request body.
+//
+// POST /admin/opening-hours/import
+func (h *Handler) ImportOpeningHours(w http.ResponseWriter, r *http.Request) {
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	home, err := h.campusFor(r, currentUser)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+
+	imported, err := h.openingHours.ImportICS(r.Context(), home.ID, r.Body, currentUser.ID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, dto.OpeningHoursImportDto{Imported: imported})
+}
+
+func toOpeningHoursExceptionDto(row database.OpeningHoursException) dto.OpeningHoursExceptionDto {
+	result := dto.OpeningHoursExceptionDto{
+		Date:      row.Date.Format(schedule.DateLayout),
+		Closed:    row.Closed,
+		Name:      row.Name,
+		SourceUID: row.SourceUid,
+		UpdatedAt: row.UpdatedAt.UTC(),
+	}
+	if !row.Closed {
+		result.Open = schedule.FormatMinute(int(row.OpenMinute))
+		result.Close = schedule.FormatMinute(int(row.CloseMinute))
+	}
+	return result
+}
@@ -34,20 +34,23 @@ func (h *Handler) CreateReservation(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate the request
-	if err := appvalidator.Validate(req); err != nil {
+	// Validate the request against the opening hours of the user's campus
+	home := h.campuses.Resolve(currentUser.CampusID)
+	ctx := appvalidator.WithOpeningHours(r.Context(), home.Hours)
+	if err := appvalidator.ValidateCtx(ctx, req); err != nil {
 		handleError(w, err)
 		return
 	}
 
 	// Call service
 	reservation, err := h.reservation.CreateReservation(r.Context(), service.CreateReservationInput{
+		CampusID:  home.ID,
 		UserID:    currentUser.ID,
 		UserName:  currentUser.Name,
-		UserRole:  currentUser.Role,
+		Grants:    currentUser.Grants,
 		RoomID:    req.RoomID,
-		StartTime: req.StartTime,
-		EndTime:   req.EndTime,
+		StartTime: req.StartTime.UTC(),
+		EndTime:   req.EndTime.UTC(),
 	})
 
 	if err != nil {
@@ -67,31 +70,45 @@ func (h *Handler) CreateReservation(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// GetReservations Handler handles fetching reservations and group them
+// GetReservations Handler handles fetching reservations and group them.
+// The dates are days in the campus time zone, or in the tz query param.
 //
 // GET /reservations
 func (h *Handler) GetReservations(w http.ResponseWriter, r *http.Request) {
 
+	// Get authenticated user from context
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	home, err := h.campusFor(r, currentUser)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
 	// Validate & parse query parameters
-	startDate, endDate, err := parseDateRange(r)
+	loc, err := parseLocation(r, home.Location)
 	if err != nil {
 		handleError(w, err)
 		return
 	}
 
-	// Get authenticated user from context
-	currentUser, ok := auth.UserFromContext(r.Context())
-	if !ok {
-		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	startDate, endDate, err := parseDateRange(r, loc)
+	if err != nil {
+		handleError(w, err)
 		return
 	}
 
 	// Build service input
 	input := service.GetReservationsInput{
+		CampusID:  home.ID,
 		StartDate: startDate,
 		EndDate:   endDate,
 		UserID:    currentUser.ID,
-		UserRole:  currentUser.Role,
+		Grants:    currentUser.Grants,
 	}
 
 	// Call service
@@ -124,8 +141,9 @@ func (h *Handler) CancelReservation(w http.ResponseWriter, r *http.Request) {
 
 	input := service.CancelReservationInput{
 		ID:       id,
+		CampusID: h.campuses.Resolve(currentUser.CampusID).ID,
 		UserID:   currentUser.ID,
-		UserRole: currentUser.Role,
+		Grants:   currentUser.Grants,
 	}
 
 	// Call service
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/service"
+	appvalidator "github.com/IbnBaqqi/book-me/internal/validator"
+)
+
+// ListUserRoles handler returns a user's base role and granted roles.
+//
+// GET /admin/users/{id}/roles
+func (h *Handler) ListUserRoles(w http.ResponseWriter, r *http.Request) {
+
+	userID, err := parsePathID(r, "id", "User ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	user, assignments, err := h.roles.ListRoles(r.Context(), userID, campusScope(currentUser))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := dto.UserRolesDto{
+		UserID:      user.ID,
+		BaseRole:    user.Role,
+		Assignments: make([]dto.RoleAssignmentDto, 0, len(assignments)),
+	}
+	for _, a := range assignments {
+		result.Assignments = append(result.Assignments, toRoleAssignmentDto(a))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// AssignRole handler grants a role to a user.
+//
+// POST /admin/users/{id}/roles
+func (h *Handler) AssignRole(w http.ResponseWriter, r *http.Request) {
+
+	userID, err := parsePathID(r, "id", "User ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	req := dto.AssignRoleRequest{}
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := appvalidator.Validate(req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	assignment, err := h.roles.AssignRole(r.Context(), service.AssignRoleInput{
+		UserID:    userID,
+		Role:      req.Role,
+		RoomID:    req.RoomID,
+		GrantedBy: currentUser.ID,
+		CampusID:  campusScope(currentUser),
+		Grants:    currentUser.Grants,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, toRoleAssignmentDto(assignment))
+}
+
+// RevokeRole handler removes a role granted to a user.
+//
+// DELETE /admin/users/{id}/roles/{assignmentId}
+func (h *Handler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+
+	userID, err := parsePathID(r, "id", "User ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	assignmentID, err := parsePathID(r, "assignmentId", "Assignment ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	err = h.roles.RevokeRole(r.Context(), service.RevokeRoleInput{
+		UserID:       userID,
+		AssignmentID: assignmentID,
+		RevokedBy:    currentUser.ID,
+		CampusID:     campusScope(currentUser),
+		Grants:       currentUser.Grants,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toRoleAssignmentDto(a database.RoleAssignment) dto.RoleAssignmentDto {
+	result := dto.RoleAssignmentDto{
+		ID:        a.ID,
+		Role:      a.Role,
+		CreatedAt: a.CreatedAt.UTC(),
+	}
+	if a.RoomID.Valid {
+		result.RoomID = &a.RoomID.Int64
+	}
+	if a.GrantedBy.Valid {
+		result.GrantedBy = &a.GrantedBy.Int64
+	}
+	return result
+}
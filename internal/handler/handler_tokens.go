@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/service"
+	appvalidator "github.com/IbnBaqqi/book-me/internal/validator"
+)
+
+// CreateAPIToken handler creates a personal access token for the current user.
+// The plain token is only returned in this response.
+//
+// POST /tokens
+func (h *Handler) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	req := dto.CreateAPITokenRequest{}
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := appvalidator.Validate(req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	apiToken, token, err := h.apiTokens.CreateToken(r.Context(), service.CreateAPITokenInput{
+		UserID:    currentUser.ID,
+		Name:      req.Name,
+		Scopes:    req.Scopes,
+		ExpiresIn: time.Duration(req.ExpiresInDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, dto.CreatedAPITokenDto{
+		APITokenDto: toAPITokenDto(apiToken),
+		Token:       token,
+	})
+}
+
+// ListAPITokens handler returns the current user's personal access tokens.
+//
+// GET /tokens
+func (h *Handler) ListAPITokens(w http.ResponseWriter, r *http.Request) {
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	tokens, err := h.apiTokens.ListTokens(r.Context(), currentUser.ID)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := make([]dto.APITokenDto, 0, len(tokens))
+	for _, t := range tokens {
+		result = append(result, toAPITokenDto(t))
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// RevokeAPIToken handler revokes one of the current user's personal access tokens.
+//
+// DELETE /tokens/{id}
+func (h *Handler) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+
+	tokenID, err := parsePathID(r, "id", "Token ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	if err := h.apiTokens.RevokeToken(r.Context(), currentUser.ID, tokenID); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func toAPITokenDto(t database.ApiToken) dto.APITokenDto {
+	result := dto.APITokenDto{
+		ID:        t.ID,
+		Name:      t.Name,
+		Prefix:    t.TokenPrefix,
+		Scopes:    t.Scopes,
+		ExpiresAt: t.ExpiresAt.UTC(),
+		CreatedAt: t.CreatedAt.UTC(),
+	}
+	if t.LastUsedAt.Valid {
+		lastUsed := t.LastUsedAt.Time.UTC()
+		result.LastUsedAt = &lastUsed
+	}
+	return result
+}
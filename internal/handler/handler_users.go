@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/service"
+	appvalidator "github.com/IbnBaqqi/book-me/internal/validator"
+)
+
+// maxUserReservations caps the booking history returned for a user.
+const maxUserReservations = 500
+
+// ListUsers handler searches users by name, email or login, optionally
+// filtered by role and status. Staff see the users of their own campus,
+// users who manage all campuses see every campus unless one is named.
+// The total number of matches is returned in the X-Total-Count header.
+//
+// GET /admin/users
+func (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {
+
+	input, err := parseUserListQuery(r)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	input.CampusID = campusScope(currentUser)
+	if r.URL.Query().Has("campus") {
+		c, err := h.campusFor(r, currentUser)
+		if err != nil {
+			handleError(w, err)
+			return
+		}
+		input.CampusID = c.ID
+	}
+
+	users, total, err := h.users.ListUsers(r.Context(), input)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := make([]dto.AdminUserDto, 0, len(users))
+	for _, u := range users {
+		result = append(result, toAdminUserDto(u))
+	}
+
+	w.Header().Set("X-Total-Count", strconv.FormatInt(total, 10))
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// GetUser handler returns a single user.
+//
+// GET /admin/users/{id}
+func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {
+
+	userID, err := parsePathID(r, "id", "User ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	user, err := h.users.GetUser(r.Context(), userID, campusScope(currentUser))
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toAdminUserDto(user))
+}
+
+// ListUserReservations handler returns a user's bookings, newest first,
+// optionally limited to the from/to range.
+//
+// GET /admin/users/{id}/reservations
+func (h *Handler) ListUserReservations(w http.ResponseWriter, r *http.Request) {
+
+	userID, err := parsePathID(r, "id", "User ID")
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	loc, err := parseLocation(r, h.campuses.Resolve(currentUser.CampusID).Location)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	fields := make(map[string]string)
+	from, to := parseTimeRange(r.URL.Query(), loc, fields)
+	if len(fields) > 0 {
+		handleError(w, &appvalidator.ValidationError{
+			Message: "Invalid query parameters",
+			Fields:  fields,
+		})
+		return
+	}
+
+	reservations, err := h.users.ListUserReservations(r.Context(), userID, campusScope(currentUser), from, to, maxUserReservations)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	result := make([]dto.UserReservationDto, 0, len(reservations))
+	for _, res := range reservations {
+		result = append(result, dto.UserReservationDto{
+			ID:        res.ID,
+			RoomID:    res.RoomID,
+			RoomName:  res.RoomName,
+			StartTime: res.StartTime.UTC(),
+			EndTime:   res.EndTime.UTC(),
+			Status:    res.Status,
+		})
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// SuspendUser handler suspends a user, blocking login and booking.
+//
+// PUT /admin/users/{id}/suspension
+func (h *Handler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+
+	input, ok := h.userActionInput(w, r)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodySize)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	req := dto.SuspendUserRequest{}
+	if err := decoder.Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := appvalidator.Validate(req); err != nil {
+		handleError(w, err)
+		return
+	}
+
+	user, err := h.users.SuspendUser(r.Context(), input, req.Reason)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toAdminUserDto(user))
+}
+
+// UnsuspendUser handler lifts a user's suspension.
+//
+// DELETE /admin/users/{id}/suspension
+func (h *Handler) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+
+	input, ok := h.userActionInput(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.users.UnsuspendUser(r.Context(), input)
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, toAdminUserDto(user))
+}
+
+// DeleteUser handler anonymizes a user, or deletes them with all their
+// bookings when called with mode=delete.
+//
+// DELETE /admin/users/{id}
+func (h *Handler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+
+	input, ok := h.userActionInput(w, r)
+	if !ok {
+		return
+	}
+
+	var err error
+	switch r.URL.Query().Get("mode") {
+	case "", "anonymize":
+		err = h.users.AnonymizeUser(r.Context(), input)
+	case "delete":
+		err = h.users.DeleteUser(r.Context(), input)
+	default:
+		err = &appvalidator.ValidationError{
+			Message: "Invalid query parameters",
+			Fields: map[string]string{
+				"mode": "Must be one of: anonymize delete",
+			},
+		}
+	}
+	if err != nil {
+		handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// userActionInput reads the target user from path and the acting user from context.
+func (h *Handler) userActionInput(w http.ResponseWriter, r *http.Request) (service.UserActionInput, bool) {
+	userID, err := parsePathID(r, "id", "User ID")
+	if err != nil {
+		handleError(w, err)
+		return service.UserActionInput{}, false
+	}
+
+	currentUser, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "unauthorized")
+		return service.UserActionInput{}, false
+	}
+
+	return service.UserActionInput{
+		UserID:   userID,
+		ActorID:  currentUser.ID,
+		CampusID: campusScope(currentUser),
+		Grants:   currentUser.Grants,
+	}, true
+}
+
+func toAdminUserDto(u database.User) dto.AdminUserDto {
+	result := dto.AdminUserDto{
+		ID:              u.ID,
+		Email:           u.Email,
+		Name:            u.Name,
+		Role:            u.Role,
+		CampusID:        u.CampusID,
+		Suspended:       u.SuspendedAt.Valid,
+		SuspendedReason: u.SuspendedReason,
+		UpdatedAt:       u.UpdatedAt.UTC(),
+	}
+	if u.Login.Valid {
+		result.Login = &u.Login.String
+	}
+	if u.AvatarUrl.Valid {
+		result.AvatarURL = &u.AvatarUrl.String
+	}
+	if u.SuspendedAt.Valid {
+		suspendedAt := u.SuspendedAt.Time.UTC()
+		result.SuspendedAt = &suspendedAt
+	}
+	return result
+}
@@ -2,9 +2,16 @@ package handler
 
 import (
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+	"github.com/IbnBaqqi/book-me/internal/schedule"
+	"github.com/IbnBaqqi/book-me/internal/service"
 	"github.com/IbnBaqqi/book-me/internal/validator"
 )
 
@@ -18,8 +25,40 @@ type reservationIDParam struct {
 	ID int64 `validate:"gt=0"`
 }
 
-// parseDateRange extracts and validates start/end dates from query params
-func parseDateRange(r *http.Request) (time.Time, time.Time, error) {
+type loginParam struct {
+	Login string `validate:"required,max=100"`
+}
+
+type lockoutKeyParam struct {
+	Kind    string `validate:"required,oneof=ip account"`
+	Subject string `validate:"required,max=255"`
+}
+
+// parseLocation reads the optional tz query param as an IANA time zone
+// name, falling back to loc when it is not set.
+func parseLocation(r *http.Request, loc *time.Location) (*time.Location, error) {
+	name := r.URL.Query().Get("tz")
+	if name == "" {
+		return loc, nil
+	}
+
+	// "Local" would be the time zone of the server, not of the user
+	tz, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil, &validator.ValidationError{
+			Message: "Invalid query parameters",
+			Fields: map[string]string{
+				"tz": "Unknown time zone, expected an IANA name such as Europe/Helsinki",
+			},
+		}
+	}
+	return tz, nil
+}
+
+// parseDateRange extracts and validates start/end dates from query params.
+// The dates are returned as midnight in loc, so a day covers 23 or 25 hours
+// when daylight saving time starts or ends.
+func parseDateRange(r *http.Request, loc *time.Location) (time.Time, time.Time, error) {
 	startDateStr := r.URL.Query().Get("start")
 	endDateStr := r.URL.Query().Get("end")
 
@@ -35,7 +74,7 @@ func parseDateRange(r *http.Request) (time.Time, time.Time, error) {
 	}
 
 	// Parse dates
-	startDate, err := time.Parse("2006-01-02", startDateStr)
+	startDate, err := time.ParseInLocation(schedule.DateLayout, startDateStr, loc)
 	if err != nil {
 		return time.Time{}, time.Time{}, &validator.ValidationError{
 			Message: "Invalid date format",
@@ -45,7 +84,7 @@ func parseDateRange(r *http.Request) (time.Time, time.Time, error) {
 		}
 	}
 
-	endDate, err := time.Parse("2006-01-02", endDateStr)
+	endDate, err := time.ParseInLocation(schedule.DateLayout, endDateStr, loc)
 	if err != nil {
 		return time.Time{}, time.Time{}, &validator.ValidationError{
 			Message: "Invalid date format",
@@ -99,3 +138,242 @@ func parseReservationID(r *http.Request) (int64, error) {
 
 	return id, nil
 }
+
+// parsePathID extracts and validates a positive numeric ID from path
+func parsePathID(r *http.Request, key, label string) (int64, error) {
+	idStr := r.PathValue(key)
+
+	if idStr == "" {
+		return 0, &validator.ValidationError{
+			Message: "Missing path parameter",
+			Fields: map[string]string{
+				key: label + " is required",
+			},
+		}
+	}
+
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, &validator.ValidationError{
+			Message: "Invalid path parameter",
+			Fields: map[string]string{
+				key: label + " must be a positive number",
+			},
+		}
+	}
+
+	return id, nil
+}
+
+// parseLogin extracts and validates a 42 login from path
+func parseLogin(r *http.Request) (string, error) {
+	login := strings.TrimSpace(r.PathValue("login"))
+
+	if err := validator.Validate(loginParam{Login: login}); err != nil {
+		return "", err
+	}
+
+	return login, nil
+}
+
+// parseLockoutKey extracts and validates the kind and subject of a login
+// lockout from path
+func parseLockoutKey(r *http.Request) (kind, subject string, err error) {
+	param := lockoutKeyParam{
+		Kind:    r.PathValue("kind"),
+		Subject: strings.TrimSpace(r.PathValue("subject")),
+	}
+
+	if err := validator.Validate(param); err != nil {
+		return "", "", err
+	}
+
+	return param.Kind, param.Subject, nil
+}
+
+// parsePathDate extracts a YYYY-MM-DD date from path
+func parsePathDate(r *http.Request, key string) (time.Time, error) {
+	date, err := time.Parse(schedule.DateLayout, r.PathValue(key))
+	if err != nil {
+		return time.Time{}, &validator.ValidationError{
+			Message: "Invalid path parameter",
+			Fields: map[string]string{
+				key: "Date must be in YYYY-MM-DD format",
+			},
+		}
+	}
+	return date, nil
+}
+
+// parseOpeningHours reads the "HH:MM" opening and closing times of a
+// request, they are only required when the day is not closed. A closing
+// time before the opening time is on the next day.
+func parseOpeningHours(req dto.OpeningHoursRequest) (open, closing int, err error) {
+	if req.Closed {
+		return 0, 0, nil
+	}
+
+	fields := map[string]string{}
+	open, openErr := schedule.ParseMinute(req.Open)
+	if openErr != nil {
+		fields["open"] = "Opening time must be HH:MM"
+	}
+	closing, closeErr := schedule.ParseMinute(req.Close)
+	if closeErr != nil {
+		fields["close"] = "Closing time must be HH:MM, or 24:00 for midnight"
+	}
+
+	if len(fields) > 0 {
+		return 0, 0, validator.NewValidationError(fields)
+	}
+	if closing < open {
+		closing += 24 * 60
+	}
+	return open, closing, nil
+}
+
+// parseTimeRange reads the optional from/to query params as RFC3339
+// timestamps, normalized to UTC, or YYYY-MM-DD dates in loc. A date as "to"
+// includes the whole day. Problems are added to fields.
+func parseTimeRange(query url.Values, loc *time.Location, fields map[string]string) (from, to time.Time) {
+	parse := func(key string, endOfDay bool) time.Time {
+		value := query.Get(key)
+		if value == "" {
+			return time.Time{}
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t.UTC()
+		}
+		t, err := time.ParseInLocation(schedule.DateLayout, value, loc)
+		if err != nil {
+			fields[key] = "Invalid format, expected RFC3339 or YYYY-MM-DD"
+			return time.Time{}
+		}
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t
+	}
+
+	from = parse("from", false)
+	to = parse("to", true)
+	if !from.IsZero() && !to.IsZero() && !to.After(from) {
+		fields["to"] = "Must be after from"
+	}
+	return from, to
+}
+
+// Audit log query limits
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 500
+	maxAuditExport    = 10000
+)
+
+// parseAuditFilter extracts and validates the audit log filters from query
+// params, plain dates are read in loc.
+func parseAuditFilter(r *http.Request, loc *time.Location, export bool) (audit.Filter, error) {
+	query := r.URL.Query()
+	fields := make(map[string]string)
+
+	filter := audit.Filter{
+		Action: query.Get("action"),
+		Limit:  defaultAuditLimit,
+	}
+	if export {
+		filter.Limit = maxAuditExport
+	}
+
+	parseID := func(key string) int64 {
+		value := query.Get(key)
+		if value == "" {
+			return 0
+		}
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			fields[key] = "Must be a positive number"
+		}
+		return id
+	}
+	filter.UserID = parseID("userId")
+	filter.RoomID = parseID("roomId")
+
+	filter.From, filter.To = parseTimeRange(query, loc, fields)
+
+	if value := query.Get("limit"); value != "" && !export {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxAuditLimit {
+			fields["limit"] = "Must be between 1 and " + strconv.Itoa(maxAuditLimit)
+		}
+		filter.Limit = limit
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			fields["offset"] = "Must be greater than or equal to 0"
+		}
+		filter.Offset = offset
+	}
+
+	if len(fields) > 0 {
+		return audit.Filter{}, &validator.ValidationError{
+			Message: "Invalid query parameters",
+			Fields:  fields,
+		}
+	}
+	return filter, nil
+}
+
+// User admin query limits
+const (
+	defaultUserPageSize = 50
+	maxUserPageSize     = 200
+)
+
+// parseUserListQuery extracts and validates the user search filters from query params.
+func parseUserListQuery(r *http.Request) (service.ListUsersInput, error) {
+	query := r.URL.Query()
+	fields := make(map[string]string)
+
+	input := service.ListUsersInput{
+		Search: query.Get("q"),
+		Role:   query.Get("role"),
+		Status: query.Get("status"),
+		Limit:  defaultUserPageSize,
+	}
+
+	if len(input.Search) > 100 {
+		fields["q"] = "Must be at most 100 characters"
+	}
+	if input.Role != "" {
+		if _, ok := rbac.ParseRole(input.Role); !ok {
+			fields["role"] = "Unknown role"
+		}
+	}
+	if input.Status != "" && input.Status != service.UserStatusActive && input.Status != service.UserStatusSuspended {
+		fields["status"] = "Must be one of: active suspended"
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxUserPageSize {
+			fields["limit"] = "Must be between 1 and " + strconv.Itoa(maxUserPageSize)
+		}
+		input.Limit = limit
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.Atoi(value)
+		if err != nil || offset < 0 {
+			fields["offset"] = "Must be greater than or equal to 0"
+		}
+		input.Offset = offset
+	}
+
+	if len(fields) > 0 {
+		return service.ListUsersInput{}, &validator.ValidationError{
+			Message: "Invalid query parameters",
+			Fields:  fields,
+		}
+	}
+	return input, nil
+}
@@ -7,6 +7,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/service"
 	"github.com/IbnBaqqi/book-me/internal/validator"
 )
 
@@ -125,7 +128,7 @@ func TestParseDateRange(t *testing.T) {
 			req.URL.RawQuery = q.Encode()
 
 			// Call parseDateRange
-			startDate, endDate, err := parseDateRange(req)
+			startDate, endDate, err := parseDateRange(req, time.UTC)
 
 			if tt.wantErr {
 				// Expect error
@@ -165,6 +168,194 @@ func TestParseDateRange(t *testing.T) {
 	}
 }
 
+func TestParseDateRange_DaylightSaving(t *testing.T) {
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Europe switches on the last Sunday of March and October at 01:00 UTC,
+	// the US on the second Sunday of March and first Sunday of November
+	tests := []struct {
+		name       string
+		loc        *time.Location
+		start, end string
+		wantStart  time.Time
+		wantDayEnd time.Time // start of the day after end
+		wantHours  float64
+		wantErr    bool
+	}{
+		{
+			name:       "day before spring forward",
+			loc:        helsinki,
+			start:      "2026-03-28",
+			end:        "2026-03-28",
+			wantStart:  time.Date(2026, 3, 27, 22, 0, 0, 0, time.UTC),
+			wantDayEnd: time.Date(2026, 3, 28, 22, 0, 0, 0, time.UTC),
+			wantHours:  24,
+		},
+		{
+			name:       "spring forward day has 23 hours",
+			loc:        helsinki,
+			start:      "2026-03-29",
+			end:        "2026-03-29",
+			wantStart:  time.Date(2026, 3, 28, 22, 0, 0, 0, time.UTC),
+			wantDayEnd: time.Date(2026, 3, 29, 21, 0, 0, 0, time.UTC),
+			wantHours:  23,
+		},
+		{
+			name:       "day after spring forward",
+			loc:        helsinki,
+			start:      "2026-03-30",
+			end:        "2026-03-30",
+			wantStart:  time.Date(2026, 3, 29, 21, 0, 0, 0, time.UTC),
+			wantDayEnd: time.Date(2026, 3, 30, 21, 0, 0, 0, time.UTC),
+			wantHours:  24,
+		},
+		{
+			name:       "fall back day has 25 hours",
+			loc:        helsinki,
+			start:      "2026-10-25",
+			end:        "2026-10-25",
+			wantStart:  time.Date(2026, 10, 24, 21, 0, 0, 0, time.UTC),
+			wantDayEnd: time.Date(2026, 10, 25, 22, 0, 0, 0, time.UTC),
+			wantHours:  25,
+		},
+		{
+			name:       "week across fall back",
+			loc:        helsinki,
+			start:      "2026-10-22",
+			end:        "2026-10-28",
+			wantStart:  time.Date(2026, 10, 21, 21, 0, 0, 0, time.UTC),
+			wantDayEnd: time.Date(2026, 10, 28, 22, 0, 0, 0, time.UTC),
+			wantHours:  7*24 + 1,
+		},
+		{
+			name:       "60 days across fall back",
+			loc:        helsinki,
+			start:      "2026-09-01",
+			end:        "2026-10-31",
+			wantStart:  time.Date(2026, 8, 31, 21, 0, 0, 0, time.UTC),
+			wantDayEnd: time.Date(2026, 10, 31, 22, 0, 0, 0, time.UTC),
+			wantHours:  61*24 + 1,
+		},
+		{
+			name:    "61 days across fall back",
+			loc:     helsinki,
+			start:   "2026-09-01",
+			end:     "2026-11-01",
+			wantErr: true,
+		},
+		{
+			name:       "60 days across spring forward",
+			loc:        helsinki,
+			start:      "2026-03-01",
+			end:        "2026-04-30",
+			wantStart:  time.Date(2026, 2, 28, 22, 0, 0, 0, time.UTC),
+			wantDayEnd: time.Date(2026, 4, 30, 21, 0, 0, 0, time.UTC),
+			wantHours:  61*24 - 1,
+		},
+		{
+			name:       "us spring forward day",
+			loc:        newYork,
+			start:      "2026-03-08",
+			end:        "2026-03-08",
+			wantStart:  time.Date(2026, 3, 8, 5, 0, 0, 0, time.UTC),
+			wantDayEnd: time.Date(2026, 3, 9, 4, 0, 0, 0, time.UTC),
+			wantHours:  23,
+		},
+		{
+			name:       "us fall back day",
+			loc:        newYork,
+			start:      "2026-11-01",
+			end:        "2026-11-01",
+			wantStart:  time.Date(2026, 11, 1, 4, 0, 0, 0, time.UTC),
+			wantDayEnd: time.Date(2026, 11, 2, 5, 0, 0, 0, time.UTC),
+			wantHours:  25,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/reservations?start="+tt.start+"&end="+tt.end, nil)
+
+			startDate, endDate, err := parseDateRange(req, tt.loc)
+
+			if tt.wantErr {
+				var valErr *validator.ValidationError
+				if !errors.As(err, &valErr) {
+					t.Fatalf("expected ValidationError, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+
+			// The services query up to the start of the day after end
+			dayEnd := endDate.AddDate(0, 0, 1)
+			if !startDate.Equal(tt.wantStart) {
+				t.Errorf("expected start %v, got %v", tt.wantStart, startDate.UTC())
+			}
+			if !dayEnd.Equal(tt.wantDayEnd) {
+				t.Errorf("expected range end %v, got %v", tt.wantDayEnd, dayEnd.UTC())
+			}
+			if got := dayEnd.Sub(startDate).Hours(); got != tt.wantHours {
+				t.Errorf("expected range of %v hours, got %v", tt.wantHours, got)
+			}
+		})
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    string
+		wantErr bool
+	}{
+		{name: "falls back to the campus time zone", query: "", want: "Europe/Helsinki"},
+		{name: "tz param", query: "tz=America/New_York", want: "America/New_York"},
+		{name: "utc", query: "tz=UTC", want: "UTC"},
+		{name: "unknown zone", query: "tz=Mars/Olympus_Mons", wantErr: true},
+		{name: "server local zone", query: "tz=Local", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/reservations?"+tt.query, nil)
+
+			loc, err := parseLocation(req, helsinki)
+
+			if tt.wantErr {
+				var valErr *validator.ValidationError
+				if !errors.As(err, &valErr) {
+					t.Fatalf("expected ValidationError, got: %v", err)
+				}
+				if _, ok := valErr.Fields["tz"]; !ok {
+					t.Errorf("expected error on field tz, got %v", valErr.Fields)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if loc.String() != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, loc)
+			}
+		})
+	}
+}
+
 func TestParseReservationID(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -275,3 +466,241 @@ func TestParseReservationID(t *testing.T) {
 		})
 	}
 }
+
+func TestParseAuditFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		export     bool
+		wantErr    bool
+		errorField string
+		expected   audit.Filter
+	}{
+		{
+			name:     "defaults",
+			query:    "",
+			expected: audit.Filter{Limit: defaultAuditLimit},
+		},
+		{
+			name:  "all filters with dates",
+			query: "userId=7&roomId=2&action=reservation.cancel&from=2026-02-01&to=2026-02-28&limit=50&offset=100",
+			expected: audit.Filter{
+				UserID: 7,
+				RoomID: 2,
+				Action: "reservation.cancel",
+				From:   time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+				To:     time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+				Limit:  50,
+				Offset: 100,
+			},
+		},
+		{
+			name:  "rfc3339 timestamps",
+			query: "from=2026-02-01T08:00:00Z&to=2026-02-01T12:00:00Z",
+			expected: audit.Filter{
+				From:  time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC),
+				To:    time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC),
+				Limit: defaultAuditLimit,
+			},
+		},
+		{
+			name:  "rfc3339 offsets normalized to utc",
+			query: "from=2026-02-01T10:00:00%2B02:00&to=2026-02-01T09:00:00-03:00",
+			expected: audit.Filter{
+				From:  time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC),
+				To:    time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC),
+				Limit: defaultAuditLimit,
+			},
+		},
+		{
+			name:     "export ignores limit",
+			query:    "limit=5",
+			export:   true,
+			expected: audit.Filter{Limit: maxAuditExport},
+		},
+		{
+			name:       "invalid user id",
+			query:      "userId=abc",
+			wantErr:    true,
+			errorField: "userId",
+		},
+		{
+			name:       "limit too large",
+			query:      "limit=5000",
+			wantErr:    true,
+			errorField: "limit",
+		},
+		{
+			name:       "to before from",
+			query:      "from=2026-02-10&to=2026-02-01",
+			wantErr:    true,
+			errorField: "to",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit?"+tt.query, nil)
+
+			filter, err := parseAuditFilter(req, time.UTC, tt.export)
+
+			if tt.wantErr {
+				var validationErr *validator.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("expected validation error, got %v", err)
+				}
+				if _, ok := validationErr.Fields[tt.errorField]; !ok {
+					t.Errorf("expected error on field %s, got %v", tt.errorField, validationErr.Fields)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if filter != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, filter)
+			}
+		})
+	}
+}
+
+func TestParseUserListQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantErr    bool
+		errorField string
+		expected   service.ListUsersInput
+	}{
+		{
+			name:     "defaults",
+			expected: service.ListUsersInput{Limit: defaultUserPageSize},
+		},
+		{
+			name:  "all filters",
+			query: "q=jdoe&role=STAFF&status=suspended&limit=10&offset=20",
+			expected: service.ListUsersInput{
+				Search: "jdoe",
+				Role:   "STAFF",
+				Status: service.UserStatusSuspended,
+				Limit:  10,
+				Offset: 20,
+			},
+		},
+		{
+			name:       "unknown role",
+			query:      "role=GUEST",
+			wantErr:    true,
+			errorField: "role",
+		},
+		{
+			name:       "unknown status",
+			query:      "status=deleted",
+			wantErr:    true,
+			errorField: "status",
+		},
+		{
+			name:       "negative offset",
+			query:      "offset=-1",
+			wantErr:    true,
+			errorField: "offset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/users?"+tt.query, nil)
+
+			input, err := parseUserListQuery(req)
+
+			if tt.wantErr {
+				var validationErr *validator.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("expected validation error, got %v", err)
+				}
+				if _, ok := validationErr.Fields[tt.errorField]; !ok {
+					t.Errorf("expected error on field %s, got %v", tt.errorField, validationErr.Fields)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if input != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, input)
+			}
+		})
+	}
+}
+
+func TestParseOpeningHours(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         dto.OpeningHoursRequest
+		wantErr     bool
+		errorField  string
+		wantOpen    int
+		wantClosing int
+	}{
+		{
+			name: "closed day ignores times",
+			req:  dto.OpeningHoursRequest{Closed: true, Open: "junk"},
+		},
+		{
+			name:        "shortened day",
+			req:         dto.OpeningHoursRequest{Open: "08:00", Close: "14:30"},
+			wantOpen:    480,
+			wantClosing: 870,
+		},
+		{
+			name:        "open until midnight",
+			req:         dto.OpeningHoursRequest{Open: "06:00", Close: "24:00"},
+			wantOpen:    360,
+			wantClosing: 1440,
+		},
+		{
+			name:        "open overnight",
+			req:         dto.OpeningHoursRequest{Open: "18:00", Close: "02:00"},
+			wantOpen:    1080,
+			wantClosing: 1560,
+		},
+		{
+			name:       "missing opening time",
+			req:        dto.OpeningHoursRequest{Close: "14:00"},
+			wantErr:    true,
+			errorField: "open",
+		},
+		{
+			name:       "invalid closing time",
+			req:        dto.OpeningHoursRequest{Open: "08:00", Close: "2pm"},
+			wantErr:    true,
+			errorField: "close",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open, closing, err := parseOpeningHours(tt.req)
+
+			if tt.wantErr {
+				var validationErr *validator.ValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("expected validation error, got %v", err)
+				}
+				if _, ok := validationErr.Fields[tt.errorField]; !ok {
+					t.Errorf("expected error on field %s, got %v", tt.errorField, validationErr.Fields)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+			if open != tt.wantOpen || closing != tt.wantClosing {
+				t.Errorf("expected %d-%d, got %d-%d", tt.wantOpen, tt.wantClosing, open, closing)
+			}
+		})
+	}
+}
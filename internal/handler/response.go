@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/IbnBaqqi/book-me/internal/oauth"
 	"github.com/IbnBaqqi/book-me/internal/service"
@@ -77,6 +79,9 @@ func handleError(w http.ResponseWriter, err error) {
 	// Check for oauth errors, log 5xx errors
 	var oauthErr *oauth.OauthError
 	if errors.As(err, &oauthErr) {
+		if oauthErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(oauthErr.RetryAfter.Seconds()))))
+		}
 		if oauthErr.StatusCode >= 500 {
 			slog.Error("oauth error",
 				"error", oauthErr,
@@ -0,0 +1,179 @@
+// Package health runs dependency checks in the background for the readiness
+// probe and the health report, so probes never call dependencies directly.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Check statuses
+const (
+	StatusUnknown   = "unknown"   // not checked yet
+	StatusHealthy   = "healthy"   // last check passed
+	StatusDegraded  = "degraded"  // last check of an optional dependency failed
+	StatusUnhealthy = "unhealthy" // last check of a critical dependency failed
+)
+
+// Check is a dependency check. Critical checks must pass for the server to
+// be ready, others only degrade the report.
+type Check struct {
+	Name     string
+	Critical bool
+	Run      func(ctx context.Context) error
+}
+
+// Result is the outcome of the last run of a check, with the last error it
+// failed with, even when it has passed since.
+type Result struct {
+	Name        string
+	Critical    bool
+	Status      string
+	Latency     time.Duration
+	CheckedAt   time.Time
+	LastError   string
+	LastErrorAt time.Time
+	Failures    int // consecutive failed runs
+}
+
+// Report is the cached state of every check.
+type Report struct {
+	Ready    bool
+	Draining bool
+	Checks   []Result
+}
+
+// Checker runs its checks every interval and caches their results. It is
+// safe for concurrent use.
+type Checker struct {
+	checks   []Check
+	interval time.Duration
+	timeout  time.Duration
+
+	mu       sync.RWMutex
+	results  []Result // in the order of checks
+	draining bool
+}
+
+// NewChecker creates a checker running each check with timeout every
+// interval, once Run is called.
+func NewChecker(interval, timeout time.Duration, checks ...Check) *Checker {
+	results := make([]Result, len(checks))
+	for i, check := range checks {
+		results[i] = Result{Name: check.Name, Critical: check.Critical, Status: StatusUnknown}
+	}
+	return &Checker{
+		checks:   checks,
+		interval: interval,
+		timeout:  timeout,
+		results:  results,
+	}
+}
+
+// Run checks every dependency right away and then every interval, until ctx
+// is done.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.Refresh(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Refresh runs every check once, concurrently, and caches the results.
+func (c *Checker) Refresh(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Go(func() {
+			checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Run(checkCtx)
+			c.record(i, start, time.Since(start), err)
+		})
+	}
+	wg.Wait()
+}
+
+// record stores the outcome of a run of check i, logging when its status
+// changes.
+func (c *Checker) record(i int, at time.Time, latency time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := c.results[i]
+	previous := result.Status
+	result.CheckedAt = at
+	result.Latency = latency
+	switch {
+	case err == nil:
+		result.Status = StatusHealthy
+		result.Failures = 0
+	case result.Critical:
+		result.Status = StatusUnhealthy
+	default:
+		result.Status = StatusDegraded
+	}
+	if err != nil {
+		result.LastError = err.Error()
+		result.LastErrorAt = at
+		result.Failures++
+	}
+	c.results[i] = result
+
+	if result.Status != previous {
+		if err != nil {
+			slog.Warn("health check failing", "check", result.Name, "status", result.Status, "error", err)
+		} else if previous != StatusUnknown {
+			slog.Info("health check recovered", "check", result.Name)
+		}
+	}
+}
+
+// Drain marks the server as shutting down, so it stops being ready and
+// load balancers send traffic elsewhere.
+func (c *Checker) Drain() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.draining = true
+}
+
+// Ready reports whether the server should receive traffic: it is not
+// draining and every critical check passed its last run.
+func (c *Checker) Ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.ready()
+}
+
+func (c *Checker) ready() bool {
+	if c.draining {
+		return false
+	}
+	for _, result := range c.results {
+		if result.Critical && result.Status != StatusHealthy {
+			return false
+		}
+	}
+	return true
+}
+
+// Report returns the cached results of every check.
+func (c *Checker) Report() Report {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return Report{
+		Ready:    c.ready(),
+		Draining: c.draining,
+		Checks:   append([]Result(nil), c.results...),
+	}
+}
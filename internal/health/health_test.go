@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChecker_Readiness(t *testing.T) {
+	var databaseDown, calendarDown atomic.Bool
+	check := func(down *atomic.Bool, name string) func(context.Context) error {
+		return func(context.Context) error {
+			if down.Load() {
+				return errors.New(name + " unreachable")
+			}
+			return nil
+		}
+	}
+
+	c := NewChecker(time.Minute, time.Second,
+		Check{Name: "database", Critical: true, Run: check(&databaseDown, "database")},
+		Check{Name: "calendar", Run: check(&calendarDown, "calendar")},
+	)
+	if c.Ready() {
+		t.Fatal("expected not ready before the first check")
+	}
+
+	c.Refresh(context.Background())
+	if !c.Ready() {
+		t.Fatalf("expected ready, got %+v", c.Report())
+	}
+
+	calendarDown.Store(true)
+	c.Refresh(context.Background())
+	report := c.Report()
+	if !report.Ready || report.Checks[1].Status != StatusDegraded {
+		t.Errorf("expected ready with the calendar degraded, got %+v", report)
+	}
+
+	databaseDown.Store(true)
+	c.Refresh(context.Background())
+	report = c.Report()
+	if report.Ready || report.Checks[0].Status != StatusUnhealthy || report.Checks[0].LastError != "database unreachable" {
+		t.Errorf("expected not ready with the database unhealthy, got %+v", report)
+	}
+
+	databaseDown.Store(false)
+	calendarDown.Store(false)
+	c.Refresh(context.Background())
+	report = c.Report()
+	if !report.Ready {
+		t.Errorf("expected ready once the database is back, got %+v", report)
+	}
+	database := report.Checks[0]
+	if database.Failures != 0 || database.LastError != "database unreachable" || database.LastErrorAt.IsZero() {
+		t.Errorf("expected the last error kept after recovering, got %+v", database)
+	}
+
+	c.Drain()
+	if report := c.Report(); report.Ready || !report.Draining {
+		t.Errorf("expected not ready while draining, got %+v", report)
+	}
+}
+
+func TestChecker_Timeout(t *testing.T) {
+	c := NewChecker(time.Minute, 10*time.Millisecond,
+		Check{Name: "database", Critical: true, Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}},
+	)
+
+	c.Refresh(context.Background())
+	result := c.Report().Checks[0]
+	if result.Status != StatusUnhealthy || result.Failures != 1 {
+		t.Errorf("expected a timed out check to fail, got %+v", result)
+	}
+	if result.Latency < 10*time.Millisecond {
+		t.Errorf("expected the latency of the timed out check, got %v", result.Latency)
+	}
+}
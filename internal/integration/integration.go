@@ -0,0 +1,231 @@
+// Package integration holds optional integrations, like Google Calendar and
+// SMTP, that the server can run without. An integration that is disabled or
+// can't be built is replaced by a null implementation, and work for it is
+// queued in memory until it is back.
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/tracing"
+)
+
+// ErrUnavailable is returned by null implementations, and wrapped by real
+// ones failing for a reason that may pass, like a network error. Jobs
+// failing with it are queued instead of dropped.
+var ErrUnavailable = errors.New("integration unavailable")
+
+// Status of an integration.
+type Status string
+
+// Integration statuses.
+const (
+	StatusAvailable   Status = "available"
+	StatusDisabled    Status = "disabled"    // turned off in the configuration
+	StatusUnavailable Status = "unavailable" // enabled but failed to start
+)
+
+// State is an integration's status as reported in health checks.
+type State struct {
+	Status Status `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	Queued int    `json:"queued"`
+}
+
+// Available returns the state of a working integration.
+func Available() State {
+	return State{Status: StatusAvailable}
+}
+
+// Disabled returns the state of an integration turned off in the configuration.
+func Disabled() State {
+	return State{Status: StatusDisabled, Reason: "disabled in configuration"}
+}
+
+// Unavailable returns the state of an integration that failed to start.
+func Unavailable(err error) State {
+	return State{Status: StatusUnavailable, Reason: err.Error()}
+}
+
+// DefaultMaxQueued is how many jobs are kept while an integration is off.
+const DefaultMaxQueued = 1000
+
+// jobTimeout bounds each queued job once the integration is back.
+const jobTimeout = 40 * time.Second
+
+// Job is work for an integration, run with its implementation.
+type Job[T any] func(ctx context.Context, impl T) error
+
+type queued[T any] struct {
+	name string
+	ctx  context.Context
+	run  Job[T]
+}
+
+// Optional holds the current implementation of an integration, swapped by
+// Set when it is turned on or off. It is safe for concurrent use.
+type Optional[T any] struct {
+	name      string
+	maxQueued int
+
+	mu    sync.RWMutex
+	impl  T
+	state State
+	queue []queued[T]
+}
+
+// NewOptional creates an integration running impl, which is a null
+// implementation unless state is available.
+func NewOptional[T any](name string, impl T, state State) *Optional[T] {
+	return &Optional[T]{
+		name:      name,
+		maxQueued: DefaultMaxQueued,
+		impl:      impl,
+		state:     state,
+	}
+}
+
+// Get returns the current implementation.
+func (o *Optional[T]) Get() T {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.impl
+}
+
+// State returns the status of the integration and its queued jobs.
+func (o *Optional[T]) State() State {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	state := o.state
+	state.Queued = len(o.queue)
+	return state
+}
+
+// Err returns why the integration is off, nil while it is available.
+func (o *Optional[T]) Err() error {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if o.state.Status == StatusAvailable {
+		return nil
+	}
+	return fmt.Errorf("%s %s: %s", o.name, o.state.Status, o.state.Reason)
+}
+
+// Set swaps the implementation. When the integration becomes available,
+// the queued jobs are run in the background.
+func (o *Optional[T]) Set(impl T, state State) {
+	o.swap(impl, state, "")
+}
+
+// Retry delivers the jobs queued while the integration was available, which
+// failed because it was unavailable for a moment, or builds an integration
+// that failed to start again and swaps it in once it works. A disabled
+// integration is left alone.
+func (o *Optional[T]) Retry(build func() (T, State)) {
+	o.mu.Lock()
+	status, impl := o.state.Status, o.impl
+	var jobs []queued[T]
+	if status == StatusAvailable {
+		jobs, o.queue = o.queue, nil
+	}
+	o.mu.Unlock()
+
+	switch status {
+	case StatusAvailable:
+		if len(jobs) > 0 {
+			slog.Info("delivering queued jobs", "integration", o.name, "jobs", len(jobs))
+			go o.deliver(impl, jobs)
+		}
+	case StatusUnavailable:
+		impl, state := build()
+		if state.Status == StatusAvailable {
+			// Unless it was turned off or on by a reload meanwhile
+			o.swap(impl, state, StatusUnavailable)
+		}
+	}
+}
+
+// swap replaces the implementation, only while the integration has the
+// status only when it is set, and delivers the queue once it is available.
+func (o *Optional[T]) swap(impl T, state State, only Status) {
+	o.mu.Lock()
+	if only != "" && o.state.Status != only {
+		o.mu.Unlock()
+		return
+	}
+	o.impl = impl
+	o.state = state
+	var jobs []queued[T]
+	if state.Status == StatusAvailable {
+		jobs, o.queue = o.queue, nil
+	}
+	o.mu.Unlock()
+
+	slog.Info("integration changed", "integration", o.name, "status", state.Status, "reason", state.Reason)
+	if len(jobs) > 0 {
+		slog.Info("delivering queued jobs", "integration", o.name, "jobs", len(jobs))
+		go o.deliver(impl, jobs)
+	}
+}
+
+// Do runs the job with the current implementation. While the integration
+// is off, the job is queued and Do returns nil. Jobs failing because the
+// integration is unavailable are queued too, and delivered by Retry.
+func (o *Optional[T]) Do(ctx context.Context, name string, job Job[T]) error {
+	o.mu.Lock()
+	impl, available := o.impl, o.state.Status == StatusAvailable
+	if !available {
+		// Queued under the same lock as the check, so Set can't deliver
+		// the queue in between
+		o.enqueue(queued[T]{name: name, ctx: tracing.Detach(ctx), run: job})
+	}
+	o.mu.Unlock()
+	if !available {
+		return nil
+	}
+
+	err := job(ctx, impl)
+	if !errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	o.mu.Lock()
+	o.enqueue(queued[T]{name: name, ctx: tracing.Detach(ctx), run: job})
+	o.mu.Unlock()
+	return nil
+}
+
+// enqueue keeps the job for later, dropping the oldest one when the queue
+// is full. o.mu must be held.
+func (o *Optional[T]) enqueue(job queued[T]) {
+	if len(o.queue) >= o.maxQueued {
+		slog.Warn("integration queue full, dropping oldest job",
+			"integration", o.name, "job", o.queue[0].name)
+		o.queue = o.queue[1:]
+	}
+	o.queue = append(o.queue, job)
+	slog.Info("integration unavailable, job queued", "integration", o.name, "job", job.name, "queued", len(o.queue))
+}
+
+// deliver runs queued jobs one at a time. Jobs failing because the
+// integration went off again are queued again.
+func (o *Optional[T]) deliver(impl T, jobs []queued[T]) {
+	for _, job := range jobs {
+		ctx, cancel := context.WithTimeout(job.ctx, jobTimeout)
+		err := job.run(ctx, impl)
+		cancel()
+
+		switch {
+		case errors.Is(err, ErrUnavailable):
+			o.mu.Lock()
+			o.enqueue(job)
+			o.mu.Unlock()
+		case err != nil:
+			slog.Error("queued job failed", "integration", o.name, "job", job.name, "error", err)
+		}
+	}
+}
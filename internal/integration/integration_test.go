@@ -0,0 +1,177 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// notifier records the messages it sends, failing while it is unavailable.
+type notifier struct {
+	available bool
+
+	mu   sync.Mutex
+	sent []string
+}
+
+func (n *notifier) Send(message string) error {
+	if !n.available {
+		return ErrUnavailable
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, message)
+	return nil
+}
+
+func (n *notifier) Sent() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.sent...)
+}
+
+func send(message string) Job[*notifier] {
+	return func(_ context.Context, n *notifier) error {
+		return n.Send(message)
+	}
+}
+
+func TestOptional_QueuesUntilAvailable(t *testing.T) {
+	ctx := context.Background()
+	o := NewOptional("notifier", &notifier{}, Disabled())
+
+	for _, message := range []string{"first", "second"} {
+		if err := o.Do(ctx, "send", send(message)); err != nil {
+			t.Fatalf("expected the job to be queued, got %v", err)
+		}
+	}
+	if state := o.State(); state.Status != StatusDisabled || state.Queued != 2 {
+		t.Fatalf("expected 2 jobs queued while disabled, got %+v", state)
+	}
+
+	working := &notifier{available: true}
+	o.Set(working, Available())
+
+	deadline := time.Now().Add(time.Second)
+	for len(working.Sent()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if sent := working.Sent(); len(sent) != 2 || sent[0] != "first" || sent[1] != "second" {
+		t.Fatalf("expected queued jobs delivered in order, got %v", sent)
+	}
+
+	if err := o.Do(ctx, "send", send("third")); err != nil {
+		t.Fatal(err)
+	}
+	if sent := working.Sent(); len(sent) != 3 || o.State().Queued != 0 {
+		t.Errorf("expected the job to run right away, got %v", sent)
+	}
+}
+
+func TestOptional_JobErrors(t *testing.T) {
+	o := NewOptional("notifier", &notifier{available: true}, Available())
+
+	failure := errors.New("smtp timeout")
+	err := o.Do(context.Background(), "send", func(context.Context, *notifier) error { return failure })
+	if !errors.Is(err, failure) {
+		t.Errorf("expected the job error, got %v", err)
+	}
+	if queued := o.State().Queued; queued != 0 {
+		t.Errorf("expected failed jobs not to be queued, got %d", queued)
+	}
+
+	// A null implementation swapped in while the job was being started
+	err = o.Do(context.Background(), "send", func(context.Context, *notifier) error { return ErrUnavailable })
+	if err != nil || o.State().Queued != 1 {
+		t.Errorf("expected the job queued, got %v and %d queued", err, o.State().Queued)
+	}
+}
+
+func TestOptional_DropsOldestWhenFull(t *testing.T) {
+	o := NewOptional("notifier", &notifier{}, Unavailable(errors.New("bad credentials")))
+	o.maxQueued = 2
+
+	for _, message := range []string{"first", "second", "third"} {
+		_ = o.Do(context.Background(), "send "+message, send(message))
+	}
+
+	state := o.State()
+	if state.Status != StatusUnavailable || state.Reason != "bad credentials" || state.Queued != 2 {
+		t.Errorf("unexpected state %+v", state)
+	}
+	if o.queue[0].name != "send second" {
+		t.Errorf("expected the oldest job dropped, got %q first", o.queue[0].name)
+	}
+}
+
+// waitSent waits for the notifier to have sent n messages.
+func waitSent(n *notifier, count int) []string {
+	deadline := time.Now().Add(time.Second)
+	for len(n.Sent()) < count && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	return n.Sent()
+}
+
+func TestOptional_RetryStartsFailedIntegration(t *testing.T) {
+	o := NewOptional("notifier", &notifier{}, Unavailable(errors.New("smtp unreachable")))
+	if err := o.Do(context.Background(), "send", send("queued")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Still failing, nothing changes
+	o.Retry(func() (*notifier, State) {
+		return &notifier{}, Unavailable(errors.New("smtp unreachable"))
+	})
+	if state := o.State(); state.Status != StatusUnavailable || state.Queued != 1 {
+		t.Fatalf("expected still unavailable with the job queued, got %+v", state)
+	}
+
+	working := &notifier{available: true}
+	o.Retry(func() (*notifier, State) { return working, Available() })
+	if sent := waitSent(working, 1); len(sent) != 1 || sent[0] != "queued" {
+		t.Fatalf("expected the queued job delivered, got %v", sent)
+	}
+	if state := o.State(); state.Status != StatusAvailable {
+		t.Errorf("expected available, got %+v", state)
+	}
+}
+
+func TestOptional_RetryLeavesDisabledAlone(t *testing.T) {
+	o := NewOptional("notifier", &notifier{}, Disabled())
+	o.Retry(func() (*notifier, State) {
+		t.Fatal("a disabled integration must not be built")
+		return nil, State{}
+	})
+	if o.State().Status != StatusDisabled {
+		t.Errorf("expected disabled, got %+v", o.State())
+	}
+}
+
+func TestOptional_RetryRedeliversWhileAvailable(t *testing.T) {
+	working := &notifier{available: true}
+	o := NewOptional("notifier", working, Available())
+
+	// Fails once as if the integration was briefly unavailable
+	attempts := 0
+	err := o.Do(context.Background(), "send", func(ctx context.Context, n *notifier) error {
+		attempts++
+		if attempts == 1 {
+			return ErrUnavailable
+		}
+		return n.Send("retried")
+	})
+	if err != nil || o.State().Queued != 1 {
+		t.Fatalf("expected the job queued, got %v and %+v", err, o.State())
+	}
+
+	o.Retry(func() (*notifier, State) {
+		t.Fatal("an available integration must not be built")
+		return nil, State{}
+	})
+	if sent := waitSent(working, 1); len(sent) != 1 || sent[0] != "retried" {
+		t.Fatalf("expected the job retried, got %v", sent)
+	}
+}
@@ -2,6 +2,7 @@
 package logger
 
 import (
+	"io"
 	"log/slog"
 	"os"
 	"strings"
@@ -37,20 +38,27 @@ func (l *RetryLogger) Warn(msg string, keysAndValues ...interface{}) {
 
 // Init creates the global structured logger based on configuration
 func Init(c *config.LoggerConfig, env string) {
+	InitWriter(os.Stdout, c, env)
+}
+
+// InitWriter is Init writing to w, like standard error for commands
+// printing their output on standard output.
+func InitWriter(w io.Writer, c *config.LoggerConfig, env string) {
 	var handler slog.Handler
 
 	level := parseLogLevel(c.Level)
 
 	opts := &slog.HandlerOptions{
-		Level:     level,
-		AddSource: level == slog.LevelDebug || level == slog.LevelError,
+		Level:       level,
+		AddSource:   level == slog.LevelDebug || level == slog.LevelError,
+		ReplaceAttr: Redact,
 	}
 
 	// Use text handler in dev, JSON in prod
 	if env == "dev" {
-		handler = slog.NewTextHandler(os.Stdout, opts)
+		handler = slog.NewTextHandler(w, opts)
 	} else {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(w, opts)
 	}
 
 	Log = slog.New(handler)
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"log/slog"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Redacted replaces secrets in log output.
+const Redacted = "[REDACTED]"
+
+var (
+	// emailPattern matches email addresses anywhere in a string
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+
+	// tokenPattern matches JWTs, personal access and device tokens
+	// and bearer credentials
+	tokenPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]+\.[A-Za-z0-9_\-]*|bm_(pat|dev)_[A-Za-z0-9_\-]+|(?i:bearer)\s+[A-Za-z0-9._~+/\-]+=*`)
+)
+
+// sensitiveKeys are attribute and query param names whose values are never logged.
+var sensitiveKeys = []string{"token", "authorization", "password", "secret", "cookie", "code", "state"}
+
+// Redact is a slog ReplaceAttr function hiding tokens and email addresses.
+// Attributes named like a secret are replaced as a whole, other string and
+// error values have tokens and emails masked.
+func Redact(_ []string, a slog.Attr) slog.Attr {
+	if isSensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, RedactString(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, RedactString(err.Error()))
+		}
+	}
+	return a
+}
+
+// RedactString masks tokens and email addresses in s.
+func RedactString(s string) string {
+	s = tokenPattern.ReplaceAllString(s, Redacted)
+	return emailPattern.ReplaceAllStringFunc(s, maskEmail)
+}
+
+// RedactURL returns the path and query of u with sensitive query params
+// hidden and tokens and emails masked in the rest.
+func RedactURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return RedactString(u.Path)
+	}
+
+	query := u.Query()
+	for key := range query {
+		if isSensitive(key) {
+			query[key] = []string{Redacted}
+		}
+	}
+	return RedactString(u.Path + "?" + query.Encode())
+}
+
+// maskEmail keeps the first letter and the domain: j***@example.com.
+func maskEmail(email string) string {
+	local, domain, _ := strings.Cut(email, "@")
+	return local[:1] + "***@" + domain
+}
+
+// isSensitive reports whether the key names a secret, judged by its last
+// word, so access_token is sensitive but token_id is not.
+func isSensitive(key string) bool {
+	words := strings.FieldsFunc(strings.ToLower(key), func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	})
+	if len(words) == 0 {
+		return false
+	}
+	return slices.Contains(sensitiveKeys, words[len(words)-1])
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"errors"
+	"log/slog"
+	"net/url"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name     string
+		attr     slog.Attr
+		expected string
+	}{
+		{
+			name:     "sensitive key",
+			attr:     slog.String("access_token", "abc"),
+			expected: Redacted,
+		},
+		{
+			name:     "sensitive header key",
+			attr:     slog.String("Authorization", "Basic abc"),
+			expected: Redacted,
+		},
+		{
+			name:     "key ending in other word is kept",
+			attr:     slog.String("token_id", "42"),
+			expected: "42",
+		},
+		{
+			name:     "email in value",
+			attr:     slog.String("msg", "sent to jane.doe@example.com"),
+			expected: "sent to j***@example.com",
+		},
+		{
+			name:     "jwt in value",
+			attr:     slog.String("detail", "got eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.sig"),
+			expected: "got " + Redacted,
+		},
+		{
+			name:     "personal access token in value",
+			attr:     slog.String("detail", "using bm_pat_abc123"),
+			expected: "using " + Redacted,
+		},
+		{
+			name:     "bearer credential in error",
+			attr:     slog.Any("error", errors.New("rejected Bearer abc.def")),
+			expected: "rejected " + Redacted,
+		},
+		{
+			name:     "plain value",
+			attr:     slog.String("room", "Big Room"),
+			expected: "Big Room",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Redact(nil, tt.attr)
+			if got.Value.String() != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got.Value.String())
+			}
+		})
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	u, _ := url.Parse("/oauth/callback?code=abc&state=xyz&day=2026-01-01")
+
+	got := RedactURL(u)
+	expected := "/oauth/callback?code=%5BREDACTED%5D&day=2026-01-01&state=%5BREDACTED%5D"
+	if got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,190 @@
+// Package metrics defines the Prometheus metrics of the server and the
+// /metrics handler exposing them.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "bookme"
+
+// Label values of the metrics below.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDenied  = "denied"
+
+	JobCalendar = "calendar"
+	JobEmail    = "email"
+
+	ConflictOverlap  = "overlap"
+	ConflictBlackout = "blackout"
+
+	CancelOwner       = "owner"
+	CancelModerator   = "moderator"
+	CancelBlackout    = "blackout"
+	CancelUserRemoved = "user_removed"
+)
+
+// Registry holds all metrics of the server, along with the Go runtime and
+// process collectors.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// HTTPRequests counts handled requests by route pattern and status.
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration observes request latencies by route pattern and status.
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latencies by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// ReservationsCreated counts bookings made.
+	ReservationsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reservations_created_total",
+		Help:      "Reservations created.",
+	})
+
+	// ReservationConflicts counts bookings refused because the slot was
+	// taken or the room blacked out.
+	ReservationConflicts = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reservation_conflicts_total",
+		Help:      "Reservations refused by conflict reason.",
+	}, []string{"reason"})
+
+	// ReservationsCancelled counts cancelled bookings by who or what
+	// cancelled them.
+	ReservationsCancelled = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reservations_cancelled_total",
+		Help:      "Reservations cancelled by reason.",
+	}, []string{"reason"})
+
+	// RateLimited counts requests rejected by a rate limiter.
+	RateLimited = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejections_total",
+		Help:      "Requests rejected by rate limiter.",
+	}, []string{"limiter"})
+
+	// Jobs counts background calendar and email jobs by outcome.
+	Jobs = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "jobs_total",
+		Help:      "Background calendar and email jobs by outcome.",
+	}, []string{"job", "outcome"})
+
+	// JobRetries counts retried attempts of background jobs.
+	JobRetries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "job_retries_total",
+		Help:      "Retried attempts of background calendar and email jobs.",
+	}, []string{"job"})
+
+	// OAuthLogins counts login callbacks by provider and outcome.
+	OAuthLogins = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "oauth_logins_total",
+		Help:      "OAuth logins by provider and outcome.",
+	}, []string{"provider", "outcome"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// RegisterDB exposes the connection pool stats of db.
+func RegisterDB(db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, namespace))
+}
+
+// JobDone counts a finished background job as a success or failure.
+func JobDone(job string, err error) {
+	outcome := OutcomeSuccess
+	if err != nil {
+		outcome = OutcomeFailure
+	}
+	Jobs.WithLabelValues(job, outcome).Inc()
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records the count and latency of requests. It must wrap the
+// ServeMux directly so the matched route pattern can be read from the
+// request afterwards, unmatched requests are labelled "unmatched" to keep
+// the number of series bounded.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r)
+
+		route := routeOf(r.Pattern)
+		status := strconv.Itoa(rec.status)
+		HTTPRequests.WithLabelValues(r.Method, route, status).Inc()
+		HTTPDuration.WithLabelValues(r.Method, route, status).Observe(time.Since(start).Seconds())
+	})
+}
+
+// routeOf strips the method from a ServeMux pattern like "GET /rooms/{id}".
+func routeOf(pattern string) string {
+	if pattern == "" {
+		return "unmatched"
+	}
+	if _, path, ok := strings.Cut(pattern, " "); ok {
+		return path
+	}
+	return pattern
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
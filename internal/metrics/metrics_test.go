@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rooms/{id}", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	mux.HandleFunc("GET /ok", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})
+	handler := Middleware(mux)
+
+	tests := []struct {
+		name   string
+		path   string
+		route  string
+		status string
+	}{
+		{"route pattern instead of path", "/rooms/42", "/rooms/{id}", "418"},
+		{"implicit 200", "/ok", "/ok", "200"},
+		{"unmatched path", "/nope/123", "unmatched", "404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := HTTPRequests.WithLabelValues(http.MethodGet, tt.route, tt.status)
+			before := testutil.ToFloat64(counter)
+
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if got := testutil.ToFloat64(counter); got != before+1 {
+				t.Errorf("expected request counted under %s %s, got %v after %v", tt.route, tt.status, got, before)
+			}
+		})
+	}
+}
+
+func TestJobDone(t *testing.T) {
+	success := Jobs.WithLabelValues(JobEmail, OutcomeSuccess)
+	failure := Jobs.WithLabelValues(JobEmail, OutcomeFailure)
+	beforeSuccess, beforeFailure := testutil.ToFloat64(success), testutil.ToFloat64(failure)
+
+	JobDone(JobEmail, nil)
+	JobDone(JobEmail, errors.New("smtp down"))
+	JobDone(JobEmail, errors.New("smtp down"))
+
+	if got := testutil.ToFloat64(success) - beforeSuccess; got != 1 {
+		t.Errorf("expected 1 success, got %v", got)
+	}
+	if got := testutil.ToFloat64(failure) - beforeFailure; got != 2 {
+		t.Errorf("expected 2 failures, got %v", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	ReservationsCreated.Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	for _, name := range []string{"bookme_reservations_created_total", "go_goroutines"} {
+		if !strings.Contains(w.Body.String(), name) {
+			t.Errorf("expected %s in output", name)
+		}
+	}
+}
@@ -3,12 +3,13 @@ package middleware
 import (
 	"log/slog"
 	"net/http"
-	"strconv"
 
 	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
 )
 
-// Authenticate extracts and validates JWT token, adding user to context if valid.
+// Authenticate extracts and validates the JWT or personal access token,
+// adding user to context if valid.
 // Allows request to continue even without valid token for public endpoints.
 func Authenticate(authService *auth.Service) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -20,29 +21,15 @@ func Authenticate(authService *auth.Service) func(http.Handler) http.Handler {
 				return
 			}
 
-			claims, err := authService.VerifyAccessToken(tokenStr)
+			user, err := authService.Authenticate(r.Context(), tokenStr)
 			if err != nil {
 				slog.Warn("invalid auth token", "path", r.URL.Path, "error", err.Error())
 				next.ServeHTTP(w, r)
 				return
 			}
 
-			// Parse user ID from claims
-			id, err := strconv.ParseInt(claims.Subject, 10, 64)
-			if err != nil {
-				slog.Error("invalid user ID in token", "subject", claims.Subject, "error", err.Error())
-				next.ServeHTTP(w, r)
-				return
-			}
-
-			user := auth.User{
-				ID:   id,
-				Role: claims.Role,
-				Name: claims.Name,
-			}
-
 			slog.Debug("authenticated request", "user_id", user.ID, "role", user.Role, "path", r.URL.Path)
-			ctx := auth.WithUser(r.Context(), user)
+			ctx := auth.WithUser(SetUser(r.Context(), user.ID), user)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -64,3 +51,23 @@ func RequireAuth(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// RequirePermission ensures the authenticated user holds the permission,
+// returning 401 if not authenticated and 403 if the permission is missing.
+// Room scoped grants pass this check, the room itself is checked by the service.
+func RequirePermission(p rbac.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, _ := auth.UserFromContext(r.Context())
+			if !user.Has(p) {
+				slog.Warn("forbidden access attempt", "user_id", user.ID, "permission", p, "path", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusForbidden)
+				_, _ = w.Write([]byte(`{"error":"forbidden"}`))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
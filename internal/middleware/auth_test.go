@@ -7,11 +7,12 @@ import (
 
 	"github.com/IbnBaqqi/book-me/internal/auth"
 	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
 )
 
 func TestAuthenticate(t *testing.T) {
 	// Create auth service
-	authService := auth.NewService("test-secret-key")
+	authService := auth.NewService("test-secret-key", nil)
 
 	// Create a test user and token
 	testUser := database.User{
@@ -150,7 +151,7 @@ func TestRequireAuth(t *testing.T) {
 
 func TestAuthenticateAndRequireAuth(t *testing.T) {
 	// Create auth service
-	authService := auth.NewService("test-secret-key")
+	authService := auth.NewService("test-secret-key", nil)
 
 	// Create a test user and token
 	testUser := database.User{
@@ -200,3 +201,56 @@ func TestAuthenticateAndRequireAuth(t *testing.T) {
 		}
 	})
 }
+
+func TestRequirePermission(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	wrappedHandler := RequirePermission(rbac.RoomsManage)(handler)
+
+	tests := []struct {
+		name     string
+		user     *auth.User
+		expected int
+	}{
+		{
+			name:     "blocks unauthenticated request",
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "forbids user without permission",
+			user:     &auth.User{ID: 1, Role: "STUDENT", Grants: rbac.Grants{{Role: rbac.Student}}},
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "allows user with global permission",
+			user:     &auth.User{ID: 2, Role: "STAFF", Grants: rbac.Grants{{Role: rbac.Staff}}},
+			expected: http.StatusOK,
+		},
+		{
+			name: "allows user with room scoped permission",
+			user: &auth.User{ID: 3, Role: "STUDENT", Grants: rbac.Grants{
+				{Role: rbac.Student},
+				{Role: rbac.RoomManager, RoomID: 1},
+			}},
+			expected: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.user != nil {
+				req = req.WithContext(auth.WithUser(req.Context(), *tt.user))
+			}
+			w := httptest.NewRecorder()
+
+			wrappedHandler.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+}
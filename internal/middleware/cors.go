@@ -1,45 +1,230 @@
 package middleware
 
 import (
+	"log/slog"
 	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
-var allowedOrigins = []string{
-	"http://localhost:5173",
-	"https://booking-calendar-chi.vercel.app",
-	"https://*.hive.fi",
-	"https://*.jgengo.dev",
+// CorsOptions configures the CORS policy.
+type CorsOptions struct {
+	// Origins are exact origins like https://app.example.com or patterns
+	// with a wildcard leftmost label like https://*.example.com, which
+	// matches subdomains of example.com only.
+	Origins        []string
+	Methods        []string
+	Headers        []string // request headers clients may send
+	ExposedHeaders []string // response headers scripts may read
+	MaxAge         time.Duration
 }
 
-func matchesOrigin(origin, pattern string) bool {
-	if !strings.Contains(pattern, "*") {
-		return origin == pattern
+// originPattern is a parsed origin. A wildcard pattern matches hosts that
+// end in "." followed by host, so it can't be matched by suffix tricks like
+// https://evilexample.com.
+type originPattern struct {
+	scheme   string
+	host     string
+	port     string
+	wildcard bool
+}
+
+// parseOriginPattern parses scheme://host[:port] with an optional "*."
+// in front of the host.
+func parseOriginPattern(pattern string) (originPattern, bool) {
+	scheme, rest, ok := strings.Cut(strings.ToLower(strings.TrimSpace(pattern)), "://")
+	if !ok || scheme == "" || rest == "" {
+		return originPattern{}, false
+	}
+
+	wildcard := false
+	if after, found := strings.CutPrefix(rest, "*."); found {
+		wildcard = true
+		rest = after
+	}
+	if strings.ContainsAny(rest, "*/?#@") {
+		return originPattern{}, false
+	}
+
+	u, err := url.Parse(scheme + "://" + rest)
+	if err != nil || u.Hostname() == "" {
+		return originPattern{}, false
 	}
-	parts := strings.SplitN(pattern, "*", 2)
-	return strings.HasPrefix(origin, parts[0]) && strings.HasSuffix(origin, parts[1])
+
+	return originPattern{
+		scheme:   u.Scheme,
+		host:     u.Hostname(),
+		port:     u.Port(),
+		wildcard: wildcard,
+	}, true
+}
+
+// matches reports whether the request origin is allowed by the pattern.
+func (p originPattern) matches(origin originPattern) bool {
+	if origin.scheme != p.scheme || origin.port != p.port || origin.wildcard {
+		return false
+	}
+	if !p.wildcard {
+		return origin.host == p.host
+	}
+	sub, ok := strings.CutSuffix(origin.host, "."+p.host)
+	return ok && sub != "" && !strings.HasPrefix(sub, ".") && !strings.HasSuffix(sub, ".")
+}
+
+// Cors is a middleware applying a CORS policy that can be replaced while
+// serving. Besides the configured origins it allows the ones returned by
+// campusOrigins, which is called on every request so campus changes apply
+// without a restart. It may be nil.
+//
+// Responses to allowed origins carry the credentials and exposed headers.
+// Preflight requests are answered directly, with 204 when the origin,
+// method and headers are allowed and 403 otherwise. Invalid origin patterns
+// are logged and ignored.
+type Cors struct {
+	mu            sync.RWMutex
+	policy        corsPolicy
+	campusOrigins func() []string
+}
+
+// corsPolicy is CorsOptions parsed for matching requests.
+type corsPolicy struct {
+	origins       []originPattern
+	methods       []string
+	headers       []string
+	allowMethods  string
+	allowHeaders  string
+	exposeHeaders string
+	maxAge        string
+}
+
+// NewCors creates the CORS middleware.
+func NewCors(opts CorsOptions, campusOrigins func() []string) *Cors {
+	c := &Cors{campusOrigins: campusOrigins}
+	c.SetOptions(opts)
+	return c
+}
+
+// SetOptions replaces the policy, requests already being handled keep the
+// previous one.
+func (c *Cors) SetOptions(opts CorsOptions) {
+	methods := make([]string, 0, len(opts.Methods))
+	for _, m := range opts.Methods {
+		methods = append(methods, strings.ToUpper(strings.TrimSpace(m)))
+	}
+	headers := make([]string, 0, len(opts.Headers))
+	for _, h := range opts.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(strings.TrimSpace(h)))
+	}
+
+	policy := corsPolicy{
+		origins:       parseOriginPatterns(opts.Origins, true),
+		methods:       methods,
+		headers:       headers,
+		allowMethods:  strings.Join(methods, ", "),
+		allowHeaders:  strings.Join(headers, ", "),
+		exposeHeaders: strings.Join(opts.ExposedHeaders, ", "),
+		maxAge:        strconv.Itoa(int(opts.MaxAge.Seconds())),
+	}
+
+	c.mu.Lock()
+	c.policy = policy
+	c.mu.Unlock()
 }
 
-// Cors ia a middleware to handle cors policy
-func Cors(next http.Handler) http.Handler {
+// Handler applies the policy to requests before passing them to next.
+func (c *Cors) Handler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.mu.RLock()
+		policy := c.policy
+		c.mu.RUnlock()
+
 		origin := r.Header.Get("Origin")
-		for _, allowed := range allowedOrigins {
-			if matchesOrigin(origin, allowed) {
-				w.Header().Set("Access-Control-Allow-Origin", origin)
-				break
-			}
-		}
+		w.Header().Add("Vary", "Origin")
 
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		w.Header().Set("Access-Control-Allow-Credentials", "true")
-		w.Header().Set("Access-Control-Max-Age", "43200") // 12 hours
+		requestMethod := r.Header.Get("Access-Control-Request-Method")
+		if r.Method == http.MethodOptions && origin != "" && requestMethod != "" {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
 
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
+			if !c.allowed(policy, origin) ||
+				!slices.Contains(policy.methods, requestMethod) ||
+				!headersAllowed(r.Header.Values("Access-Control-Request-Headers"), policy.headers) {
+				slog.Debug("cors preflight rejected", "origin", origin, "method", requestMethod)
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			w.Header().Set("Access-Control-Allow-Methods", policy.allowMethods)
+			w.Header().Set("Access-Control-Allow-Headers", policy.allowHeaders)
+			w.Header().Set("Access-Control-Max-Age", policy.maxAge)
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
+
+		if c.allowed(policy, origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			if policy.exposeHeaders != "" {
+				w.Header().Set("Access-Control-Expose-Headers", policy.exposeHeaders)
+			}
+		}
 		next.ServeHTTP(w, r)
 	})
 }
+
+// allowed reports whether the origin matches the policy or a campus origin.
+func (c *Cors) allowed(policy corsPolicy, origin string) bool {
+	if origin == "" {
+		return false
+	}
+	o, ok := parseOriginPattern(origin)
+	if !ok {
+		return false
+	}
+	if slices.ContainsFunc(policy.origins, func(p originPattern) bool { return p.matches(o) }) {
+		return true
+	}
+	if c.campusOrigins == nil {
+		return false
+	}
+	return slices.ContainsFunc(parseOriginPatterns(c.campusOrigins(), false), func(p originPattern) bool {
+		return p.matches(o)
+	})
+}
+
+// parseOriginPatterns parses the valid patterns, logging invalid ones when
+// warn is set.
+func parseOriginPatterns(patterns []string, warn bool) []originPattern {
+	parsed := make([]originPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		p, ok := parseOriginPattern(pattern)
+		if !ok {
+			if warn {
+				slog.Warn("invalid cors origin pattern, ignoring", "pattern", pattern)
+			}
+			continue
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed
+}
+
+// headersAllowed reports whether every header of the comma-separated
+// Access-Control-Request-Headers values is allowed.
+func headersAllowed(requested []string, allowed []string) bool {
+	for _, value := range requested {
+		for _, h := range strings.Split(value, ",") {
+			h = strings.TrimSpace(h)
+			if h != "" && !slices.Contains(allowed, http.CanonicalHeaderKey(h)) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,219 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMatchesOrigin(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		origin   string
+		expected bool
+	}{
+		{"https://app.example.com", "https://app.example.com", true},
+		{"https://app.example.com", "http://app.example.com", false},
+		{"https://app.example.com", "https://app.example.com:8443", false},
+		{"http://localhost:5173", "http://localhost:5173", true},
+		{"http://localhost:5173", "http://localhost:5174", false},
+		{"https://*.hive.fi", "https://booking.hive.fi", true},
+		{"https://*.hive.fi", "https://a.b.hive.fi", true},
+		{"https://*.hive.fi", "https://HIVE.booking.hive.fi", true},
+		{"https://*.hive.fi", "https://hive.fi", false},
+		{"https://*.hive.fi", "https://evilhive.fi", false},
+		{"https://*.hive.fi", "https://booking.hive.fi.evil.com", false},
+		{"https://*.hive.fi", "https://evil.com/.hive.fi", false},
+		{"https://*.hive.fi", "https://evil.com?.hive.fi", false},
+		{"https://*.hive.fi", "https://x@booking.hive.fi", false},
+		{"https://*.hive.fi", "http://booking.hive.fi", false},
+		{"https://*.hive.fi", "https://*.hive.fi", false},
+		{"https://*.hive.fi", "null", false},
+		{"https://*hive.fi", "https://evilhive.fi", false},
+		{"https://app.*.fi", "https://app.hive.fi", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.origin, func(t *testing.T) {
+			pattern, _ := parseOriginPattern(tt.pattern)
+			origin, ok := parseOriginPattern(tt.origin)
+			got := ok && pattern.host != "" && pattern.matches(origin)
+			if got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCors(t *testing.T) {
+	cors := NewCors(CorsOptions{
+		Origins:        []string{"https://*.hive.fi"},
+		Methods:        []string{"GET", "POST", "PUT", "DELETE"},
+		Headers:        []string{"Content-Type", "Authorization"},
+		ExposedHeaders: []string{"X-Total-Count", "RateLimit-Remaining"},
+		MaxAge:         12 * time.Hour,
+	}, func() []string { return []string{"https://campus.example.com"} })
+
+	called := false
+	handler := cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name            string
+		method          string
+		origin          string
+		requestMethod   string
+		requestHeaders  string
+		expectedStatus  int
+		expectedAllowed bool
+		expectedCalled  bool
+	}{
+		{
+			name:            "request from allowed origin",
+			method:          http.MethodGet,
+			origin:          "https://booking.hive.fi",
+			expectedStatus:  http.StatusOK,
+			expectedAllowed: true,
+			expectedCalled:  true,
+		},
+		{
+			name:            "request from campus origin",
+			method:          http.MethodGet,
+			origin:          "https://campus.example.com",
+			expectedStatus:  http.StatusOK,
+			expectedAllowed: true,
+			expectedCalled:  true,
+		},
+		{
+			name:           "request from other origin gets no cors headers",
+			method:         http.MethodGet,
+			origin:         "https://evilhive.fi",
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+		},
+		{
+			name:            "allowed preflight",
+			method:          http.MethodOptions,
+			origin:          "https://booking.hive.fi",
+			requestMethod:   http.MethodPut,
+			requestHeaders:  "content-type, authorization",
+			expectedStatus:  http.StatusNoContent,
+			expectedAllowed: true,
+		},
+		{
+			name:           "preflight from other origin",
+			method:         http.MethodOptions,
+			origin:         "https://evilhive.fi",
+			requestMethod:  http.MethodGet,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "preflight with disallowed method",
+			method:         http.MethodOptions,
+			origin:         "https://booking.hive.fi",
+			requestMethod:  http.MethodPatch,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "preflight with disallowed header",
+			method:         http.MethodOptions,
+			origin:         "https://booking.hive.fi",
+			requestMethod:  http.MethodPost,
+			requestHeaders: "Content-Type, X-Evil",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "options without preflight headers is passed on",
+			method:         http.MethodOptions,
+			expectedStatus: http.StatusOK,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called = false
+			req := httptest.NewRequest(tt.method, "/api/v1/rooms", nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.requestMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			}
+			if tt.requestHeaders != "" {
+				req.Header.Set("Access-Control-Request-Headers", tt.requestHeaders)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if called != tt.expectedCalled {
+				t.Errorf("expected handler called %v, got %v", tt.expectedCalled, called)
+			}
+			if w.Header().Values("Vary")[0] != "Origin" {
+				t.Errorf("expected Vary: Origin, got %v", w.Header().Values("Vary"))
+			}
+
+			allowOrigin := w.Header().Get("Access-Control-Allow-Origin")
+			credentials := w.Header().Get("Access-Control-Allow-Credentials")
+			if tt.expectedAllowed {
+				if allowOrigin != tt.origin || credentials != "true" {
+					t.Errorf("expected origin %s allowed with credentials, got %q %q", tt.origin, allowOrigin, credentials)
+				}
+			} else if allowOrigin != "" || credentials != "" {
+				t.Errorf("expected no cors headers, got %q %q", allowOrigin, credentials)
+			}
+
+			if tt.expectedAllowed && tt.method == http.MethodOptions {
+				if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST, PUT, DELETE" {
+					t.Errorf("unexpected allowed methods %q", got)
+				}
+				if got := w.Header().Get("Access-Control-Max-Age"); got != "43200" {
+					t.Errorf("expected max age 43200, got %q", got)
+				}
+			}
+			if tt.expectedAllowed && tt.method != http.MethodOptions {
+				if got := w.Header().Get("Access-Control-Expose-Headers"); got != "X-Total-Count, RateLimit-Remaining" {
+					t.Errorf("unexpected exposed headers %q", got)
+				}
+			}
+		})
+	}
+}
+
+func TestCors_SetOptions(t *testing.T) {
+	cors := NewCors(CorsOptions{
+		Origins: []string{"https://old.example.com"},
+		Methods: []string{"GET"},
+	}, nil)
+	handler := cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	allowed := func(origin string) bool {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/rooms", nil)
+		req.Header.Set("Origin", origin)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Header().Get("Access-Control-Allow-Origin") == origin
+	}
+
+	if !allowed("https://old.example.com") || allowed("https://new.example.com") {
+		t.Fatal("expected only the old origin before the update")
+	}
+
+	cors.SetOptions(CorsOptions{
+		Origins: []string{"https://new.example.com"},
+		Methods: []string{"GET"},
+	})
+
+	if allowed("https://old.example.com") || !allowed("https://new.example.com") {
+		t.Error("expected only the new origin after the update")
+	}
+}
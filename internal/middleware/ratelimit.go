@@ -2,85 +2,96 @@
 package middleware
 
 import (
+	"fmt"
 	"log/slog"
-	"net"
 	"net/http"
-	"strings"
+	"strconv"
 	"sync"
 	"time"
 
-	"golang.org/x/time/rate"
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/clientip"
+	"github.com/IbnBaqqi/book-me/internal/metrics"
+	"github.com/IbnBaqqi/book-me/internal/ratelimit"
 )
 
-// RateLimiter manages per-IP rate limiting.
+// RateLimiter limits requests per authenticated user, or per client IP for
+// anonymous requests. Its state lives in a ratelimit.Store, so replicas
+// sharing a store share the limit.
 type RateLimiter struct {
-	mu              sync.Mutex
-	visitors        map[string]*visitor
-	rate            rate.Limit
-	burst           int
-	cleanupInterval time.Duration
-	lastCleanup     time.Time
-	trustProxy      bool
+	name  string
+	store ratelimit.Store
+	ips   *clientip.Resolver
+
+	mu       sync.RWMutex
+	limit    ratelimit.Limit
+	policies []RateLimitPolicy
 }
 
-type visitor struct {
-	limiter  *rate.Limiter
-	lastSeen time.Time
+// RateLimitPolicy replaces the default limit for requests matching a route
+// pattern, like "POST /api/v1/reservations", the role of the user, or both.
+// Requests matching a route policy are counted apart from other routes.
+type RateLimitPolicy struct {
+	Route string
+	Role  string
+	Limit ratelimit.Limit
 }
 
 // NewRateLimiter creates a new rate limiter.
-//   - r: requests per second
-//   - b: maximum burst size
-//   - trustProxy: whether to trust X-Forwarded-For / X-Real-IP headers
-func NewRateLimiter(r rate.Limit, b int, trustProxy bool) *RateLimiter {
+//   - name: label of the limiter in the rejection metrics, also keeps the
+//     keys of limiters sharing a store apart
+//   - store: where the limiter state is kept
+//   - limit: the limit of requests matching no policy
+//   - ips: resolves the client IP of anonymous requests
+//   - policies: the most specific matching policy applies, a route and role
+//     policy before a route policy before a role policy
+func NewRateLimiter(name string, store ratelimit.Store, limit ratelimit.Limit, ips *clientip.Resolver, policies ...RateLimitPolicy) *RateLimiter {
 	return &RateLimiter{
-		visitors:        make(map[string]*visitor),
-		rate:            r,
-		burst:           b,
-		cleanupInterval: 3 * time.Minute,
-		lastCleanup:     time.Now(),
-		trustProxy:      trustProxy,
+		name:     name,
+		store:    store,
+		limit:    limit,
+		policies: policies,
+		ips:      ips,
 	}
 }
 
-// getVisitor returns the rate limiter for the given IP,
-// creating one if it doesn't exist. Cleans up stale entries inline.
-func (rl *RateLimiter) getVisitor(ip string) *rate.Limiter {
-	now := time.Now()
-
+// SetLimits replaces the default limit and the policies. Counts already
+// kept in the store carry over to the new limits.
+func (rl *RateLimiter) SetLimits(limit ratelimit.Limit, policies ...RateLimitPolicy) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
-
-	// Inline cleanup of stale visitors.
-	if now.Sub(rl.lastCleanup) > rl.cleanupInterval {
-		for k, v := range rl.visitors {
-			if now.Sub(v.lastSeen) > rl.cleanupInterval {
-				delete(rl.visitors, k)
-			}
-		}
-		rl.lastCleanup = now
-	}
-
-	v, exists := rl.visitors[ip]
-	if !exists {
-		limiter := rate.NewLimiter(rl.rate, rl.burst)
-		rl.visitors[ip] = &visitor{limiter: limiter, lastSeen: now}
-		return limiter
-	}
-
-	v.lastSeen = now
-	return v.limiter
+	rl.limit = limit
+	rl.policies = policies
 }
 
-// Limit is the middleware that enforces rate limiting.
+// Limit is the middleware that enforces rate limiting. Users are only known
+// when it runs after Authenticate, and routes when it runs inside the mux.
+// Every response carries the RateLimit-* headers, rejected ones a
+// Retry-After computed from the limiter state. When the store is
+// unavailable requests are let through.
 func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		ip := rl.getIP(r)
-		limiter := rl.getVisitor(ip)
+		client, role := rl.client(r)
+		policy := rl.policyFor(r.Pattern, role)
+
+		key := rl.name + ":" + client
+		if policy.Route != "" {
+			key = rl.name + ":" + policy.Route + ":" + client
+		}
+
+		result, err := rl.store.Allow(r.Context(), key, policy.Limit)
+		if err != nil {
+			slog.Error("rate limit store unavailable", "limiter", rl.name, "error", err)
+			next.ServeHTTP(w, r)
+			return
+		}
 
-		if !limiter.Allow() {
-			slog.Warn("rate limit exceeded", "ip", ip, "method", r.Method, "path", r.URL.Path)
-			w.Header().Set("Retry-After", "6")
+		setRateLimitHeaders(w.Header(), policy.Limit, result)
+
+		if !result.Allowed {
+			slog.Warn("rate limit exceeded", "limiter", rl.name, "client", client, "method", r.Method, "path", r.URL.Path)
+			metrics.RateLimited.WithLabelValues(rl.name).Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
 			http.Error(w, "Rate limit exceeded. Please try again later.", http.StatusTooManyRequests)
 			return
 		}
@@ -89,23 +100,55 @@ func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
 	})
 }
 
-// getIP extracts the client IP address from the request.
-func (rl *RateLimiter) getIP(r *http.Request) string {
-	if rl.trustProxy {
-		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-			parts := strings.SplitN(xff, ",", 2)
-			if ip := strings.TrimSpace(parts[0]); ip != "" {
-				return ip
-			}
-		}
-		if ip := r.Header.Get("X-Real-IP"); ip != "" {
-			return strings.TrimSpace(ip)
+// client returns the key identifying who sent the request, with the role
+// of authenticated users.
+func (rl *RateLimiter) client(r *http.Request) (key, role string) {
+	if user, ok := auth.UserFromContext(r.Context()); ok {
+		switch {
+		case user.ID != 0:
+			return "user:" + strconv.FormatInt(user.ID, 10), user.Role
+		case user.DeviceID != 0:
+			return "device:" + strconv.FormatInt(user.DeviceID, 10), user.Role
 		}
 	}
+	return "ip:" + rl.ips.ClientIP(r), ""
+}
 
-	ip, _, err := net.SplitHostPort(r.RemoteAddr)
-	if err != nil {
-		return r.RemoteAddr
+// policyFor returns the most specific policy for the route and role,
+// falling back to the default limit.
+func (rl *RateLimiter) policyFor(route, role string) RateLimitPolicy {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	best, bestScore := RateLimitPolicy{Limit: rl.limit}, 0
+	for _, p := range rl.policies {
+		if (p.Route != "" && p.Route != route) || (p.Role != "" && p.Role != role) {
+			continue
+		}
+		score := 0
+		if p.Route != "" {
+			score += 2
+		}
+		if p.Role != "" {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
 	}
-	return ip
+	return best
+}
+
+// setRateLimitHeaders writes the RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers of the IETF draft.
+func setRateLimitHeaders(h http.Header, limit ratelimit.Limit, result ratelimit.Result) {
+	h.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.ResetAfter)))
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Burst, ceilSeconds(limit.Window())))
+}
+
+// ceilSeconds rounds d up to whole seconds, at least one.
+func ceilSeconds(d time.Duration) int {
+	return max(int((d+time.Second-1)/time.Second), 1)
 }
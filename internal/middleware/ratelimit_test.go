@@ -1,16 +1,21 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
-	"golang.org/x/time/rate"
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/metrics"
+	"github.com/IbnBaqqi/book-me/internal/ratelimit"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestRateLimiter(t *testing.T) {
 	// Create a rate limiter: 2 requests per second, burst of 2, no proxy trust
-	limiter := NewRateLimiter(rate.Limit(2), 2, false)
+	limiter := NewRateLimiter("test", ratelimit.NewMemoryStore(), ratelimit.Limit{Rate: 2, Burst: 2}, nil)
 
 	// Create a simple handler
 	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -40,6 +45,15 @@ func TestRateLimiter(t *testing.T) {
 		if w.Code != http.StatusOK {
 			t.Errorf("expected status 200, got %d", w.Code)
 		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+			t.Errorf("expected RateLimit-Remaining 0, got %q", got)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("expected RateLimit-Limit 2, got %q", got)
+		}
+		if got := w.Header().Get("RateLimit-Policy"); got != "2;w=1" {
+			t.Errorf("expected RateLimit-Policy 2;w=1, got %q", got)
+		}
 	})
 
 	t.Run("blocks requests exceeding limit", func(t *testing.T) {
@@ -56,6 +70,7 @@ func TestRateLimiter(t *testing.T) {
 		}
 
 		// Next request should be rate limited
+		rejected := testutil.ToFloat64(metrics.RateLimited.WithLabelValues("test"))
 		w := httptest.NewRecorder()
 		limitedHandler.ServeHTTP(w, req)
 
@@ -63,6 +78,14 @@ func TestRateLimiter(t *testing.T) {
 			t.Errorf("expected status 429, got %d", w.Code)
 		}
 
+		if got := testutil.ToFloat64(metrics.RateLimited.WithLabelValues("test")); got != rejected+1 {
+			t.Errorf("expected rejection to be counted, got %v after %v", got, rejected)
+		}
+
+		if got := w.Header().Get("Retry-After"); got != "1" {
+			t.Errorf("expected Retry-After 1, got %q", got)
+		}
+
 		if w.Body.String() == "" {
 			t.Error("expected error message in response body")
 		}
@@ -88,90 +111,152 @@ func TestRateLimiter(t *testing.T) {
 	})
 }
 
-func TestGetIP(t *testing.T) {
+func TestRateLimiter_Policies(t *testing.T) {
+	limiter := NewRateLimiter("test", ratelimit.NewMemoryStore(), ratelimit.Limit{Rate: 1, Burst: 5}, nil,
+		RateLimitPolicy{Route: "POST /reservations", Limit: ratelimit.Limit{Rate: 1, Burst: 2}},
+		RateLimitPolicy{Role: "STAFF", Limit: ratelimit.Limit{Rate: 1, Burst: 20}},
+		RateLimitPolicy{Route: "POST /reservations", Role: "STAFF", Limit: ratelimit.Limit{Rate: 1, Burst: 4}},
+	)
+
+	mux := http.NewServeMux()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("GET /reservations", limiter.Limit(ok))
+	mux.Handle("POST /reservations", limiter.Limit(ok))
+
 	tests := []struct {
 		name          string
+		method        string
+		user          *auth.User
 		remoteAddr    string
-		xForwardedFor string
-		xRealIP       string
-		trustProxy    bool
-		expectedIP    string
+		expectedLimit string
 	}{
 		{
-			name:       "from RemoteAddr when not trusting proxy",
-			remoteAddr: "192.168.1.1:1234",
-			trustProxy: false,
-			expectedIP: "192.168.1.1",
-		},
-		{
-			name:       "ignores X-Real-IP when not trusting proxy",
-			remoteAddr: "192.168.1.1:1234",
-			xRealIP:    "10.0.0.1",
-			trustProxy: false,
-			expectedIP: "192.168.1.1",
-		},
-		{
-			name:          "ignores X-Forwarded-For when not trusting proxy",
-			remoteAddr:    "192.168.1.1:1234",
-			xForwardedFor: "10.0.0.2",
-			trustProxy:    false,
-			expectedIP:    "192.168.1.1",
-		},
-		{
-			name:       "from RemoteAddr when trusting proxy but no headers",
-			remoteAddr: "192.168.1.1:1234",
-			trustProxy: true,
-			expectedIP: "192.168.1.1",
+			name:          "default limit per ip",
+			method:        http.MethodGet,
+			remoteAddr:    "10.0.0.1:1234",
+			expectedLimit: "5",
 		},
 		{
-			name:       "from X-Real-IP when trusting proxy",
-			remoteAddr: "192.168.1.1:1234",
-			xRealIP:    "10.0.0.1",
-			trustProxy: true,
-			expectedIP: "10.0.0.1",
+			name:          "route policy",
+			method:        http.MethodPost,
+			user:          &auth.User{ID: 1, Role: "STUDENT"},
+			expectedLimit: "2",
 		},
 		{
-			name:          "from X-Forwarded-For when trusting proxy",
-			remoteAddr:    "192.168.1.1:1234",
-			xForwardedFor: "10.0.0.2",
-			trustProxy:    true,
-			expectedIP:    "10.0.0.2",
+			name:          "role policy",
+			method:        http.MethodGet,
+			user:          &auth.User{ID: 2, Role: "STAFF"},
+			expectedLimit: "20",
 		},
 		{
-			name:          "X-Forwarded-For takes precedence when trusting proxy",
-			remoteAddr:    "192.168.1.1:1234",
-			xForwardedFor: "10.0.0.2",
-			xRealIP:       "10.0.0.1",
-			trustProxy:    true,
-			expectedIP:    "10.0.0.2",
-		},
-		{
-			name:          "X-Forwarded-For with multiple IPs takes first",
-			remoteAddr:    "192.168.1.1:1234",
-			xForwardedFor: "10.0.0.2, 10.0.0.3, 10.0.0.4",
-			trustProxy:    true,
-			expectedIP:    "10.0.0.2",
+			name:          "route and role policy wins",
+			method:        http.MethodPost,
+			user:          &auth.User{ID: 2, Role: "STAFF"},
+			expectedLimit: "4",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			limiter := NewRateLimiter(rate.Limit(1), 1, tt.trustProxy)
-
-			req := httptest.NewRequest("GET", "/test", nil)
+			req := httptest.NewRequest(tt.method, "/reservations", nil)
 			req.RemoteAddr = tt.remoteAddr
-
-			if tt.xForwardedFor != "" {
-				req.Header.Set("X-Forwarded-For", tt.xForwardedFor)
-			}
-			if tt.xRealIP != "" {
-				req.Header.Set("X-Real-IP", tt.xRealIP)
+			if tt.user != nil {
+				req = req.WithContext(auth.WithUser(req.Context(), *tt.user))
 			}
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
 
-			ip := limiter.getIP(req)
-			if ip != tt.expectedIP {
-				t.Errorf("expected IP %s, got %s", tt.expectedIP, ip)
+			if got := w.Header().Get("RateLimit-Limit"); got != tt.expectedLimit {
+				t.Errorf("expected limit %s, got %s", tt.expectedLimit, got)
 			}
 		})
 	}
+
+	t.Run("users behind one ip have separate limits", func(t *testing.T) {
+		for id := int64(10); id < 13; id++ {
+			for range 2 {
+				req := httptest.NewRequest(http.MethodPost, "/reservations", nil)
+				req.RemoteAddr = "10.0.0.9:1234"
+				req = req.WithContext(auth.WithUser(req.Context(), auth.User{ID: id, Role: "STUDENT"}))
+				w := httptest.NewRecorder()
+				mux.ServeHTTP(w, req)
+				if w.Code != http.StatusOK {
+					t.Fatalf("user %d: expected status 200, got %d", id, w.Code)
+				}
+			}
+		}
+	})
+
+	t.Run("route policy is counted apart", func(t *testing.T) {
+		user := auth.User{ID: 20, Role: "STUDENT"}
+		send := func(method string) int {
+			req := httptest.NewRequest(method, "/reservations", nil)
+			req = req.WithContext(auth.WithUser(req.Context(), user))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			return w.Code
+		}
+
+		for range 2 {
+			send(http.MethodPost)
+		}
+		if code := send(http.MethodPost); code != http.StatusTooManyRequests {
+			t.Errorf("expected route limit to be exhausted, got %d", code)
+		}
+		if code := send(http.MethodGet); code != http.StatusOK {
+			t.Errorf("expected other routes to keep their limit, got %d", code)
+		}
+	})
+}
+
+func TestRateLimiter_SetLimits(t *testing.T) {
+	limiter := NewRateLimiter("test", ratelimit.NewMemoryStore(), ratelimit.Limit{Rate: 1, Burst: 1}, nil)
+	handler := limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	send()
+	if w := send(); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected second request limited, got %d", w.Code)
+	}
+
+	limiter.SetLimits(ratelimit.Limit{Rate: 1, Burst: 10})
+
+	w := send()
+	if w.Code != http.StatusOK {
+		t.Errorf("expected request allowed after raising the limit, got %d", w.Code)
+	}
+	if got := w.Header().Get("RateLimit-Limit"); got != "10" {
+		t.Errorf("expected RateLimit-Limit 10, got %q", got)
+	}
+}
+
+func TestRateLimiter_StoreUnavailable(t *testing.T) {
+	limiter := NewRateLimiter("test", failingStore{}, ratelimit.Limit{Rate: 1, Burst: 1}, nil)
+	handler := limiter.Limit(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/test", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected requests to pass when the store fails, got %d", w.Code)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Allow(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
 }
@@ -0,0 +1,146 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/clientip"
+	"github.com/IbnBaqqi/book-me/internal/logger"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in requests and responses.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLen = 64
+
+// Router finds the route pattern a request matches, *http.ServeMux is one.
+type Router interface {
+	Handler(r *http.Request) (h http.Handler, pattern string)
+}
+
+// requestInfo is filled in while a request is served and read by the
+// access log once it is done.
+type requestInfo struct {
+	userID int64
+}
+
+type requestInfoKey struct{}
+
+// RequestMeta prepares the context of every request and writes the access log:
+//   - A well-formed incoming request ID is kept, otherwise one is generated.
+//     The ID is echoed in the response.
+//   - The client IP, resolved by ips, and request ID are saved for the audit log.
+//   - A request-scoped logger carrying the request ID and route is saved for
+//     logger.FromContext, SetUser adds the user to it.
+//
+// Once the request is served, one access log line is written with tokens and
+// emails redacted.
+func RequestMeta(routes Router, ips *clientip.Resolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = uuid.NewString()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			ip := ips.ClientIP(r)
+
+			_, route := routes.Handler(r)
+			if route == "" {
+				route = "unmatched"
+			}
+
+			info := &requestInfo{}
+			log := slog.Default().With("request_id", requestID, "route", route)
+
+			ctx := audit.WithMeta(r.Context(), audit.Meta{
+				IP:        ip,
+				RequestID: requestID,
+			})
+			ctx = logger.WithContext(ctx, log)
+			ctx = context.WithValue(ctx, requestInfoKey{}, info)
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+
+			level := slog.LevelInfo
+			if rec.status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			attrs := []any{
+				"method", r.Method,
+				"path", logger.RedactURL(r.URL),
+				"status", rec.status,
+				"bytes", rec.bytes,
+				"duration_ms", time.Since(start).Milliseconds(),
+				"ip", ip,
+				"user_agent", r.UserAgent(),
+			}
+			if info.userID != 0 {
+				attrs = append(attrs, "user_id", info.userID)
+			}
+			log.Log(ctx, level, "request", attrs...)
+		})
+	}
+}
+
+// SetUser adds the authenticated user to the request-scoped logger and the
+// access log of the request.
+func SetUser(ctx context.Context, userID int64) context.Context {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.userID = userID
+	}
+	return logger.With(ctx, "user_id", userID)
+}
+
+// validRequestID accepts short IDs made of letters, digits, '-', '_' and '.'.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// responseRecorder captures the status code and size of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.status = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
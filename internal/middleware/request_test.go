@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/clientip"
+	"github.com/IbnBaqqi/book-me/internal/logger"
+)
+
+func TestRequestMeta(t *testing.T) {
+	tests := []struct {
+		name       string
+		requestID  string
+		keepsID    bool
+		remoteAddr string
+		trusted    []string
+		forwarded  string
+		expectedIP string
+	}{
+		{
+			name:       "keeps incoming request id",
+			requestID:  "abc-123",
+			keepsID:    true,
+			remoteAddr: "10.0.0.1:4242",
+			expectedIP: "10.0.0.1",
+		},
+		{
+			name:       "generates missing request id",
+			remoteAddr: "[2001:db8::1]:4242",
+			expectedIP: "2001:db8::1",
+		},
+		{
+			name:       "replaces malformed request id",
+			requestID:  "bad id\nwith newline",
+			remoteAddr: "10.0.0.1:4242",
+			expectedIP: "10.0.0.1",
+		},
+		{
+			name:       "resolves client behind trusted proxy",
+			remoteAddr: "10.0.0.1:4242",
+			trusted:    []string{"10.0.0.0/8"},
+			forwarded:  "203.0.113.7, 10.0.0.2",
+			expectedIP: "203.0.113.7",
+		},
+		{
+			name:       "replaces overlong request id",
+			requestID:  strings.Repeat("a", 100),
+			remoteAddr: "10.0.0.1:4242",
+			expectedIP: "10.0.0.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ips, err := clientip.NewResolver(tt.trusted)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var meta audit.Meta
+			handler := RequestMeta(http.NewServeMux(), ips)(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				meta = audit.MetaFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if meta.IP != tt.expectedIP {
+				t.Errorf("expected IP %s, got %s", tt.expectedIP, meta.IP)
+			}
+			if tt.keepsID && meta.RequestID != tt.requestID {
+				t.Errorf("expected request id %s, got %s", tt.requestID, meta.RequestID)
+			}
+			if !tt.keepsID && (meta.RequestID == "" || meta.RequestID == tt.requestID) {
+				t.Errorf("expected a generated request id, got %q", meta.RequestID)
+			}
+			if got := w.Header().Get(RequestIDHeader); got != meta.RequestID {
+				t.Errorf("expected response header %s, got %s", meta.RequestID, got)
+			}
+		})
+	}
+}
+
+func TestRequestMeta_AccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: logger.Redact})))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rooms/{id}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := SetUser(r.Context(), 42)
+		logger.FromContext(ctx).Info("handled", "email", "jane@example.com")
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("ok"))
+	})
+	handler := RequestMeta(mux, nil)(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/rooms/7?token=secret-value&day=2026-01-01", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 log lines, got %d: %s", len(lines), buf.String())
+	}
+
+	var handled, access map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &handled); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &access); err != nil {
+		t.Fatal(err)
+	}
+
+	if handled["request_id"] != "req-1" || handled["user_id"] != float64(42) {
+		t.Errorf("expected request id and user on handler log, got %v", handled)
+	}
+	if handled["email"] == "jane@example.com" {
+		t.Errorf("expected email to be redacted, got %v", handled["email"])
+	}
+
+	if access["msg"] != "request" {
+		t.Errorf("expected access log line, got %v", access["msg"])
+	}
+	if access["route"] != "GET /rooms/{id}" {
+		t.Errorf("expected route pattern, got %v", access["route"])
+	}
+	if access["status"] != float64(http.StatusTeapot) || access["bytes"] != float64(2) {
+		t.Errorf("expected status 418 and 2 bytes, got %v and %v", access["status"], access["bytes"])
+	}
+	if access["user_id"] != float64(42) {
+		t.Errorf("expected user_id 42, got %v", access["user_id"])
+	}
+	path, _ := access["path"].(string)
+	if strings.Contains(path, "secret-value") || !strings.Contains(path, "day=2026-01-01") {
+		t.Errorf("expected token redacted from path, got %s", path)
+	}
+}
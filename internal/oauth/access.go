@@ -0,0 +1,89 @@
+package oauth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/IbnBaqqi/book-me/internal/campus"
+	"github.com/IbnBaqqi/book-me/internal/service"
+)
+
+// AccessPolicy decides which 42 accounts may log in.
+// Empty campus, cursus and pool filters are not applied. The primary campus
+// must be registered as a BookMe campus in any case.
+type AccessPolicy struct {
+	CampusIDs  []int    // allowed primary intra campus IDs
+	CursusIDs  []int    // user must be enrolled in one of these cursus
+	PoolYears  []string // user's pool year must be one of these
+	PoolMonths []string // user's pool month must be one of these
+}
+
+// CursusUsers represents User42 cursus enrollment
+type CursusUsers struct {
+	CursusID int `json:"cursus_id"`
+}
+
+// check applies the campus, cursus and pool filters to the 42 user.
+func (p AccessPolicy) check(user42 *User42) error {
+	if len(p.CampusIDs) > 0 && !slices.Contains(p.CampusIDs, user42.PrimaryCampusID()) {
+		return ErrInvalidCampus
+	}
+
+	if len(p.CursusIDs) > 0 && !slices.ContainsFunc(user42.Cursus, func(c CursusUsers) bool {
+		return slices.Contains(p.CursusIDs, c.CursusID)
+	}) {
+		return ErrCursusNotAllowed
+	}
+
+	if len(p.PoolYears) > 0 && !slices.Contains(p.PoolYears, user42.PoolYear) {
+		return ErrPoolNotAllowed
+	}
+	if len(p.PoolMonths) > 0 && !slices.ContainsFunc(p.PoolMonths, func(m string) bool {
+		return strings.EqualFold(m, user42.PoolMonth)
+	}) {
+		return ErrPoolNotAllowed
+	}
+
+	return nil
+}
+
+// CheckAccess decides whether the 42 user may log in and returns the campus
+// the user belongs to, matched on the primary intra campus. An explicit
+// per-login rule managed by staff takes precedence over the configured
+// policy; users allowed by a rule whose campus is not registered are placed
+// on the default campus.
+func (p *Provider42) CheckAccess(ctx context.Context, user42 *User42) (*campus.Campus, error) {
+	home, registered := p.campuses.ByIntraID(user42.PrimaryCampusID())
+
+	rule, err := p.db.GetLoginAccessRule(ctx, service.NormalizeLogin(user42.Login))
+	switch {
+	case err == nil:
+		if rule.Effect == service.AccessDeny {
+			return nil, ErrAccountDenied
+		}
+		if !registered {
+			home = p.campuses.Default()
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		if !registered {
+			return nil, ErrInvalidCampus
+		}
+		p.mu.RLock()
+		policy := p.policy
+		p.mu.RUnlock()
+		if err := policy.check(user42); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("failed to get access rule: %w", err)
+	}
+
+	if !home.AllowsProvider(campus.Provider42) {
+		return nil, ErrProviderNotAllowed
+	}
+	return home, nil
+}
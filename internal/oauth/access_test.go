@@ -0,0 +1,88 @@
+package oauth
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAccessPolicy_Check(t *testing.T) {
+	hiveStudent := User42{
+		Login:     "jdoe",
+		PoolYear:  "2024",
+		PoolMonth: "september",
+		Campus:    []CampusUsers{{ID: 13, Primary: true}},
+		Cursus:    []CursusUsers{{CursusID: 9}, {CursusID: 21}},
+	}
+
+	tests := []struct {
+		name    string
+		policy  AccessPolicy
+		user42  User42
+		wantErr error
+	}{
+		{
+			name:   "allowed campus without other filters",
+			policy: AccessPolicy{CampusIDs: []int{13}},
+			user42: hiveStudent,
+		},
+		{
+			name:   "no campus filter",
+			policy: AccessPolicy{},
+			user42: hiveStudent,
+		},
+		{
+			name:    "campus not in allowlist",
+			policy:  AccessPolicy{CampusIDs: []int{1, 7}},
+			user42:  hiveStudent,
+			wantErr: ErrInvalidCampus,
+		},
+		{
+			name:   "allowed campus that is not primary",
+			policy: AccessPolicy{CampusIDs: []int{13}},
+			user42: User42{
+				Campus: []CampusUsers{{ID: 13, Primary: false}, {ID: 1, Primary: true}},
+			},
+			wantErr: ErrInvalidCampus,
+		},
+		{
+			name:   "enrolled in allowed cursus",
+			policy: AccessPolicy{CampusIDs: []int{13}, CursusIDs: []int{21}},
+			user42: hiveStudent,
+		},
+		{
+			name:    "not enrolled in allowed cursus",
+			policy:  AccessPolicy{CampusIDs: []int{13}, CursusIDs: []int{3}},
+			user42:  hiveStudent,
+			wantErr: ErrCursusNotAllowed,
+		},
+		{
+			name:   "matching pool year and month",
+			policy: AccessPolicy{CampusIDs: []int{13}, PoolYears: []string{"2023", "2024"}, PoolMonths: []string{"September"}},
+			user42: hiveStudent,
+		},
+		{
+			name:    "pool year not allowed",
+			policy:  AccessPolicy{CampusIDs: []int{13}, PoolYears: []string{"2025"}},
+			user42:  hiveStudent,
+			wantErr: ErrPoolNotAllowed,
+		},
+		{
+			name:    "pool month not allowed",
+			policy:  AccessPolicy{CampusIDs: []int{13}, PoolMonths: []string{"july"}},
+			user42:  hiveStudent,
+			wantErr: ErrPoolNotAllowed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.check(&tt.user42)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
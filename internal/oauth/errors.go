@@ -3,6 +3,7 @@ package oauth
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // OauthError represents an oauth error with a status code
@@ -12,6 +13,7 @@ type OauthError struct {
 	Err        error
 	Message    string
 	StatusCode int
+	RetryAfter time.Duration // set when the login is locked out
 }
 
 // Error implements the error interface
@@ -33,6 +35,7 @@ func (e *OauthError) Wrap(err error) *OauthError {
 		Err:        err,
 		Message:    e.Message,
 		StatusCode: e.StatusCode,
+		RetryAfter: e.RetryAfter,
 	}
 }
 
@@ -59,9 +62,33 @@ var (
 		StatusCode: http.StatusGatewayTimeout,
 	}
 	ErrInvalidCampus = &OauthError{
-		Message:    "access denied: only helsinki campus student allowed",
+		Message:    "access denied: your primary campus is not allowed to use BookMe",
 		StatusCode: http.StatusForbidden,
 	}
+	ErrProviderNotAllowed = &OauthError{
+		Message:    "access denied: this login method is not enabled for your campus",
+		StatusCode: http.StatusForbidden,
+	}
+	ErrCursusNotAllowed = &OauthError{
+		Message:    "access denied: your cursus is not allowed to use BookMe",
+		StatusCode: http.StatusForbidden,
+	}
+	ErrPoolNotAllowed = &OauthError{
+		Message:    "access denied: your pool is not allowed to use BookMe",
+		StatusCode: http.StatusForbidden,
+	}
+	ErrAccountDenied = &OauthError{
+		Message:    "access denied: your account has been blocked from BookMe by staff",
+		StatusCode: http.StatusForbidden,
+	}
+	ErrAccountSuspended = &OauthError{
+		Message:    "access denied: your account has been suspended by staff",
+		StatusCode: http.StatusForbidden,
+	}
+	ErrAccessCheckFailed = &OauthError{
+		Message:    "failed to check login access",
+		StatusCode: http.StatusInternalServerError,
+	}
 	ErrOAuthSessionFailed = &OauthError{
 		Message:    "failed to get session",
 		StatusCode: http.StatusInternalServerError,
@@ -78,4 +105,16 @@ var (
 		Message:    "invalid or missing state",
 		StatusCode: http.StatusForbidden,
 	}
+	ErrInvalidIDToken = &OauthError{
+		Message:    "invalid id token",
+		StatusCode: http.StatusUnauthorized,
+	}
+	ErrNonceMismatch = &OauthError{
+		Message:    "invalid or missing nonce",
+		StatusCode: http.StatusForbidden,
+	}
+	ErrLoginLocked = &OauthError{
+		Message:    "too many failed logins, try again later",
+		StatusCode: http.StatusTooManyRequests,
+	}
 )
@@ -2,14 +2,15 @@ package oauth
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/campus"
 	"github.com/IbnBaqqi/book-me/internal/database"
 	"github.com/IbnBaqqi/book-me/internal/logger"
 	"github.com/IbnBaqqi/book-me/internal/service"
@@ -20,10 +21,21 @@ import (
 
 // User42 represent the user data response from 42 user info endpoint
 type User42 struct {
-	Email  string        `json:"email"`
-	Name   string        `json:"login"`
-	Staff  bool          `json:"staff?"`
-	Campus []CampusUsers `json:"campus_users"`
+	ID          int64         `json:"id"`
+	Email       string        `json:"email"`
+	Login       string        `json:"login"`
+	DisplayName string        `json:"displayname"`
+	Image       Image42       `json:"image"`
+	Staff       bool          `json:"staff?"`
+	PoolYear    string        `json:"pool_year"`
+	PoolMonth   string        `json:"pool_month"`
+	Campus      []CampusUsers `json:"campus_users"`
+	Cursus      []CursusUsers `json:"cursus_users"`
+}
+
+// Image42 represents User42 profile picture information
+type Image42 struct {
+	Link string `json:"link"`
 }
 
 // CampusUsers represents User42 campus information
@@ -39,6 +51,11 @@ type Provider42 struct {
 	session          *sessions.CookieStore
 	redirectTokenURL string
 	userInfoURL      string
+	campuses         *campus.Registry
+
+	mu            sync.RWMutex
+	policy        AccessPolicy
+	sessionSecret string
 }
 
 // NewProvider42 creates a new 42 OAuth provider
@@ -48,17 +65,56 @@ func NewProvider42(
 	sessionSecret string,
 	redirectTokenURL string,
 	userInfoURL string,
+	policy AccessPolicy,
+	campuses *campus.Registry,
 ) *Provider42 {
 
 	return &Provider42{
 		db:               db,
 		config:           config,
-		session:          sessions.NewCookieStore([]byte(sessionSecret)),
+		session:          newSessionStore(sessionSecret),
+		sessionSecret:    sessionSecret,
 		redirectTokenURL: redirectTokenURL,
 		userInfoURL:      userInfoURL,
+		policy:           policy,
+		campuses:         campuses,
 	}
 }
 
+// SetAccessPolicy replaces the access policy checked on later logins.
+func (p *Provider42) SetAccessPolicy(policy AccessPolicy) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.policy = policy
+}
+
+// SetSecrets rotates the OAuth client secret and the session secret.
+// Sessions signed with the previous session secret can still be read, so
+// logins in progress complete.
+func (p *Provider42) SetSecrets(clientSecret, sessionSecret string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = withClientSecret(p.config, clientSecret)
+	if sessionSecret != p.sessionSecret {
+		p.session = newSessionStore(sessionSecret, p.sessionSecret)
+		p.sessionSecret = sessionSecret
+	}
+}
+
+// oauthConfig returns the OAuth config with the current client secret.
+func (p *Provider42) oauthConfig() *oauth2.Config {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config
+}
+
+// sessions returns the store of login sessions.
+func (p *Provider42) sessions() *sessions.CookieStore {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.session
+}
+
 // ExchangeCode exchanges OAuth code for access token
 func (p *Provider42) ExchangeCode(r *http.Request) (*oauth2.Token, error) {
 	code := r.URL.Query().Get("code")
@@ -66,7 +122,7 @@ func (p *Provider42) ExchangeCode(r *http.Request) (*oauth2.Token, error) {
 		return nil, ErrInvalidOAuthCode
 	}
 
-	return p.config.Exchange(r.Context(), code)
+	return p.oauthConfig().Exchange(r.Context(), code)
 }
 
 // Fetch42UserData fetch user data from 42 intranet
@@ -124,37 +180,44 @@ func (p *Provider42) NewRetryClient(oauthTransport http.RoundTripper) *retryable
 	return client
 }
 
-// FindOrCreateUser gets existing user or creates new one
-func (p *Provider42) FindOrCreateUser(ctx context.Context, user42 *User42) (database.User, error) {
-	// Try to find existing user
-	user, err := p.db.GetUserByEmail(ctx, user42.Email)
-	if err == nil {
-		return user, nil
-	}
-
-	// If not found, create new user
-	if errors.Is(err, sql.ErrNoRows) {
-		return p.createUser(ctx, user42)
-	}
-
-	return database.User{}, fmt.Errorf("database error: %w", err)
+// FindOrCreateUser gets the user linked to the 42 account, or creates one,
+// and syncs role, profile and campus with the intra data
+func (p *Provider42) FindOrCreateUser(ctx context.Context, user42 *User42, home *campus.Campus) (database.User, error) {
+	profile := profileFrom42(user42)
+	profile.CampusID = home.ID
+	return syncUser(ctx, p.db, profile, home.ID)
 }
 
-// createUser creates a new user from 42 data
-func (p *Provider42) createUser(ctx context.Context, user42 *User42) (database.User, error) {
+// profileFrom42 maps 42 intra user data to a Profile
+func profileFrom42(user42 *User42) Profile {
 	role := service.RoleStudent
 	if user42.Staff {
 		role = service.RoleStaff
 	}
 
-	user, err := p.db.CreateUser(ctx, database.CreateUserParams{
-		Email: user42.Email,
-		Name:  user42.Name,
-		Role:  role,
-	})
-	if err != nil {
-		return database.User{}, fmt.Errorf("failed to create user: %w", err)
+	name := user42.DisplayName
+	if name == "" {
+		name = user42.Login
 	}
 
-	return user, nil
+	return Profile{
+		Provider:      Provider42Name,
+		Subject:       strconv.FormatInt(user42.ID, 10),
+		Email:         user42.Email,
+		Name:          name,
+		Login:         user42.Login,
+		AvatarURL:     user42.Image.Link,
+		IntraCampusID: user42.PrimaryCampusID(),
+		Role:          role,
+	}
+}
+
+// PrimaryCampusID returns the ID of the user's primary campus, or 0 if none
+func (u *User42) PrimaryCampusID() int {
+	for _, camp := range u.Campus {
+		if camp.Primary {
+			return camp.ID
+		}
+	}
+	return 0
 }
@@ -0,0 +1,107 @@
+package oauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IbnBaqqi/book-me/internal/service"
+	"golang.org/x/oauth2"
+)
+
+func TestProfileFrom42(t *testing.T) {
+	tests := []struct {
+		name     string
+		user42   User42
+		expected Profile
+	}{
+		{
+			name: "student with display name and primary campus",
+			user42: User42{
+				ID:          4242,
+				Email:       "jdoe@student.hive.fi",
+				Login:       "jdoe",
+				DisplayName: "Jane Doe",
+				Image:       Image42{Link: "https://cdn.intra.42.fr/users/jdoe.jpg"},
+				Campus: []CampusUsers{
+					{ID: 1, Primary: false},
+					{ID: 13, Primary: true},
+				},
+			},
+			expected: Profile{
+				Provider:      Provider42Name,
+				Subject:       "4242",
+				Email:         "jdoe@student.hive.fi",
+				Name:          "Jane Doe",
+				Login:         "jdoe",
+				AvatarURL:     "https://cdn.intra.42.fr/users/jdoe.jpg",
+				IntraCampusID: 13,
+				Role:          service.RoleStudent,
+			},
+		},
+		{
+			name: "staff without display name or primary campus",
+			user42: User42{
+				ID:     7,
+				Email:  "bocal@hive.fi",
+				Login:  "bocal",
+				Staff:  true,
+				Campus: []CampusUsers{{ID: 13, Primary: false}},
+			},
+			expected: Profile{
+				Provider: Provider42Name,
+				Subject:  "7",
+				Email:    "bocal@hive.fi",
+				Name:     "bocal",
+				Login:    "bocal",
+				Role:     service.RoleStaff,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := profileFrom42(&tt.user42)
+			if got != tt.expected {
+				t.Errorf("expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProvider42_SetSecrets(t *testing.T) {
+	p := NewProvider42(nil, &oauth2.Config{ClientSecret: "client-1"}, "session-1", "", "", AccessPolicy{}, nil)
+
+	// A login started before the rotation
+	req := httptest.NewRequest(http.MethodGet, "/auth/42/login", nil)
+	w := httptest.NewRecorder()
+	session, _ := p.sessions().Get(req, sessionName)
+	session.Values["oauth_state"] = "state"
+	if err := session.Save(req, w); err != nil {
+		t.Fatal(err)
+	}
+	cookies := w.Result().Cookies()
+
+	readState := func() string {
+		req := httptest.NewRequest(http.MethodGet, "/auth/42/callback", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		session, _ := p.sessions().Get(req, sessionName)
+		state, _ := session.Values["oauth_state"].(string)
+		return state
+	}
+
+	p.SetSecrets("client-2", "session-2")
+	if p.oauthConfig().ClientSecret != "client-2" {
+		t.Errorf("expected rotated client secret, got %q", p.oauthConfig().ClientSecret)
+	}
+	if state := readState(); state != "state" {
+		t.Errorf("expected session of the previous secret to be readable, got %q", state)
+	}
+
+	p.SetSecrets("client-2", "session-3")
+	if state := readState(); state != "" {
+		t.Errorf("expected session two rotations old to be rejected, got %q", state)
+	}
+}
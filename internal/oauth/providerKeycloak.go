@@ -3,13 +3,16 @@ package oauth
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"sync"
 
+	"github.com/IbnBaqqi/book-me/internal/campus"
 	"github.com/IbnBaqqi/book-me/internal/database"
 	"github.com/IbnBaqqi/book-me/internal/service"
+	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/gorilla/sessions"
 	"golang.org/x/oauth2"
 )
@@ -18,87 +21,196 @@ import (
 type ProviderKeycloak struct {
 	db               *database.DB
 	config           *oauth2.Config
+	verifier         *oidc.IDTokenVerifier
 	session          *sessions.CookieStore
 	redirectTokenURL string
-	userInfoURL      string
+	staffRoles       []string
+	campuses         *campus.Registry
+
+	mu            sync.RWMutex
+	sessionSecret string
 }
 
-// keycloakClaims holds the relevant fields from the Keycloak userinfo response.
+// keycloakClaims holds the relevant fields from a verified Keycloak ID token.
 type keycloakClaims struct {
+	Subject           string `json:"sub"`
 	Email             string `json:"email"`
+	EmailVerified     *bool  `json:"email_verified"`
 	PreferredUsername string `json:"preferred_username"`
+	Name              string `json:"name"`
+	Picture           string `json:"picture"`
+	RealmAccess       struct {
+		Roles []string `json:"roles"`
+	} `json:"realm_access"`
+	ResourceAccess map[string]struct {
+		Roles []string `json:"roles"`
+	} `json:"resource_access"`
 }
 
 // NewProviderKeycloak creates a new Keycloak OIDC provider.
+// The authorization and token endpoints as well as the signing keys
+// are resolved through OIDC discovery on the issuer URL.
 func NewProviderKeycloak(
+	ctx context.Context,
 	db *database.DB,
 	config *oauth2.Config,
+	issuerURL string,
 	sessionSecret string,
 	redirectTokenURL string,
-	userInfoURL string,
-) *ProviderKeycloak {
+	staffRoles []string,
+	campuses *campus.Registry,
+) (*ProviderKeycloak, error) {
+	provider, err := oidc.NewProvider(ctx, issuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover keycloak issuer: %w", err)
+	}
+	config.Endpoint = provider.Endpoint()
+
 	return &ProviderKeycloak{
 		db:               db,
 		config:           config,
-		session:          sessions.NewCookieStore([]byte(sessionSecret)),
+		verifier:         provider.Verifier(&oidc.Config{ClientID: config.ClientID}),
+		session:          newSessionStore(sessionSecret),
+		sessionSecret:    sessionSecret,
 		redirectTokenURL: redirectTokenURL,
-		userInfoURL:      userInfoURL,
+		staffRoles:       staffRoles,
+		campuses:         campuses,
+	}, nil
+}
+
+// SetSecrets rotates the OAuth client secret and the session secret.
+// Sessions signed with the previous session secret can still be read, so
+// logins in progress complete.
+func (p *ProviderKeycloak) SetSecrets(clientSecret, sessionSecret string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = withClientSecret(p.config, clientSecret)
+	if sessionSecret != p.sessionSecret {
+		p.session = newSessionStore(sessionSecret, p.sessionSecret)
+		p.sessionSecret = sessionSecret
 	}
 }
 
+// oauthConfig returns the OAuth config with the current client secret.
+func (p *ProviderKeycloak) oauthConfig() *oauth2.Config {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.config
+}
+
+// sessions returns the store of login sessions.
+func (p *ProviderKeycloak) sessions() *sessions.CookieStore {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.session
+}
+
 // ExchangeCode exchanges the OAuth2 authorization code for a token.
 func (p *ProviderKeycloak) ExchangeCode(r *http.Request) (*oauth2.Token, error) {
 	code := r.URL.Query().Get("code")
 	if code == "" {
 		return nil, ErrInvalidOAuthCode
 	}
-	return p.config.Exchange(r.Context(), code)
+	return p.oauthConfig().Exchange(r.Context(), code)
 }
 
-// FetchUserInfo fetches user claims from the Keycloak userinfo endpoint.
-func (p *ProviderKeycloak) FetchUserInfo(ctx context.Context, token *oauth2.Token) (*keycloakClaims, error) {
-	client := p.config.Client(ctx, token)
+// VerifyIDToken verifies the ID token returned with the OAuth2 token against
+// the issuer's JWKS, checks that its nonce matches the one bound to the
+// login session and returns its claims.
+func (p *ProviderKeycloak) VerifyIDToken(ctx context.Context, token *oauth2.Token, nonce string) (*keycloakClaims, error) {
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, fmt.Errorf("token response missing id_token")
+	}
 
-	resp, err := client.Get(p.userInfoURL)
+	idToken, err := p.verifier.Verify(ctx, rawIDToken)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call userinfo endpoint: %w", err)
+		return nil, fmt.Errorf("failed to verify id token: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("userinfo endpoint returned status: %s", resp.Status)
+	if nonce == "" || idToken.Nonce != nonce {
+		return nil, ErrNonceMismatch
 	}
 
 	var claims keycloakClaims
-	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
-		return nil, fmt.Errorf("failed to decode userinfo response: %w", err)
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("failed to decode id token claims: %w", err)
 	}
 
-	if claims.Email == "" {
-		return nil, fmt.Errorf("userinfo response missing email")
+	if claims.Subject == "" || claims.Email == "" {
+		return nil, fmt.Errorf("id token missing subject or email")
 	}
-	// fmt.Println(claims)
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		return nil, fmt.Errorf("id token email is not verified")
+	}
+
 	return &claims, nil
 }
 
-// FindOrCreateUser looks up a user by email or creates one from Keycloak claims.
-func (p *ProviderKeycloak) FindOrCreateUser(ctx context.Context, claims *keycloakClaims) (database.User, error) {
-	user, err := p.db.GetUserByEmail(ctx, claims.Email)
-	if err == nil {
-		return user, nil
+// role maps the Keycloak realm and client roles in the claims to a BookMe role.
+func (p *ProviderKeycloak) role(claims *keycloakClaims) string {
+	roles := claims.RealmAccess.Roles
+	if client, ok := claims.ResourceAccess[p.oauthConfig().ClientID]; ok {
+		roles = append(slices.Clone(roles), client.Roles...)
 	}
 
-	if errors.Is(err, sql.ErrNoRows) {
-		name := claims.PreferredUsername
-		if name == "" {
-			name = claims.Email
+	for _, r := range roles {
+		if slices.Contains(p.staffRoles, r) {
+			return service.RoleStaff
 		}
-		return p.db.CreateUser(ctx, database.CreateUserParams{
-			Email: claims.Email,
-			Name:  name,
-			Role:  service.RoleStudent,
-		})
 	}
+	return service.RoleStudent
+}
+
+// HomeCampus returns the ID of the campus the Keycloak account logs in to.
+// Keycloak carries no campus: the account keeps the campus of the user it is
+// linked to, or of the user owning its email, and new users are placed on the
+// default campus. Nothing is created, so the campus can be checked first.
+func (p *ProviderKeycloak) HomeCampus(ctx context.Context, claims *keycloakClaims) (int64, error) {
+	identity, err := p.db.GetUserIdentity(ctx, database.GetUserIdentityParams{
+		Provider: ProviderKeycloakName,
+		Subject:  claims.Subject,
+	})
+	var user database.User
+	switch {
+	case err == nil:
+		user, err = p.db.GetUser(ctx, identity.UserID)
+	case errors.Is(err, sql.ErrNoRows):
+		user, err = p.db.GetUserByEmail(ctx, claims.Email)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return p.campuses.Default().ID, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return user.CampusID, nil
+}
+
+// FindOrCreateUser gets the user linked to the Keycloak account, or creates one
+// on the home campus, and syncs role and profile with the ID token claims.
+// Existing users keep their campus.
+func (p *ProviderKeycloak) FindOrCreateUser(ctx context.Context, claims *keycloakClaims, home *campus.Campus) (database.User, error) {
+	return syncUser(ctx, p.db, p.profile(claims), home.ID)
+}
 
-	return database.User{}, fmt.Errorf("database error: %w", err)
+// profile maps Keycloak claims to a Profile.
+func (p *ProviderKeycloak) profile(claims *keycloakClaims) Profile {
+	name := claims.Name
+	if name == "" {
+		name = claims.PreferredUsername
+	}
+	if name == "" {
+		name = claims.Email
+	}
+
+	return Profile{
+		Provider:  ProviderKeycloakName,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		Name:      name,
+		Login:     claims.PreferredUsername,
+		AvatarURL: claims.Picture,
+		Role:      p.role(claims),
+	}
 }
@@ -0,0 +1,216 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/service"
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/oauth2"
+)
+
+const testClientID = "bookme"
+
+// newTestIssuer starts a minimal OIDC issuer serving discovery and JWKS
+// documents for the given key.
+func newTestIssuer(t *testing.T, key *rsa.PrivateKey) *httptest.Server {
+	t.Helper()
+
+	var srv *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                                srv.URL,
+			"authorization_endpoint":                srv.URL + "/auth",
+			"token_endpoint":                        srv.URL + "/token",
+			"jwks_uri":                              srv.URL + "/certs",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/certs", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func signIDToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) *oauth2.Token {
+	t.Helper()
+
+	tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	tok.Header["kid"] = "test"
+	raw, err := tok.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign id token: %v", err)
+	}
+	return (&oauth2.Token{AccessToken: "access"}).WithExtra(map[string]any{"id_token": raw})
+}
+
+func TestProviderKeycloak_VerifyIDToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	issuer := newTestIssuer(t, key)
+	provider, err := NewProviderKeycloak(
+		context.Background(),
+		nil,
+		&oauth2.Config{ClientID: testClientID},
+		issuer.URL,
+		"session-secret",
+		"http://localhost/redirect",
+		[]string{"staff"},
+		nil,
+	)
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	if provider.config.Endpoint.TokenURL != issuer.URL+"/token" {
+		t.Errorf("expected token endpoint from discovery, got %q", provider.config.Endpoint.TokenURL)
+	}
+
+	baseClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                issuer.URL,
+			"aud":                testClientID,
+			"sub":                "kc-123",
+			"exp":                time.Now().Add(time.Minute).Unix(),
+			"iat":                time.Now().Unix(),
+			"nonce":              "expected-nonce",
+			"email":              "jane@hive.fi",
+			"preferred_username": "jane",
+		}
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		claims, err := provider.VerifyIDToken(context.Background(), signIDToken(t, key, baseClaims()), "expected-nonce")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if claims.Email != "jane@hive.fi" || claims.Subject != "kc-123" {
+			t.Errorf("unexpected claims: %+v", claims)
+		}
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		_, err := provider.VerifyIDToken(context.Background(), signIDToken(t, key, baseClaims()), "other-nonce")
+		if !errors.Is(err, ErrNonceMismatch) {
+			t.Errorf("expected ErrNonceMismatch, got %v", err)
+		}
+	})
+
+	t.Run("missing session nonce", func(t *testing.T) {
+		_, err := provider.VerifyIDToken(context.Background(), signIDToken(t, key, baseClaims()), "")
+		if !errors.Is(err, ErrNonceMismatch) {
+			t.Errorf("expected ErrNonceMismatch, got %v", err)
+		}
+	})
+
+	t.Run("signed by unknown key", func(t *testing.T) {
+		_, err := provider.VerifyIDToken(context.Background(), signIDToken(t, otherKey, baseClaims()), "expected-nonce")
+		if err == nil {
+			t.Error("expected error for token signed by unknown key")
+		}
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		claims := baseClaims()
+		claims["aud"] = "another-client"
+		_, err := provider.VerifyIDToken(context.Background(), signIDToken(t, key, claims), "expected-nonce")
+		if err == nil {
+			t.Error("expected error for wrong audience")
+		}
+	})
+
+	t.Run("unverified email", func(t *testing.T) {
+		claims := baseClaims()
+		claims["email_verified"] = false
+		_, err := provider.VerifyIDToken(context.Background(), signIDToken(t, key, claims), "expected-nonce")
+		if err == nil {
+			t.Error("expected error for unverified email")
+		}
+	})
+
+	t.Run("missing id token", func(t *testing.T) {
+		_, err := provider.VerifyIDToken(context.Background(), &oauth2.Token{AccessToken: "access"}, "expected-nonce")
+		if err == nil {
+			t.Error("expected error for missing id token")
+		}
+	})
+}
+
+func TestProviderKeycloak_Role(t *testing.T) {
+	provider := &ProviderKeycloak{
+		config:     &oauth2.Config{ClientID: testClientID},
+		staffRoles: []string{"staff", "bocal"},
+	}
+
+	tests := []struct {
+		name     string
+		claims   string
+		expected string
+	}{
+		{
+			name:     "no roles",
+			claims:   `{}`,
+			expected: service.RoleStudent,
+		},
+		{
+			name:     "realm staff role",
+			claims:   `{"realm_access":{"roles":["offline_access","staff"]}}`,
+			expected: service.RoleStaff,
+		},
+		{
+			name:     "client staff role",
+			claims:   `{"resource_access":{"bookme":{"roles":["bocal"]}}}`,
+			expected: service.RoleStaff,
+		},
+		{
+			name:     "staff role on another client is ignored",
+			claims:   `{"resource_access":{"other":{"roles":["staff"]}}}`,
+			expected: service.RoleStudent,
+		},
+		{
+			name:     "unrelated roles",
+			claims:   `{"realm_access":{"roles":["student"]},"resource_access":{"bookme":{"roles":["user"]}}}`,
+			expected: service.RoleStudent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims keycloakClaims
+			if err := json.Unmarshal([]byte(tt.claims), &claims); err != nil {
+				t.Fatalf("failed to decode claims: %v", err)
+			}
+			if got := provider.role(&claims); got != tt.expected {
+				t.Errorf("expected role %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -8,23 +8,33 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/campus"
 	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/logger"
+	"github.com/IbnBaqqi/book-me/internal/service"
+	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/gorilla/sessions"
+	"golang.org/x/oauth2"
 )
 
 // Service orchestrates the OAuth authentication flow.
 type Service struct {
 	provider42  *Provider42
 	providerKey *ProviderKeycloak
+	guard       *service.LoginGuardService
 }
 
-// NewService creates a new OAuth service.
-func NewService(provider42 *Provider42, providerKey *ProviderKeycloak) *Service {
+// NewService creates a new OAuth service. Failed callbacks are reported to
+// guard, which locks out IPs and accounts failing too often.
+func NewService(provider42 *Provider42, providerKey *ProviderKeycloak, guard *service.LoginGuardService) *Service {
 	return &Service{
 		provider42:  provider42,
 		providerKey: providerKey,
+		guard:       guard,
 	}
 }
 
@@ -34,7 +44,7 @@ const sessionName = "bookme-session"
 func (s *Service) Initiate42Login(w http.ResponseWriter, r *http.Request) (string, error) {
 	state := generateRandomState()
 
-	session, err := s.provider42.session.Get(r, sessionName)
+	session, err := s.provider42.sessions().Get(r, sessionName)
 	if err != nil {
 		return "", ErrOAuthSessionFailed
 	}
@@ -44,98 +54,157 @@ func (s *Service) Initiate42Login(w http.ResponseWriter, r *http.Request) (strin
 		return "", ErrFailedToSaveSession
 	}
 
-	return s.provider42.config.AuthCodeURL(state), nil
+	return s.provider42.oauthConfig().AuthCodeURL(state), nil
 }
 
 // Handle42Callback handles the 42 OAuth callback.
 func (s *Service) Handle42Callback(r *http.Request) (database.User, error) {
+	user, account, err := s.handle42Callback(r)
+	return user, s.track(r.Context(), account, err)
+}
+
+func (s *Service) handle42Callback(r *http.Request) (user database.User, account string, err error) {
 	token, err := s.provider42.ExchangeCode(r)
 	if err != nil {
 		slog.Error("oauth code exchange failed", "error", err)
-		return database.User{}, ErrOAuthExchangeFailed
+		return database.User{}, "", ErrOAuthExchangeFailed
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	user42, err := s.provider42.Fetch42UserData(ctx, s.provider42.config, token)
+	user42, err := s.provider42.Fetch42UserData(ctx, s.provider42.oauthConfig(), token)
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
-			return database.User{}, ErrOAuthTimeout
+			return database.User{}, "", ErrOAuthTimeout
 		}
 		slog.Error("failed to fetch user data from 42 intra", "error", err)
-		return database.User{}, ErrOAuthUserInfoFailed
+		return database.User{}, "", ErrOAuthUserInfoFailed
 	}
 
-	// Validate Campus
-	isHive := false
-	for _, camp := range user42.Campus {
-		if camp.ID == 13 && camp.Primary {
-			isHive = true
-			break
-		}
+	account = Provider42Name + ":" + strings.ToLower(user42.Login)
+	if err := s.checkLockout(r.Context(), "", account); err != nil {
+		return database.User{}, account, err
 	}
-	if !isHive {
-		return database.User{}, ErrInvalidCampus
+
+	// Validate campus, cursus, pool & per-login rules
+	home, err := s.provider42.CheckAccess(r.Context(), user42)
+	if err != nil {
+		var oauthErr *OauthError
+		if errors.As(err, &oauthErr) {
+			slog.Info("42 login rejected", "login", user42.Login, "reason", oauthErr.Message)
+			return database.User{}, account, err
+		}
+		slog.Error("failed to check login access", "error", err)
+		return database.User{}, account, ErrAccessCheckFailed
 	}
 
-	user, err := s.provider42.FindOrCreateUser(r.Context(), user42)
+	user, err = s.provider42.FindOrCreateUser(r.Context(), user42, home)
 	if err != nil {
 		slog.Error("unable to find or create user", "error", err)
-		return database.User{}, ErrFailedToFindorCreateUser
+		return database.User{}, account, ErrFailedToFindorCreateUser
+	}
+
+	if user.SuspendedAt.Valid {
+		slog.Info("suspended user login rejected", "user_id", user.ID)
+		return database.User{}, account, ErrAccountSuspended
 	}
 
-	return user, nil
+	return user, account, nil
 }
 
-// InitiateKeycloakLogin generates a state token and returns the Keycloak authorization URL.
+// InitiateKeycloakLogin generates a state token and a nonce bound to the
+// session and returns the Keycloak authorization URL.
 func (s *Service) InitiateKeycloakLogin(w http.ResponseWriter, r *http.Request) (string, error) {
 	state := generateRandomState()
+	nonce := generateRandomState()
 
-	session, err := s.providerKey.session.Get(r, sessionName)
+	session, err := s.providerKey.sessions().Get(r, sessionName)
 	if err != nil {
 		return "", ErrOAuthSessionFailed
 	}
 	session.Values["oauth_state"] = state
+	session.Values["oauth_nonce"] = nonce
 	if err := session.Save(r, w); err != nil {
 		slog.Error("failed to save session", "error", err)
 		return "", ErrFailedToSaveSession
 	}
 
-	return s.providerKey.config.AuthCodeURL(state), nil
+	return s.providerKey.oauthConfig().AuthCodeURL(state, oidc.Nonce(nonce)), nil
 }
 
 // HandleKeycloakCallback handles the Keycloak OIDC callback.
-func (s *Service) HandleKeycloakCallback(r *http.Request) (database.User, error) {
+func (s *Service) HandleKeycloakCallback(w http.ResponseWriter, r *http.Request) (database.User, error) {
+	user, account, err := s.handleKeycloakCallback(w, r)
+	return user, s.track(r.Context(), account, err)
+}
+
+func (s *Service) handleKeycloakCallback(w http.ResponseWriter, r *http.Request) (user database.User, account string, err error) {
+	nonce, err := s.popNonce(w, r)
+	if err != nil {
+		return database.User{}, "", err
+	}
+
 	token, err := s.providerKey.ExchangeCode(r)
 	if err != nil {
 		slog.Error("keycloak code exchange failed", "error", err)
-		return database.User{}, ErrOAuthExchangeFailed
+		return database.User{}, "", ErrOAuthExchangeFailed
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
 	defer cancel()
 
-	claims, err := s.providerKey.FetchUserInfo(ctx, token)
+	claims, err := s.providerKey.VerifyIDToken(ctx, token, nonce)
+	if err != nil {
+		if errors.Is(err, ErrNonceMismatch) {
+			slog.Warn("keycloak id token nonce mismatch")
+			return database.User{}, "", ErrNonceMismatch
+		}
+		slog.Error("failed to verify keycloak id token", "error", err)
+		return database.User{}, "", ErrInvalidIDToken
+	}
+
+	account = ProviderKeycloakName + ":" + strings.ToLower(claims.Email)
+	if err := s.checkLockout(r.Context(), "", account); err != nil {
+		return database.User{}, account, err
+	}
+
+	// The campus is checked before the user is created, so a rejected login
+	// leaves no account behind
+	campusID, err := s.providerKey.HomeCampus(r.Context(), claims)
 	if err != nil {
-		slog.Error("failed to fetch keycloak user info", "error", err)
-		return database.User{}, ErrOAuthUserInfoFailed
+		slog.Error("failed to resolve keycloak user campus", "error", err)
+		return database.User{}, account, ErrAccessCheckFailed
+	}
+	home, ok := s.providerKey.campuses.Get(campusID)
+	if !ok || !home.AllowsProvider(campus.ProviderKeycloak) {
+		slog.Info("keycloak login rejected", "campus_id", campusID, "reason", ErrProviderNotAllowed.Message)
+		return database.User{}, account, ErrProviderNotAllowed
 	}
 
-	user, err := s.providerKey.FindOrCreateUser(r.Context(), claims)
+	user, err = s.providerKey.FindOrCreateUser(r.Context(), claims, home)
 	if err != nil {
 		slog.Error("unable to find or create keycloak user", "error", err)
-		return database.User{}, ErrFailedToFindorCreateUser
+		return database.User{}, account, ErrFailedToFindorCreateUser
+	}
+
+	if user.SuspendedAt.Valid {
+		slog.Info("suspended user login rejected", "user_id", user.ID)
+		return database.User{}, account, ErrAccountSuspended
 	}
 
-	return user, nil
+	return user, account, nil
 }
 
 // ValidateState checks CSRF protection state.
 // It checks both provider sessions since only one will have the state set.
 func (s *Service) ValidateState(w http.ResponseWriter, r *http.Request) error {
+	return s.track(r.Context(), "", s.validateState(w, r))
+}
+
+func (s *Service) validateState(w http.ResponseWriter, r *http.Request) error {
 	// Try 42 session first, then Keycloak
-	for _, store := range []*sessions.CookieStore{s.provider42.session, s.providerKey.session} {
+	for _, store := range []*sessions.CookieStore{s.provider42.sessions(), s.providerKey.sessions()} {
 		session, err := store.Get(r, sessionName)
 		if err != nil {
 			continue
@@ -159,11 +228,118 @@ func (s *Service) ValidateState(w http.ResponseWriter, r *http.Request) error {
 	return ErrInvalidOrMissingState
 }
 
+// CheckLockout rejects callbacks from a client IP locked out after too
+// many failed logins.
+func (s *Service) CheckLockout(ctx context.Context) error {
+	return s.checkLockout(ctx, audit.MetaFromContext(ctx).IP, "")
+}
+
+func (s *Service) checkLockout(ctx context.Context, ip, account string) error {
+	if retryAfter := s.guard.Locked(ctx, ip, account); retryAfter > 0 {
+		logger.FromContext(ctx).Warn("security event",
+			"event", "login_blocked",
+			"ip", ip,
+			"account", account,
+			"retry_after", retryAfter,
+		)
+		return &OauthError{
+			Message:    ErrLoginLocked.Message,
+			StatusCode: ErrLoginLocked.StatusCode,
+			RetryAfter: retryAfter,
+		}
+	}
+	return nil
+}
+
+// track reports the outcome of a callback to the login guard and returns
+// err unchanged. Only failures a client can cause count, like a rejected
+// account or a nonce mismatch, provider outages don't. Failures before the
+// account is known, like a stale state or a replayed code, are only logged:
+// many users may share an IP behind a NAT, and an expired session of one of
+// them must not lock the others out.
+func (s *Service) track(ctx context.Context, account string, err error) error {
+	if err == nil {
+		if account != "" {
+			s.guard.Success(ctx, account)
+		}
+		return nil
+	}
+
+	var oauthErr *OauthError
+	if !errors.As(err, &oauthErr) {
+		return err
+	}
+	clientError := oauthErr.StatusCode >= 400 && oauthErr.StatusCode < 500 && oauthErr.StatusCode != http.StatusTooManyRequests
+	if !clientError && !errors.Is(err, ErrOAuthExchangeFailed) {
+		return err
+	}
+
+	ip := audit.MetaFromContext(ctx).IP
+	if account == "" {
+		logger.FromContext(ctx).Warn("security event",
+			"event", "login_failed",
+			"ip", ip,
+			"reason", oauthErr.Message,
+		)
+		return err
+	}
+	s.guard.Failure(ctx, ip, account, oauthErr.Message)
+	return err
+}
+
+// popNonce reads the OIDC nonce bound to the login session and removes it,
+// so that a nonce can only be used for a single callback.
+func (s *Service) popNonce(w http.ResponseWriter, r *http.Request) (string, error) {
+	session, err := s.providerKey.sessions().Get(r, sessionName)
+	if err != nil {
+		return "", ErrOAuthSessionFailed
+	}
+	nonce, _ := session.Values["oauth_nonce"].(string)
+	delete(session.Values, "oauth_nonce")
+	if err := session.Save(r, w); err != nil {
+		slog.Error("failed to save session", "error", err)
+		return "", ErrFailedToSaveSession
+	}
+	if nonce == "" {
+		return "", ErrNonceMismatch
+	}
+	return nonce, nil
+}
+
+// SetAccessPolicy replaces the access policy of 42 logins.
+func (s *Service) SetAccessPolicy(policy AccessPolicy) {
+	s.provider42.SetAccessPolicy(policy)
+}
+
+// SetSecrets rotates the client secrets of the 42 and Keycloak providers
+// and the secret of login sessions.
+func (s *Service) SetSecrets(clientSecret42, clientSecretKeycloak, sessionSecret string) {
+	s.provider42.SetSecrets(clientSecret42, sessionSecret)
+	s.providerKey.SetSecrets(clientSecretKeycloak, sessionSecret)
+}
+
 // GetRedirectTokenURL returns the OAuth provider redirect URL.
 func (s *Service) GetRedirectTokenURL() string {
 	return s.provider42.redirectTokenURL
 }
 
+// newSessionStore creates a store of login sessions signed with the first
+// secret, sessions signed with the other ones can still be read.
+func newSessionStore(secrets ...string) *sessions.CookieStore {
+	keyPairs := make([][]byte, 0, 2*len(secrets))
+	for _, secret := range secrets {
+		keyPairs = append(keyPairs, []byte(secret), nil)
+	}
+	return sessions.NewCookieStore(keyPairs...)
+}
+
+// withClientSecret returns a copy of config with clientSecret.
+func withClientSecret(config *oauth2.Config, clientSecret string) *oauth2.Config {
+	c := *config
+	c.ClientSecret = clientSecret
+	return &c
+}
+
 // generateRandomState generates a cryptographically secure random state token.
 func generateRandomState() string {
 	b := make([]byte, 32)
@@ -0,0 +1,124 @@
+package oauth
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/IbnBaqqi/book-me/internal/database"
+)
+
+// Identity provider names stored with linked accounts.
+const (
+	Provider42Name       = "42"
+	ProviderKeycloakName = "keycloak"
+)
+
+// Profile is the provider-independent view of a user as reported
+// by an identity provider on login.
+type Profile struct {
+	Provider      string
+	Subject       string
+	Email         string
+	Name          string
+	Login         string
+	AvatarURL     string
+	IntraCampusID int   // intra campus ID, 0 when unknown
+	CampusID      int64 // BookMe campus, 0 keeps the user's campus
+	Role          string
+}
+
+// syncUser links the provider account to a BookMe user and refreshes the
+// stored profile and role from the provider.
+//
+// Accounts are matched by provider subject first. The first login through a
+// provider falls back to matching by email, so one person using several
+// providers maps to a single user. The email is never overwritten, as it is
+// the key other providers link on. Users created on login are placed on
+// newUserCampus unless the profile names a campus.
+func syncUser(ctx context.Context, db *database.DB, p Profile, newUserCampus int64) (database.User, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.User{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := db.WithTx(tx.Tx)
+
+	identity, err := qtx.GetUserIdentity(ctx, database.GetUserIdentityParams{
+		Provider: p.Provider,
+		Subject:  p.Subject,
+	})
+	switch {
+	case err == nil:
+		if err := qtx.TouchUserIdentity(ctx, identity.ID); err != nil {
+			return database.User{}, fmt.Errorf("failed to update identity: %w", err)
+		}
+	case errors.Is(err, sql.ErrNoRows):
+		identity, err = linkIdentity(ctx, qtx, p, newUserCampus)
+		if err != nil {
+			return database.User{}, err
+		}
+	default:
+		return database.User{}, fmt.Errorf("database error: %w", err)
+	}
+
+	user, err := qtx.UpdateUserProfile(ctx, database.UpdateUserProfileParams{
+		ID:            identity.UserID,
+		Name:          p.Name,
+		Login:         nullString(p.Login),
+		AvatarUrl:     nullString(p.AvatarURL),
+		IntraCampusID: sql.NullInt32{Int32: int32(p.IntraCampusID), Valid: p.IntraCampusID != 0}, //nolint:gosec // campus IDs are small
+		Role:          p.Role,
+		CampusID:      sql.NullInt64{Int64: p.CampusID, Valid: p.CampusID != 0},
+	})
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to update user profile: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return database.User{}, err
+	}
+
+	return user, nil
+}
+
+// linkIdentity attaches a new provider identity to the user owning the
+// profile email, creating the user on campusID if none exists yet.
+func linkIdentity(ctx context.Context, qtx *database.Queries, p Profile, campusID int64) (database.UserIdentity, error) {
+	user, err := qtx.GetUserByEmail(ctx, p.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		if p.CampusID != 0 {
+			campusID = p.CampusID
+		}
+		user, err = qtx.CreateUser(ctx, database.CreateUserParams{
+			Email:    p.Email,
+			Name:     p.Name,
+			Role:     p.Role,
+			CampusID: campusID,
+		})
+		if err != nil {
+			return database.UserIdentity{}, fmt.Errorf("failed to create user: %w", err)
+		}
+	} else if err != nil {
+		return database.UserIdentity{}, fmt.Errorf("database error: %w", err)
+	}
+
+	identity, err := qtx.CreateUserIdentity(ctx, database.CreateUserIdentityParams{
+		UserID:   user.ID,
+		Provider: p.Provider,
+		Subject:  p.Subject,
+	})
+	if err != nil {
+		return database.UserIdentity{}, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	return identity, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
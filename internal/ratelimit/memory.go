@@ -0,0 +1,51 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps limits in process memory. Each server process counts
+// separately and restarts reset the limits.
+type MemoryStore struct {
+	mu              sync.Mutex
+	tats            map[string]time.Time
+	cleanupInterval time.Duration
+	lastCleanup     time.Time
+	now             func() time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tats:            make(map[string]time.Time),
+		cleanupInterval: 3 * time.Minute,
+		lastCleanup:     time.Now(),
+		now:             time.Now,
+	}
+}
+
+// Allow takes one request for key. Keys back at a full burst are dropped
+// inline every few minutes.
+func (s *MemoryStore) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastCleanup) > s.cleanupInterval {
+		for k, tat := range s.tats {
+			if !tat.After(now) {
+				delete(s.tats, k)
+			}
+		}
+		s.lastCleanup = now
+	}
+
+	result, tat := gcra(now, s.tats[key], limit)
+	if result.Allowed {
+		s.tats[key] = tat
+	}
+	return result, nil
+}
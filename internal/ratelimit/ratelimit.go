@@ -0,0 +1,74 @@
+// Package ratelimit implements the Generic Cell Rate Algorithm (GCRA) over
+// pluggable stores, in process memory for a single server or in Redis to
+// share limits between replicas.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit allows Burst requests at once, refilled at Rate requests per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// interval is the time it takes to earn back one request.
+func (l Limit) interval() time.Duration {
+	if l.Rate <= 0 {
+		return time.Duration(math.MaxInt64 / int64(max(l.Burst, 1)))
+	}
+	return time.Duration(float64(time.Second) / l.Rate)
+}
+
+// Window is the time it takes to refill a full burst.
+func (l Limit) Window() time.Duration {
+	return l.interval() * time.Duration(l.Burst)
+}
+
+// Result is the state of a key after a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter is how long to wait before the next request is allowed,
+	// zero when this one was.
+	RetryAfter time.Duration
+	// ResetAfter is how long until the full burst is available again.
+	ResetAfter time.Duration
+}
+
+// Store takes one request for key and reports whether it is allowed.
+type Store interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// gcra applies one request at now to a key whose theoretical arrival time
+// is tat, returning the result and the new tat to store when allowed.
+func gcra(now, tat time.Time, limit Limit) (Result, time.Time) {
+	interval := limit.interval()
+	tolerance := interval * time.Duration(limit.Burst)
+
+	if tat.Before(now) {
+		tat = now
+	}
+	newTAT := tat.Add(interval)
+	allowAt := newTAT.Add(-tolerance)
+
+	if now.Before(allowAt) {
+		return Result{
+			Limit:      limit.Burst,
+			RetryAfter: allowAt.Sub(now),
+			ResetAfter: tat.Sub(now),
+		}, tat
+	}
+
+	return Result{
+		Allowed:    true,
+		Limit:      limit.Burst,
+		Remaining:  int(now.Sub(allowAt) / interval),
+		ResetAfter: newTAT.Sub(now),
+	}, newTAT
+}
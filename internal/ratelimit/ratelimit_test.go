@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// storeTest creates a store along with a function advancing its clock.
+type storeTest struct {
+	name  string
+	setup func(t *testing.T, start time.Time) (Store, func(time.Duration))
+}
+
+var stores = []storeTest{
+	{
+		name: "memory",
+		setup: func(_ *testing.T, start time.Time) (Store, func(time.Duration)) {
+			now := start
+			store := NewMemoryStore()
+			store.now = func() time.Time { return now }
+			return store, func(d time.Duration) { now = now.Add(d) }
+		},
+	},
+	{
+		name: "redis",
+		setup: func(t *testing.T, start time.Time) (Store, func(time.Duration)) {
+			server := miniredis.RunT(t)
+			now := start
+			server.SetTime(now)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+			return NewRedisStore(client, "test:"), func(d time.Duration) {
+				now = now.Add(d)
+				server.SetTime(now)
+			}
+		},
+	},
+}
+
+func TestStore_Allow(t *testing.T) {
+	limit := Limit{Rate: 2, Burst: 3}
+
+	for _, st := range stores {
+		t.Run(st.name, func(t *testing.T) {
+			ctx := context.Background()
+			store, advance := st.setup(t, time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC))
+
+			for i := range 3 {
+				result, err := store.Allow(ctx, "ip", limit)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !result.Allowed {
+					t.Fatalf("request %d: expected to be allowed within burst", i+1)
+				}
+				if result.Remaining != 2-i {
+					t.Errorf("request %d: expected %d remaining, got %d", i+1, 2-i, result.Remaining)
+				}
+			}
+
+			result, err := store.Allow(ctx, "ip", limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Allowed {
+				t.Fatal("expected request over burst to be rejected")
+			}
+			if result.RetryAfter != 500*time.Millisecond {
+				t.Errorf("expected retry after 500ms, got %v", result.RetryAfter)
+			}
+			if result.ResetAfter != 1500*time.Millisecond {
+				t.Errorf("expected reset after 1.5s, got %v", result.ResetAfter)
+			}
+
+			other, err := store.Allow(ctx, "other-ip", limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !other.Allowed {
+				t.Error("expected other keys to have their own limit")
+			}
+
+			advance(500 * time.Millisecond)
+			result, err = store.Allow(ctx, "ip", limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Allowed || result.Remaining != 0 {
+				t.Errorf("expected one request earned back, got %+v", result)
+			}
+
+			advance(10 * time.Second)
+			result, err = store.Allow(ctx, "ip", limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !result.Allowed || result.Remaining != 2 {
+				t.Errorf("expected full burst after idling, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestRedisStore_SharedBetweenReplicas(t *testing.T) {
+	server := miniredis.RunT(t)
+	limit := Limit{Rate: 1, Burst: 2}
+	ctx := context.Background()
+
+	replicas := make([]*RedisStore, 2)
+	for i := range replicas {
+		client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		replicas[i] = NewRedisStore(client, "test:")
+	}
+
+	for i, replica := range []*RedisStore{replicas[0], replicas[1], replicas[0]} {
+		result, err := replica.Allow(ctx, "ip", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := i < 2; result.Allowed != expected {
+			t.Errorf("request %d: expected allowed %v, got %v", i+1, expected, result.Allowed)
+		}
+	}
+
+	if ttl := server.TTL("test:ip"); ttl <= 0 || ttl > 2*time.Second {
+		t.Errorf("expected key to expire once the burst is refilled, got ttl %v", ttl)
+	}
+}
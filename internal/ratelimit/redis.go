@@ -0,0 +1,69 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// gcraScript runs the GCRA atomically in Redis using the Redis clock, so
+// replicas with drifting clocks share one limit. Times are in microseconds
+// and the key expires once the full burst is available again.
+var gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call("GET", KEYS[1]) or now)
+if tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - tolerance
+
+if now < allow_at then
+	return {0, 0, allow_at - now, tat - now}
+end
+
+local reset = new_tat - now
+redis.call("SET", KEYS[1], string.format("%.0f", new_tat), "PX", math.ceil(reset / 1000))
+return {1, math.floor((now - allow_at) / interval), 0, reset}
+`)
+
+// RedisStore keeps limits in Redis, shared by all server replicas.
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+// NewRedisStore creates a store keeping its keys under prefix.
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Allow takes one request for key.
+func (s *RedisStore) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	interval := limit.interval().Microseconds()
+	tolerance := interval * int64(limit.Burst)
+
+	values, err := gcraScript.Run(ctx, s.client, []string{s.prefix + key}, interval, tolerance).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+	if len(values) != 4 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Limit:      limit.Burst,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Microsecond,
+		ResetAfter: time.Duration(values[3]) * time.Microsecond,
+	}, nil
+}
@@ -0,0 +1,193 @@
+// Package rbac provides the role and permission model for the application.
+package rbac
+
+import "slices"
+
+// Permission is a single action a user may be allowed to perform.
+type Permission string
+
+// Permissions
+const (
+	ReservationsRead      Permission = "reservations:read"      // view booked slots
+	ReservationsCreate    Permission = "reservations:create"    // book a room
+	ReservationsCancel    Permission = "reservations:cancel"    // cancel own reservations
+	ReservationsModerate  Permission = "reservations:moderate"  // cancel anyone's reservations
+	ReservationsUnlimited Permission = "reservations:unlimited" // book beyond the max duration
+	RoomsManage           Permission = "rooms:manage"
+	UsersManage           Permission = "users:manage"
+	UsersDelete           Permission = "users:delete" // delete or anonymize users
+	RolesAssign           Permission = "roles:assign"
+	AccessManage          Permission = "access:manage" // manage login allow/deny rules
+	TokensManage          Permission = "tokens:manage" // create and revoke own personal access tokens
+	RoomsDisplay          Permission = "rooms:display" // read the current and next booking of a room
+	AuditRead             Permission = "audit:read"
+	HoursManage           Permission = "hours:manage"    // manage holidays and special opening hours
+	CampusesManage        Permission = "campuses:manage" // manage campuses and act across all of them
+	HealthRead            Permission = "health:read"     // view the dependency health report
+)
+
+// TokenScopes are the permissions a personal access token can be limited to.
+// Managing tokens is deliberately not among them, so a token cannot mint others.
+var TokenScopes = []Permission{
+	ReservationsRead,
+	ReservationsCreate,
+	ReservationsCancel,
+}
+
+// Role is a named set of permissions.
+type Role string
+
+// Roles. STUDENT and STAFF are the base roles stored on a user,
+// other roles are granted on top of them.
+const (
+	Student     Role = "STUDENT"
+	Staff       Role = "STAFF"
+	RoomManager Role = "ROOM_MANAGER"
+	Admin       Role = "ADMIN"
+	Display     Role = "DISPLAY" // room display devices, never assigned to users
+)
+
+var rolePermissions = map[Role][]Permission{
+	Student: {
+		ReservationsRead,
+		ReservationsCreate,
+		ReservationsCancel,
+		TokensManage,
+	},
+	Staff: {
+		ReservationsRead,
+		ReservationsCreate,
+		ReservationsCancel,
+		ReservationsModerate,
+		ReservationsUnlimited,
+		RoomsManage,
+		UsersManage,
+		RolesAssign,
+		AccessManage,
+		TokensManage,
+		RoomsDisplay,
+		AuditRead,
+		HoursManage,
+		HealthRead,
+	},
+	RoomManager: {
+		ReservationsModerate,
+		ReservationsUnlimited,
+		RoomsManage,
+		RoomsDisplay,
+	},
+	Admin: {
+		ReservationsRead,
+		ReservationsCreate,
+		ReservationsCancel,
+		ReservationsModerate,
+		ReservationsUnlimited,
+		RoomsManage,
+		UsersManage,
+		UsersDelete,
+		RolesAssign,
+		AccessManage,
+		TokensManage,
+		RoomsDisplay,
+		AuditRead,
+		HoursManage,
+		CampusesManage,
+		HealthRead,
+	},
+	Display: {
+		RoomsDisplay,
+	},
+}
+
+// ParseRole returns the user role with the given name, if it exists.
+// The device only DISPLAY role is not a user role.
+func ParseRole(name string) (Role, bool) {
+	role := Role(name)
+	if role == Display {
+		return role, false
+	}
+	_, ok := rolePermissions[role]
+	return role, ok
+}
+
+// Permissions returns the permissions of the role.
+func (r Role) Permissions() []Permission {
+	return slices.Clone(rolePermissions[r])
+}
+
+// ParseScope returns the token scope with the given name, if it exists.
+func ParseScope(name string) (Permission, bool) {
+	p := Permission(name)
+	return p, slices.Contains(TokenScopes, p)
+}
+
+// Grant is a role held by a user, limited to one room when RoomID is set
+// and to a subset of the role's permissions when Scopes is set.
+type Grant struct {
+	Role   Role
+	RoomID int64        // 0 means all rooms
+	Scopes []Permission // nil means every permission of the role
+}
+
+// allows reports whether the grant carries the permission.
+func (gr Grant) allows(p Permission) bool {
+	if gr.Scopes != nil && !slices.Contains(gr.Scopes, p) {
+		return false
+	}
+	return slices.Contains(rolePermissions[gr.Role], p)
+}
+
+// Grants is the set of roles held by a user.
+type Grants []Grant
+
+// Has reports whether the permission is granted in any scope.
+// Use it to gate endpoints, then Can to check a specific room.
+func (g Grants) Has(p Permission) bool {
+	return slices.ContainsFunc(g, func(gr Grant) bool {
+		return gr.allows(p)
+	})
+}
+
+// Can reports whether the permission is granted for the room, either
+// globally or through a grant scoped to it. A roomID of 0 only matches
+// global grants.
+func (g Grants) Can(p Permission, roomID int64) bool {
+	return slices.ContainsFunc(g, func(gr Grant) bool {
+		if gr.RoomID != 0 && gr.RoomID != roomID {
+			return false
+		}
+		return gr.allows(p)
+	})
+}
+
+// Restrict returns a copy of the grants limited to the given scopes.
+// It is used for personal access tokens, which never hold more than the
+// user who created them.
+func (g Grants) Restrict(scopes []Permission) Grants {
+	scopes = slices.Clone(scopes)
+	if scopes == nil {
+		scopes = []Permission{}
+	}
+
+	restricted := make(Grants, len(g))
+	for i, gr := range g {
+		gr.Scopes = scopes
+		restricted[i] = gr
+	}
+	return restricted
+}
+
+// CanGrant reports whether a holder of these grants may grant the role in
+// the room. Granting requires roles:assign and every permission of the
+// role in the same scope, so nobody can hand out more than they hold.
+func (g Grants) CanGrant(role Role, roomID int64) bool {
+	if !g.Can(RolesAssign, 0) {
+		return false
+	}
+	for _, p := range rolePermissions[role] {
+		if !g.Can(p, roomID) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+package rbac
+
+import "testing"
+
+func TestGrants_Can(t *testing.T) {
+	tests := []struct {
+		name       string
+		grants     Grants
+		permission Permission
+		roomID     int64
+		expected   bool
+	}{
+		{
+			name:       "student can create reservations",
+			grants:     Grants{{Role: Student}},
+			permission: ReservationsCreate,
+			roomID:     1,
+			expected:   true,
+		},
+		{
+			name:       "student cannot moderate reservations",
+			grants:     Grants{{Role: Student}},
+			permission: ReservationsModerate,
+			roomID:     1,
+			expected:   false,
+		},
+		{
+			name:       "staff can moderate any room",
+			grants:     Grants{{Role: Staff}},
+			permission: ReservationsModerate,
+			roomID:     2,
+			expected:   true,
+		},
+		{
+			name:       "room manager can moderate own room",
+			grants:     Grants{{Role: Student}, {Role: RoomManager, RoomID: 1}},
+			permission: ReservationsModerate,
+			roomID:     1,
+			expected:   true,
+		},
+		{
+			name:       "room manager cannot moderate other rooms",
+			grants:     Grants{{Role: Student}, {Role: RoomManager, RoomID: 1}},
+			permission: ReservationsModerate,
+			roomID:     2,
+			expected:   false,
+		},
+		{
+			name:       "room scoped grant does not apply globally",
+			grants:     Grants{{Role: RoomManager, RoomID: 1}},
+			permission: RoomsManage,
+			roomID:     0,
+			expected:   false,
+		},
+		{
+			name:       "unknown role grants nothing",
+			grants:     Grants{{Role: Role("GUEST")}},
+			permission: ReservationsRead,
+			roomID:     0,
+			expected:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.grants.Can(tt.permission, tt.roomID); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestGrants_Has(t *testing.T) {
+	grants := Grants{{Role: Student}, {Role: RoomManager, RoomID: 3}}
+
+	if !grants.Has(RoomsManage) {
+		t.Error("expected room scoped permission to be held in some scope")
+	}
+	if grants.Has(UsersManage) {
+		t.Error("expected users:manage not to be held")
+	}
+}
+
+func TestGrants_CanGrant(t *testing.T) {
+	tests := []struct {
+		name     string
+		grants   Grants
+		role     Role
+		roomID   int64
+		expected bool
+	}{
+		{
+			name:     "staff can grant room manager",
+			grants:   Grants{{Role: Staff}},
+			role:     RoomManager,
+			roomID:   1,
+			expected: true,
+		},
+		{
+			name:     "room manager cannot grant room manager",
+			grants:   Grants{{Role: Student}, {Role: RoomManager, RoomID: 1}},
+			role:     RoomManager,
+			roomID:   1,
+			expected: false,
+		},
+		{
+			name:     "staff can grant staff",
+			grants:   Grants{{Role: Staff}},
+			role:     Staff,
+			expected: true,
+		},
+		{
+			name:     "staff cannot grant admin",
+			grants:   Grants{{Role: Staff}},
+			role:     Admin,
+			expected: false,
+		},
+		{
+			name:     "student cannot grant anything",
+			grants:   Grants{{Role: Student}},
+			role:     Student,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.grants.CanGrant(tt.role, tt.roomID); got != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for _, name := range []string{"STUDENT", "STAFF", "ROOM_MANAGER", "ADMIN"} {
+		if _, ok := ParseRole(name); !ok {
+			t.Errorf("expected %s to be a known role", name)
+		}
+	}
+	if _, ok := ParseRole("staff"); ok {
+		t.Error("expected role names to be case sensitive")
+	}
+	if _, ok := ParseRole("DISPLAY"); ok {
+		t.Error("expected device role not to be a user role")
+	}
+}
+
+func TestGrants_Restrict(t *testing.T) {
+	grants := Grants{{Role: Staff}, {Role: RoomManager, RoomID: 1}}
+	restricted := grants.Restrict([]Permission{ReservationsRead, ReservationsCreate})
+
+	if !restricted.Can(ReservationsCreate, 1) {
+		t.Error("expected scoped permission to be kept")
+	}
+	if restricted.Can(ReservationsModerate, 1) {
+		t.Error("expected permission outside the scopes to be dropped")
+	}
+	if restricted.Has(TokensManage) {
+		t.Error("expected restricted grants not to manage tokens")
+	}
+	if !grants.Can(ReservationsModerate, 1) {
+		t.Error("expected original grants to be unchanged")
+	}
+	if grants.Restrict(nil).Has(ReservationsRead) {
+		t.Error("expected no scopes to grant nothing")
+	}
+}
@@ -0,0 +1,219 @@
+package schedule
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// maxICSEvents bounds the number of exceptions a single import may produce.
+const maxICSEvents = 2000
+
+// ParseICS reads the VEVENTs of an iCalendar file as exceptions.
+// All-day events close the campus on every day they cover. Timed events
+// set special opening hours on their start date, from their start to their
+// end, which may be on the next day up to 24 hours later. Times are read in
+// the event's TZID, as UTC when they end in Z, and in loc otherwise.
+func ParseICS(r io.Reader, loc *time.Location) ([]Exception, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		exceptions []Exception
+		event      map[string]icsProperty
+	)
+	for n, line := range lines {
+		switch {
+		case line == "BEGIN:VEVENT":
+			event = make(map[string]icsProperty)
+		case line == "END:VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", n+1)
+			}
+			parsed, err := eventExceptions(event, loc)
+			if err != nil {
+				return nil, fmt.Errorf("event ending on line %d: %w", n+1, err)
+			}
+			exceptions = append(exceptions, parsed...)
+			if len(exceptions) > maxICSEvents {
+				return nil, fmt.Errorf("calendar has more than %d days", maxICSEvents)
+			}
+			event = nil
+		case event != nil:
+			prop, ok := parseProperty(line)
+			if ok {
+				event[prop.name] = prop
+			}
+		}
+	}
+
+	if event != nil {
+		return nil, fmt.Errorf("unterminated VEVENT")
+	}
+	return exceptions, nil
+}
+
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// unfoldLines joins continuation lines, which start with a space or tab.
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseProperty splits NAME;PARAM=VALUE:value.
+func parseProperty(line string) (icsProperty, bool) {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return icsProperty{}, false
+	}
+
+	parts := strings.Split(head, ";")
+	prop := icsProperty{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string),
+		value:  value,
+	}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		prop.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return prop, true
+}
+
+func eventExceptions(event map[string]icsProperty, loc *time.Location) ([]Exception, error) {
+	startProp, ok := event["DTSTART"]
+	if !ok {
+		return nil, fmt.Errorf("missing DTSTART")
+	}
+
+	name := unescapeText(event["SUMMARY"].value)
+	uid := event["UID"].value
+
+	start, allDay, err := parseICSTime(startProp, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	endProp, hasEnd := event["DTEND"]
+
+	if allDay {
+		// DTEND of an all-day event is exclusive and defaults to the next day
+		end := start.AddDate(0, 0, 1)
+		if hasEnd {
+			end, _, err = parseICSTime(endProp, loc)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		var exceptions []Exception
+		for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+			exceptions = append(exceptions, Exception{
+				Date:  day.Format(DateLayout),
+				Hours: Hours{Closed: true},
+				Name:  name,
+				UID:   uid,
+			})
+			if len(exceptions) > maxICSEvents {
+				return nil, fmt.Errorf("event covers more than %d days", maxICSEvents)
+			}
+		}
+		return exceptions, nil
+	}
+
+	if !hasEnd {
+		return nil, fmt.Errorf("timed event without DTEND")
+	}
+	end, _, err := parseICSTime(endProp, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	start = start.In(loc)
+	end = end.In(loc)
+
+	closeMinute := minuteOfDay(end)
+	if end.Format(DateLayout) != start.Format(DateLayout) {
+		// Events running overnight close on the next day
+		next := start.AddDate(0, 0, 1)
+		if end.Format(DateLayout) != next.Format(DateLayout) || end.Sub(start) > 24*time.Hour {
+			return nil, fmt.Errorf("timed event must end within 24 hours of its start")
+		}
+		closeMinute += 24 * 60
+	}
+
+	hours := Hours{Open: minuteOfDay(start), Close: closeMinute}
+	if err := hours.Validate(); err != nil {
+		return nil, err
+	}
+
+	return []Exception{{
+		Date:  start.Format(DateLayout),
+		Hours: hours,
+		Name:  name,
+		UID:   uid,
+	}}, nil
+}
+
+// parseICSTime parses a DATE or DATE-TIME value. All-day dates are
+// returned as midnight in loc.
+func parseICSTime(prop icsProperty, loc *time.Location) (time.Time, bool, error) {
+	value := prop.value
+
+	if prop.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		t, err := time.ParseInLocation("20060102", value, loc)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s date %q", prop.name, value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid %s time %q", prop.name, value)
+		}
+		return t, false, nil
+	}
+
+	eventLoc := loc
+	if tzid := prop.params["TZID"]; tzid != "" {
+		var err error
+		eventLoc, err = time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown TZID %q", tzid)
+		}
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, eventLoc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid %s time %q", prop.name, value)
+	}
+	return t, false, nil
+}
+
+// unescapeText reverses iCalendar TEXT escaping.
+func unescapeText(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
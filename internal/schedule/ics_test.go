@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseICS(t *testing.T) {
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:xmas@hive.fi",
+		"DTSTART;VALUE=DATE:20261224",
+		"DTEND;VALUE=DATE:20261227",
+		"SUMMARY:Christmas\\, campus closed",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:short@hive.fi",
+		"DTSTART;TZID=Europe/Helsinki:20261223T080000",
+		"DTEND;TZID=Europe/Helsinki:20261223T140000",
+		"SUMMARY:Short",
+		"  day",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:hack@hive.fi",
+		"DTSTART:20261120T040000Z",
+		"DTEND:20261120T220000Z",
+		"SUMMARY:Hackathon",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:gamejam@hive.fi",
+		"DTSTART;TZID=Europe/Helsinki:20261127T180000",
+		"DTEND;TZID=Europe/Helsinki:20261128T020000",
+		"SUMMARY:Game jam",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+
+	got, err := ParseICS(strings.NewReader(ics), helsinki)
+	if err != nil {
+		t.Fatalf("ParseICS: %v", err)
+	}
+
+	want := []Exception{
+		{Date: "2026-12-24", Hours: Hours{Closed: true}, Name: "Christmas, campus closed", UID: "xmas@hive.fi"},
+		{Date: "2026-12-25", Hours: Hours{Closed: true}, Name: "Christmas, campus closed", UID: "xmas@hive.fi"},
+		{Date: "2026-12-26", Hours: Hours{Closed: true}, Name: "Christmas, campus closed", UID: "xmas@hive.fi"},
+		{Date: "2026-12-23", Hours: Hours{Open: 8 * 60, Close: 14 * 60}, Name: "Short day", UID: "short@hive.fi"},
+		{Date: "2026-11-20", Hours: Hours{Open: 6 * 60, Close: 24 * 60}, Name: "Hackathon", UID: "hack@hive.fi"},
+		{Date: "2026-11-27", Hours: Hours{Open: 18 * 60, Close: 26 * 60}, Name: "Game jam", UID: "gamejam@hive.fi"},
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("expected %d exceptions, got %d: %+v", len(want), len(got), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("exception %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestParseICS_Invalid(t *testing.T) {
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		ics  string
+	}{
+		{"missing DTSTART", "BEGIN:VEVENT\nSUMMARY:x\nEND:VEVENT"},
+		{"unterminated event", "BEGIN:VEVENT\nDTSTART;VALUE=DATE:20261224"},
+		{"timed event without end", "BEGIN:VEVENT\nDTSTART:20261224T080000Z\nEND:VEVENT"},
+		{"timed event over 24 hours", "BEGIN:VEVENT\nDTSTART:20261224T080000Z\nDTEND:20261225T090000Z\nEND:VEVENT"},
+		{"timed event across days", "BEGIN:VEVENT\nDTSTART:20261224T080000Z\nDTEND:20261226T070000Z\nEND:VEVENT"},
+		{"unknown time zone", "BEGIN:VEVENT\nDTSTART;TZID=Mars/Olympus:20261224T080000\nDTEND:20261224T100000Z\nEND:VEVENT"},
+		{"end before start", "BEGIN:VEVENT\nDTSTART:20261224T100000Z\nDTEND:20261224T080000Z\nEND:VEVENT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseICS(strings.NewReader(tt.ics), helsinki); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
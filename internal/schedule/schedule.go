@@ -0,0 +1,194 @@
+// Package schedule provides campus opening hours together with the
+// exceptions to them: closed days, shortened days and extended nights.
+package schedule
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DateLayout is the layout of dates exceptions are keyed by.
+const DateLayout = "2006-01-02"
+
+// Hours is the opening time of a single day as minutes after local midnight.
+// A closed day has Closed set and the times are ignored.
+type Hours struct {
+	Open   int
+	Close  int // past 24*60 when open overnight, at most 24 hours after Open
+	Closed bool
+}
+
+// Exception overrides the regular hours on one date.
+type Exception struct {
+	Date  string // YYYY-MM-DD in the calendar's location
+	Hours Hours
+	Name  string
+	UID   string // iCalendar UID for imported exceptions, empty otherwise
+}
+
+// Day is the effective opening hours of a date.
+type Day struct {
+	Date      string
+	Hours     Hours
+	Name      string
+	Exception bool
+}
+
+// Calendar answers whether the campus is open at a given time. It is safe
+// for concurrent use, exceptions are swapped in with Replace.
+type Calendar struct {
+	loc     *time.Location
+	regular Hours
+
+	mu         sync.RWMutex
+	exceptions map[string]Exception
+}
+
+// NewCalendar creates a calendar open for the regular hours every day.
+func NewCalendar(loc *time.Location, regular Hours) *Calendar {
+	return &Calendar{
+		loc:        loc,
+		regular:    regular,
+		exceptions: make(map[string]Exception),
+	}
+}
+
+// Location returns the time zone the hours are in.
+func (c *Calendar) Location() *time.Location {
+	return c.loc
+}
+
+// Replace swaps the set of exceptions.
+func (c *Calendar) Replace(exceptions []Exception) {
+	byDate := make(map[string]Exception, len(exceptions))
+	for _, e := range exceptions {
+		byDate[e.Date] = e
+	}
+
+	c.mu.Lock()
+	c.exceptions = byDate
+	c.mu.Unlock()
+}
+
+// DayOf returns the opening hours of the local date t falls on.
+func (c *Calendar) DayOf(t time.Time) Day {
+	date := t.In(c.loc).Format(DateLayout)
+
+	c.mu.RLock()
+	e, ok := c.exceptions[date]
+	c.mu.RUnlock()
+
+	if ok {
+		return Day{Date: date, Hours: e.Hours, Name: e.Name, Exception: true}
+	}
+	return Day{Date: date, Hours: c.regular}
+}
+
+// IsOpenAt reports whether t is within opening hours, of its own date or
+// of the previous one when it is open overnight. Closing time itself counts
+// as open so bookings can end exactly when the campus closes.
+func (c *Calendar) IsOpenAt(t time.Time) bool {
+	t = t.Truncate(time.Minute)
+	midnight := c.midnightOf(t)
+	return c.within(midnight, t, t) || c.within(midnight.AddDate(0, 0, -1), t, t)
+}
+
+// Allows reports whether the whole of [start, end) falls within the
+// opening hours of a single day, which may run past midnight.
+func (c *Calendar) Allows(start, end time.Time) bool {
+	if !end.After(start) {
+		return false
+	}
+	midnight := c.midnightOf(start)
+	return c.within(midnight, start, end) || c.within(midnight.AddDate(0, 0, -1), start, end)
+}
+
+// within reports whether [start, end] falls within the opening hours of
+// the date beginning at midnight.
+func (c *Calendar) within(midnight, start, end time.Time) bool {
+	day := c.DayOf(noon(midnight))
+	if day.Hours.Closed {
+		return false
+	}
+
+	open := atMinute(midnight, day.Hours.Open)
+	closing := atMinute(midnight, day.Hours.Close)
+	return !start.Before(open) && !end.After(closing)
+}
+
+// Days returns the effective hours of every date from `from` to `to`, both
+// inclusive. The dates are read in the location of from and to, so midnight
+// in any time zone stands for that date.
+func (c *Calendar) Days(from, to time.Time) []Day {
+	current := c.noonOf(from)
+	last := c.noonOf(to)
+
+	var days []Day
+	for !current.After(last) {
+		days = append(days, c.DayOf(current))
+		current = current.AddDate(0, 0, 1)
+	}
+	return days
+}
+
+// Validate checks the hours describe a day that is open for some time,
+// opening on the day and closing within 24 hours.
+func (h Hours) Validate() error {
+	if h.Closed {
+		return nil
+	}
+	if h.Open < 0 || h.Open >= 24*60 || h.Open >= h.Close || h.Close > h.Open+24*60 {
+		return fmt.Errorf("invalid opening hours %s-%s", FormatMinute(h.Open), FormatMinute(h.Close))
+	}
+	return nil
+}
+
+// ParseMinute parses a "15:04" clock time, or "24:00", into minutes after midnight.
+func ParseMinute(s string) (int, error) {
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// FormatMinute formats minutes after midnight as "15:04". Times past
+// "24:00" are on the next day and formatted as such.
+func FormatMinute(m int) string {
+	if m > 24*60 {
+		m -= 24 * 60
+	}
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
+}
+
+// noon is used to step through dates without daylight saving surprises.
+func noon(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, t.Location())
+}
+
+// midnightOf returns the start of the local date of t.
+func (c *Calendar) midnightOf(t time.Time) time.Time {
+	local := t.In(c.loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.loc)
+}
+
+// noonOf returns noon in the calendar's location on the date of t in the
+// location of t.
+func (c *Calendar) noonOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 12, 0, 0, 0, c.loc)
+}
+
+func minuteOfDay(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+// atMinute returns the wall-clock time m minutes after midnight, so hours
+// stay correct on days with a daylight saving change. Minutes past 24*60
+// fall on the next day.
+func atMinute(midnight time.Time, m int) time.Time {
+	return time.Date(midnight.Year(), midnight.Month(), midnight.Day(), m/60, m%60, 0, 0, midnight.Location())
+}
@@ -0,0 +1,240 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendar(t *testing.T) {
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCalendar(helsinki, Hours{Open: 6 * 60, Close: 20 * 60})
+	c.Replace([]Exception{
+		{Date: "2026-12-24", Hours: Hours{Closed: true}, Name: "Christmas Eve"},
+		{Date: "2026-12-23", Hours: Hours{Open: 8 * 60, Close: 14 * 60}, Name: "Short day"},
+		{Date: "2026-11-20", Hours: Hours{Open: 6 * 60, Close: 24 * 60}, Name: "Hackathon"},
+	})
+
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, helsinki)
+	}
+
+	openTests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"regular day open", at(12, 22, 10, 0), true},
+		{"regular day before opening", at(12, 22, 5, 59), false},
+		{"regular day at closing", at(12, 22, 20, 0), true},
+		{"regular day after closing", at(12, 22, 20, 1), false},
+		{"closed day", at(12, 24, 10, 0), false},
+		{"short day after closing", at(12, 23, 15, 0), false},
+		{"short day open", at(12, 23, 9, 0), true},
+		{"extended night", at(11, 20, 23, 30), true},
+	}
+	for _, tt := range openTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.IsOpenAt(tt.t); got != tt.want {
+				t.Errorf("IsOpenAt(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+
+	allowTests := []struct {
+		name       string
+		start, end time.Time
+		want       bool
+	}{
+		{"within regular hours", at(12, 22, 10, 0), at(12, 22, 12, 0), true},
+		{"ends at closing", at(12, 22, 18, 0), at(12, 22, 20, 0), true},
+		{"runs past closing", at(12, 22, 19, 0), at(12, 22, 21, 0), false},
+		{"spans overnight", at(12, 22, 10, 0), at(12, 23, 10, 0), false},
+		{"closed day", at(12, 24, 10, 0), at(12, 24, 11, 0), false},
+		{"short day past closing", at(12, 23, 12, 0), at(12, 23, 15, 0), false},
+		{"extended night until midnight", at(11, 20, 21, 0), at(11, 21, 0, 0), true},
+		{"daylight saving day", at(3, 29, 6, 0), at(3, 29, 20, 0), true},
+	}
+	for _, tt := range allowTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Allows(tt.start, tt.end); got != tt.want {
+				t.Errorf("Allows(%v, %v) = %v, want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+
+	days := c.Days(at(12, 22, 0, 0), at(12, 24, 0, 0))
+	if len(days) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(days))
+	}
+	if days[0].Exception || !days[1].Exception || !days[2].Hours.Closed {
+		t.Errorf("unexpected days %+v", days)
+	}
+}
+
+func TestCalendar_Overnight(t *testing.T) {
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCalendar(helsinki, Hours{Open: 6 * 60, Close: 20 * 60})
+	c.Replace([]Exception{
+		{Date: "2026-11-27", Hours: Hours{Open: 18 * 60, Close: 26 * 60}, Name: "Game jam"},
+		{Date: "2026-11-28", Hours: Hours{Closed: true}, Name: "Cleaning"},
+	})
+
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 11, day, hour, minute, 0, 0, helsinki)
+	}
+
+	openTests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"before opening", at(27, 17, 59), false},
+		{"evening", at(27, 23, 0), true},
+		{"after midnight", at(28, 1, 0), true},
+		{"at closing", at(28, 2, 0), true},
+		{"after closing", at(28, 2, 1), false},
+		{"next day closed", at(28, 10, 0), false},
+		{"night before opening", at(27, 1, 0), false},
+	}
+	for _, tt := range openTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.IsOpenAt(tt.t); got != tt.want {
+				t.Errorf("IsOpenAt(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+
+	allowTests := []struct {
+		name       string
+		start, end time.Time
+		want       bool
+	}{
+		{"across midnight", at(27, 23, 0), at(28, 1, 0), true},
+		{"after midnight", at(28, 0, 30), at(28, 2, 0), true},
+		{"past closing", at(28, 1, 0), at(28, 3, 0), false},
+		{"before opening", at(27, 17, 0), at(27, 19, 0), false},
+	}
+	for _, tt := range allowTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Allows(tt.start, tt.end); got != tt.want {
+				t.Errorf("Allows(%v, %v) = %v, want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+
+	if err := (Hours{Open: 18 * 60, Close: 26 * 60}).Validate(); err != nil {
+		t.Errorf("expected overnight hours to be valid, got %v", err)
+	}
+	if err := (Hours{Open: 18 * 60, Close: 42*60 + 1}).Validate(); err == nil {
+		t.Error("expected hours longer than a day to be invalid")
+	}
+	if got := FormatMinute(26 * 60); got != "02:00" {
+		t.Errorf("FormatMinute(26*60) = %q, want 02:00", got)
+	}
+}
+
+func TestCalendar_DaylightSaving(t *testing.T) {
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCalendar(helsinki, Hours{Open: 6 * 60, Close: 20 * 60})
+
+	utc := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	// Helsinki is UTC+2 in winter and UTC+3 in summer, switching on
+	// 2026-03-29 and 2026-10-25 at 01:00 UTC
+	tests := []struct {
+		name string
+		t    time.Time
+		want bool
+	}{
+		{"before spring forward opening", utc(3, 28, 3, 59), false},
+		{"before spring forward open", utc(3, 28, 4, 0), true},
+		{"spring forward day before opening", utc(3, 29, 2, 59), false},
+		{"spring forward day open", utc(3, 29, 3, 0), true},
+		{"spring forward day at closing", utc(3, 29, 17, 0), true},
+		{"spring forward day after closing", utc(3, 29, 17, 1), false},
+		{"fall back day before opening", utc(10, 25, 3, 59), false},
+		{"fall back day open", utc(10, 25, 4, 0), true},
+		{"fall back day at closing", utc(10, 25, 18, 0), true},
+		{"fall back day after closing", utc(10, 25, 18, 1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.IsOpenAt(tt.t); got != tt.want {
+				t.Errorf("IsOpenAt(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+
+	allowTests := []struct {
+		name       string
+		start, end time.Time
+		want       bool
+	}{
+		{"whole spring forward day", utc(3, 29, 3, 0), utc(3, 29, 17, 0), true},
+		{"spring forward day with winter hours", utc(3, 29, 4, 0), utc(3, 29, 18, 0), false},
+		{"whole fall back day", utc(10, 25, 4, 0), utc(10, 25, 18, 0), true},
+		{"fall back day with summer hours", utc(10, 25, 3, 0), utc(10, 25, 17, 0), false},
+	}
+	for _, tt := range allowTests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.Allows(tt.start, tt.end); got != tt.want {
+				t.Errorf("Allows(%v, %v) = %v, want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+
+	// Dates are read in the location they are given in, midnight in Tokyo
+	// is still the previous day in Helsinki
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	days := c.Days(time.Date(2026, 3, 28, 0, 0, 0, 0, tokyo), time.Date(2026, 3, 30, 0, 0, 0, 0, tokyo))
+	want := []string{"2026-03-28", "2026-03-29", "2026-03-30"}
+	if len(days) != len(want) {
+		t.Fatalf("expected %d days, got %d", len(want), len(days))
+	}
+	for i, day := range days {
+		if day.Date != want[i] {
+			t.Errorf("day %d: expected %s, got %s", i, want[i], day.Date)
+		}
+	}
+}
+
+func TestParseMinute(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "06:00", want: 360},
+		{in: "20:30", want: 1230},
+		{in: "24:00", want: 1440},
+		{in: "25:00", wantErr: true},
+		{in: "8am", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMinute(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMinute(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMinute(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
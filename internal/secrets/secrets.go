@@ -0,0 +1,88 @@
+// Package secrets resolves secret references in the configuration, so that
+// secrets can be read from files, like Docker and Kubernetes secrets, or
+// from a secret manager instead of plain environment variables.
+//
+// A reference is written scheme:ref. file:/run/secrets/jwt reads a file,
+// other schemes are handled by a Provider, like vault:secret/book-me#jwt.
+// Values with any other scheme are literals.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FileScheme is the scheme of references to files.
+const FileScheme = "file"
+
+// ErrNotFound is returned when a referenced secret doesn't exist.
+var ErrNotFound = errors.New("secret not found")
+
+// Provider reads secrets from a secret manager.
+type Provider interface {
+	// Scheme is the scheme of the references handled by the provider.
+	Scheme() string
+	// Get returns the secret the reference, without its scheme, points at.
+	Get(ctx context.Context, ref string) (string, error)
+}
+
+// Resolver resolves references with the file scheme and the schemes of its
+// providers.
+type Resolver struct {
+	providers map[string]Provider
+}
+
+// NewResolver creates a resolver with the given providers.
+func NewResolver(providers ...Provider) *Resolver {
+	r := &Resolver{providers: make(map[string]Provider, len(providers))}
+	for _, p := range providers {
+		r.providers[p.Scheme()] = p
+	}
+	return r
+}
+
+// IsReference reports whether value is resolved by r instead of being used
+// as is.
+func (r *Resolver) IsReference(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	_, known := r.providers[scheme]
+	return known || scheme == FileScheme
+}
+
+// Resolve returns the secret value points at, or value itself when it is
+// not a reference.
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !r.IsReference(value) {
+		return value, nil
+	}
+
+	scheme, ref, _ := strings.Cut(value, ":")
+	if scheme == FileScheme {
+		return ReadFile(ref)
+	}
+
+	secret, err := r.providers[scheme].Get(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", scheme, err)
+	}
+	return secret, nil
+}
+
+// ReadFile reads a secret from a file, without the trailing newline most
+// editors and secret mounts add.
+func ReadFile(path string) (string, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // path comes from config, not user input
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("%w: %s", ErrNotFound, path)
+		}
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
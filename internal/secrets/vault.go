@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// VaultScheme is the scheme of references to Vault secrets.
+const VaultScheme = "vault"
+
+// Vault reads secrets from the KV version 2 engine of a Vault compatible
+// HTTP API. References are written mount/path#key, so
+// vault:secret/book-me#jwt_secret is the jwt_secret key of the secret
+// stored at book-me in the engine mounted at secret.
+type Vault struct {
+	addr      string
+	token     string
+	namespace string
+	client    *http.Client
+}
+
+// NewVault creates a Vault provider.
+//   - addr: base URL of the API, like https://vault.example.com:8200
+//   - token: sent as X-Vault-Token
+//   - namespace: sent as X-Vault-Namespace when set
+//   - client: used for requests, a client with a 10s timeout when nil
+func NewVault(addr, token, namespace string, client *http.Client) *Vault {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Vault{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		namespace: namespace,
+		client:    client,
+	}
+}
+
+// Scheme implements Provider.
+func (v *Vault) Scheme() string {
+	return VaultScheme
+}
+
+// Get implements Provider.
+func (v *Vault) Get(ctx context.Context, ref string) (string, error) {
+	if v.addr == "" {
+		return "", errors.New("vault address is not configured")
+	}
+
+	secretPath, key, ok := strings.Cut(ref, "#")
+	mount, name, hasName := strings.Cut(strings.Trim(secretPath, "/"), "/")
+	if !ok || key == "" || !hasName || mount == "" || name == "" {
+		return "", fmt.Errorf("expected mount/path#key, got %q", ref)
+	}
+
+	endpoint := v.addr + "/v1/" + url.PathEscape(mount) + "/data/" + escapePath(name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: %s", ErrNotFound, secretPath)
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("failed to read secret %s: status %d", secretPath, resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode secret %s: %w", secretPath, err)
+	}
+
+	value, ok := body.Data.Data[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s#%s", ErrNotFound, secretPath, key)
+	}
+	secret, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("secret %s#%s is not a string", secretPath, key)
+	}
+	return secret, nil
+}
+
+// escapePath escapes every segment of a slash separated path.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
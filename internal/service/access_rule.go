@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/database"
+)
+
+// Login access rule effects
+const (
+	AccessAllow = "ALLOW"
+	AccessDeny  = "DENY"
+)
+
+// AccessRuleService handles the staff managed allow/deny list for 42 logins.
+type AccessRuleService struct {
+	db    *database.DB
+	audit *audit.Service
+}
+
+// SetAccessRuleInput contains the input parameters for allowing or denying a login.
+type SetAccessRuleInput struct {
+	Login     string
+	Effect    string
+	Reason    string
+	CreatedBy int64
+}
+
+// NewAccessRuleService create dependencies for AccessRuleService.
+func NewAccessRuleService(db *database.DB, auditService *audit.Service) *AccessRuleService {
+	return &AccessRuleService{
+		db:    db,
+		audit: auditService,
+	}
+}
+
+// NormalizeLogin returns the form logins are stored in access rules.
+func NormalizeLogin(login string) string {
+	return strings.ToLower(strings.TrimSpace(login))
+}
+
+// ListRules returns all login access rules.
+func (s *AccessRuleService) ListRules(ctx context.Context) ([]database.LoginAccessRule, error) {
+	rules, err := s.db.ListLoginAccessRules(ctx)
+	if err != nil {
+		slog.Error("failed to list access rules", "error", err)
+		return nil, ErrAccessRuleFetchFailed
+	}
+	return rules, nil
+}
+
+// SetRule creates or replaces the access rule for a login.
+func (s *AccessRuleService) SetRule(
+	ctx context.Context,
+	input SetAccessRuleInput,
+) (database.LoginAccessRule, error) {
+
+	login := NormalizeLogin(input.Login)
+
+	// Previous rule, if any, for the audit log
+	var before any
+	previous, err := s.db.GetLoginAccessRule(ctx, login)
+	switch {
+	case err == nil:
+		before = accessRuleSnapshot(previous)
+	case !errors.Is(err, sql.ErrNoRows):
+		slog.Error("failed to get access rule", "error", err)
+		return database.LoginAccessRule{}, ErrAccessRuleSaveFailed
+	}
+
+	rule, err := s.db.UpsertLoginAccessRule(ctx, database.UpsertLoginAccessRuleParams{
+		Login:     login,
+		Effect:    input.Effect,
+		Reason:    input.Reason,
+		CreatedBy: sql.NullInt64{Int64: input.CreatedBy, Valid: input.CreatedBy != 0},
+	})
+	if err != nil {
+		slog.Error("failed to save access rule", "error", err)
+		return database.LoginAccessRule{}, ErrAccessRuleSaveFailed
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.AccessRuleSet,
+		ActorID:    input.CreatedBy,
+		TargetType: audit.TargetAccessRule,
+		TargetID:   rule.Login,
+		Before:     before,
+		After:      accessRuleSnapshot(rule),
+	})
+
+	slog.Info("login access rule set",
+		"login", rule.Login,
+		"effect", rule.Effect,
+		"by", input.CreatedBy,
+	)
+	return rule, nil
+}
+
+// DeleteRule removes the access rule for a login.
+func (s *AccessRuleService) DeleteRule(ctx context.Context, login string, deletedBy int64) error {
+	rule, err := s.db.GetLoginAccessRule(ctx, NormalizeLogin(login))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrAccessRuleNotFound
+		}
+		return err
+	}
+
+	n, err := s.db.DeleteLoginAccessRule(ctx, rule.Login)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAccessRuleNotFound
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.AccessRuleDelete,
+		ActorID:    deletedBy,
+		TargetType: audit.TargetAccessRule,
+		TargetID:   rule.Login,
+		Before:     accessRuleSnapshot(rule),
+	})
+	return nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/schedule"
+)
+
+// Snapshots of records stored as before/after state in the audit log.
+
+func reservationSnapshot(r database.Reservation) map[string]any {
+	return map[string]any{
+		"id":        r.ID,
+		"userId":    r.UserID,
+		"roomId":    r.RoomID,
+		"startTime": r.StartTime.UTC(),
+		"endTime":   r.EndTime.UTC(),
+		"status":    r.Status,
+	}
+}
+
+func roleAssignmentSnapshot(a database.RoleAssignment) map[string]any {
+	return map[string]any{
+		"id":     a.ID,
+		"userId": a.UserID,
+		"role":   a.Role,
+		"roomId": a.RoomID.Int64,
+	}
+}
+
+func accessRuleSnapshot(r database.LoginAccessRule) map[string]any {
+	return map[string]any{
+		"login":  r.Login,
+		"effect": r.Effect,
+		"reason": r.Reason,
+	}
+}
+
+func apiTokenSnapshot(t database.ApiToken) map[string]any {
+	return map[string]any{
+		"id":        t.ID,
+		"name":      t.Name,
+		"prefix":    t.TokenPrefix,
+		"scopes":    t.Scopes,
+		"expiresAt": t.ExpiresAt.UTC(),
+	}
+}
+
+func displayDeviceSnapshot(d database.DisplayDevice) map[string]any {
+	return map[string]any{
+		"id":     d.ID,
+		"roomId": d.RoomID,
+		"name":   d.Name,
+		"prefix": d.TokenPrefix,
+	}
+}
+
+func campusSnapshot(c database.Campus) map[string]any {
+	return map[string]any{
+		"id":             c.ID,
+		"slug":           c.Slug,
+		"name":           c.Name,
+		"intraCampusId":  c.IntraCampusID.Int32,
+		"timezone":       c.Timezone,
+		"openMinute":     c.OpenMinute,
+		"closeMinute":    c.CloseMinute,
+		"loginProviders": c.LoginProviders,
+		"calendarId":     c.CalendarID,
+		"corsOrigins":    c.CorsOrigins,
+	}
+}
+
+func roomSnapshot(r database.Room) map[string]any {
+	return map[string]any{
+		"id":       r.ID,
+		"campusId": r.CampusID,
+		"name":     r.Name,
+	}
+}
+
+// userSnapshot leaves out personal data, the audit log is append-only and
+// must not keep what anonymizing a user removes.
+func userSnapshot(u database.User) map[string]any {
+	return map[string]any{
+		"id":              u.ID,
+		"role":            u.Role,
+		"suspended":       u.SuspendedAt.Valid,
+		"suspendedReason": u.SuspendedReason,
+	}
+}
+
+func blackoutSnapshot(b database.RoomBlackout) map[string]any {
+	snapshot := map[string]any{
+		"id":         b.ID,
+		"roomId":     b.RoomID,
+		"reason":     b.Reason,
+		"startTime":  b.StartTime.UTC(),
+		"endTime":    b.EndTime.UTC(),
+		"recurrence": b.Recurrence,
+	}
+	if b.RepeatUntil.Valid {
+		snapshot["repeatUntil"] = b.RepeatUntil.Time.UTC()
+	}
+	return snapshot
+}
+
+func openingHoursSnapshot(o database.OpeningHoursException) map[string]any {
+	return map[string]any{
+		"date":   o.Date.Format(schedule.DateLayout),
+		"closed": o.Closed,
+		"open":   schedule.FormatMinute(int(o.OpenMinute)),
+		"close":  schedule.FormatMinute(int(o.CloseMinute)),
+		"name":   o.Name,
+	}
+}
+
+// lockoutSnapshot leaves out the subject, an account's login or an IP, so
+// the audit log keeps nothing anonymizing the user would remove.
+func lockoutSnapshot(l database.LoginLockout) map[string]any {
+	snapshot := map[string]any{
+		"id":          l.ID,
+		"kind":        l.Kind,
+		"failures":    l.Failures,
+		"lockouts":    l.Lockouts,
+		"last_reason": l.LastReason,
+	}
+	if l.LockedUntil.Valid {
+		snapshot["locked_until"] = l.LockedUntil.Time.UTC()
+	}
+	return snapshot
+}
@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/campus"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/email"
+	"github.com/IbnBaqqi/book-me/internal/google"
+	"github.com/IbnBaqqi/book-me/internal/integration"
+	"github.com/IbnBaqqi/book-me/internal/logger"
+	"github.com/IbnBaqqi/book-me/internal/metrics"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+	"github.com/IbnBaqqi/book-me/internal/tracing"
+)
+
+// Blackout recurrences
+const (
+	RecurrenceNone   = "NONE"
+	RecurrenceDaily  = "DAILY"
+	RecurrenceWeekly = "WEEKLY"
+)
+
+// BlackoutService handles the periods a room cannot be booked.
+type BlackoutService struct {
+	db       *database.DB
+	email    *integration.Optional[email.Sender]
+	calendar *integration.Optional[google.Calendar]
+	audit    *audit.Service
+	campuses *campus.Registry
+}
+
+// CreateBlackoutInput contains the input parameters for blacking out a room.
+type CreateBlackoutInput struct {
+	RoomID      int64
+	Reason      string
+	StartTime   time.Time
+	EndTime     time.Time
+	Recurrence  string
+	RepeatUntil time.Time // zero for one-off blackouts
+	CreatedBy   int64
+	CampusID    int64 // campus of the user, the room must belong to it
+	Grants      rbac.Grants
+}
+
+// BlackoutPeriod is a single occurrence of a blackout.
+type BlackoutPeriod struct {
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// NewBlackoutService create dependencies for BlackoutService.
+func NewBlackoutService(
+	db *database.DB,
+	emailService *integration.Optional[email.Sender],
+	calendarService *integration.Optional[google.Calendar],
+	auditService *audit.Service,
+	campuses *campus.Registry,
+) *BlackoutService {
+	return &BlackoutService{
+		db:       db,
+		email:    emailService,
+		calendar: calendarService,
+		audit:    auditService,
+		campuses: campuses,
+	}
+}
+
+// CreateBlackout blacks out a room. Reservations inside any occurrence of the
+// blackout are cancelled and their owners notified. It returns the blackout
+// and the cancelled reservations.
+func (s *BlackoutService) CreateBlackout(
+	ctx context.Context,
+	input CreateBlackoutInput,
+) (database.RoomBlackout, []database.Reservation, error) {
+
+	if !input.Grants.Can(rbac.RoomsManage, input.RoomID) {
+		return database.RoomBlackout{}, nil, ErrUnauthorized
+	}
+
+	if input.Recurrence == "" {
+		input.Recurrence = RecurrenceNone
+	}
+	if err := validateRecurrence(input); err != nil {
+		return database.RoomBlackout{}, nil, err
+	}
+
+	room, err := s.db.GetRoomByID(ctx, input.RoomID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.RoomBlackout{}, nil, ErrRoomNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get room", "room_id", input.RoomID, "error", err)
+		return database.RoomBlackout{}, nil, ErrBlackoutFailed
+	}
+
+	// Rooms of other campuses are managed by their own campus staff
+	if room.CampusID != input.CampusID && !input.Grants.Has(rbac.CampusesManage) {
+		return database.RoomBlackout{}, nil, ErrRoomNotFound
+	}
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelReadCommitted,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to start transaction", "error", err)
+		return database.RoomBlackout{}, nil, ErrBlackoutFailed
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := s.db.WithTx(tx.Tx)
+
+	// Bookings of the room wait for the blackout, so none is made inside
+	// it after the reservations below are listed
+	if _, err := qtx.LockRoom(ctx, input.RoomID); err != nil {
+		logger.FromContext(ctx).Error("failed to lock room", "room_id", input.RoomID, "error", err)
+		return database.RoomBlackout{}, nil, ErrBlackoutFailed
+	}
+
+	blackout, err := qtx.CreateRoomBlackout(ctx, database.CreateRoomBlackoutParams{
+		RoomID:      input.RoomID,
+		Reason:      input.Reason,
+		StartTime:   input.StartTime,
+		EndTime:     input.EndTime,
+		Recurrence:  input.Recurrence,
+		RepeatUntil: sql.NullTime{Time: input.RepeatUntil, Valid: !input.RepeatUntil.IsZero()},
+		CreatedBy:   sql.NullInt64{Int64: input.CreatedBy, Valid: input.CreatedBy != 0},
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to create room blackout", "room_id", input.RoomID, "error", err)
+		return database.RoomBlackout{}, nil, ErrBlackoutFailed
+	}
+
+	// Lock the reservations in the whole span of the blackout and cancel
+	// the ones inside an occurrence
+	from, to := blackoutSpan(blackout)
+	candidates, err := qtx.ListReservationsByRoomBetween(ctx, database.ListReservationsByRoomBetweenParams{
+		RoomID:   input.RoomID,
+		FromTime: from,
+		ToTime:   to,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list reservations in blackout", "room_id", input.RoomID, "error", err)
+		return database.RoomBlackout{}, nil, ErrBlackoutFailed
+	}
+
+	home := s.campuses.Resolve(room.CampusID)
+	periods := expandBlackout(blackout, home.Location, from, to)
+	cancelled := make([]database.Reservation, 0)
+	for _, r := range candidates {
+		if !overlapsAny(periods, r.StartTime, r.EndTime) {
+			continue
+		}
+		if err := qtx.DeleteReservation(ctx, r.ID); err != nil {
+			logger.FromContext(ctx).Error("failed to cancel reservation in blackout", "reservation_id", r.ID, "error", err)
+			return database.RoomBlackout{}, nil, ErrBlackoutFailed
+		}
+		cancelled = append(cancelled, r)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.FromContext(ctx).Error("failed to commit transaction", "error", err)
+		return database.RoomBlackout{}, nil, ErrBlackoutFailed
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.RoomBlackoutAdd,
+		ActorID:    input.CreatedBy,
+		RoomID:     blackout.RoomID,
+		TargetType: audit.TargetRoomBlackout,
+		TargetID:   strconv.FormatInt(blackout.ID, 10),
+		After:      blackoutSnapshot(blackout),
+	})
+
+	for _, r := range cancelled {
+		s.audit.Record(ctx, audit.Entry{
+			Action:     audit.ReservationCancel,
+			ActorID:    input.CreatedBy,
+			UserID:     r.UserID,
+			RoomID:     r.RoomID,
+			TargetType: audit.TargetReservation,
+			TargetID:   strconv.FormatInt(r.ID, 10),
+			Before:     reservationSnapshot(r),
+		})
+	}
+
+	metrics.ReservationsCancelled.WithLabelValues(metrics.CancelBlackout).Add(float64(len(cancelled)))
+	s.notifyCancelled(ctx, home, room.Name, blackout.Reason, cancelled)
+
+	logger.FromContext(ctx).Info("room blackout created",
+		"blackout_id", blackout.ID,
+		"room_id", blackout.RoomID,
+		"cancelled", len(cancelled),
+		"by", input.CreatedBy,
+	)
+	return blackout, cancelled, nil
+}
+
+// ListBlackouts returns the blackouts of a room.
+func (s *BlackoutService) ListBlackouts(ctx context.Context, roomID, campusID int64, grants rbac.Grants) ([]database.RoomBlackout, error) {
+	if !grants.Can(rbac.RoomsManage, roomID) {
+		return nil, ErrUnauthorized
+	}
+
+	if err := s.checkRoom(ctx, roomID, campusID, grants); err != nil {
+		return nil, err
+	}
+
+	blackouts, err := s.db.ListRoomBlackoutsByRoom(ctx, roomID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list room blackouts", "room_id", roomID, "error", err)
+		return nil, ErrBlackoutFailed
+	}
+	return blackouts, nil
+}
+
+// DeleteBlackout removes a blackout, the room can be booked again.
+// Reservations cancelled by it are not restored.
+func (s *BlackoutService) DeleteBlackout(ctx context.Context, roomID, blackoutID, deletedBy, campusID int64, grants rbac.Grants) error {
+	if !grants.Can(rbac.RoomsManage, roomID) {
+		return ErrUnauthorized
+	}
+
+	if err := s.checkRoom(ctx, roomID, campusID, grants); err != nil {
+		return err
+	}
+
+	blackout, err := s.db.GetRoomBlackout(ctx, database.GetRoomBlackoutParams{
+		ID:     blackoutID,
+		RoomID: roomID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrBlackoutNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get room blackout", "blackout_id", blackoutID, "error", err)
+		return ErrBlackoutFailed
+	}
+
+	rows, err := s.db.DeleteRoomBlackout(ctx, database.DeleteRoomBlackoutParams{
+		ID:     blackoutID,
+		RoomID: roomID,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to delete room blackout", "blackout_id", blackoutID, "error", err)
+		return ErrBlackoutFailed
+	}
+	if rows == 0 {
+		return ErrBlackoutNotFound
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.RoomBlackoutDelete,
+		ActorID:    deletedBy,
+		RoomID:     roomID,
+		TargetType: audit.TargetRoomBlackout,
+		TargetID:   strconv.FormatInt(blackoutID, 10),
+		Before:     blackoutSnapshot(blackout),
+	})
+
+	logger.FromContext(ctx).Info("room blackout deleted", "blackout_id", blackoutID, "room_id", roomID)
+	return nil
+}
+
+// checkRoom returns ErrRoomNotFound if the room does not exist or belongs to
+// another campus than the caller's, unless the caller manages all campuses.
+func (s *BlackoutService) checkRoom(ctx context.Context, roomID, campusID int64, grants rbac.Grants) error {
+	room, err := s.db.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRoomNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get room", "room_id", roomID, "error", err)
+		return ErrBlackoutFailed
+	}
+	if room.CampusID != campusID && !grants.Has(rbac.CampusesManage) {
+		return ErrRoomNotFound
+	}
+	return nil
+}
+
+// notifyCancelled removes the calendar events of reservations cancelled by
+// a blackout and emails their owners.
+func (s *BlackoutService) notifyCancelled(ctx context.Context, home *campus.Campus, roomName, reason string, cancelled []database.Reservation) {
+	for _, r := range cancelled {
+		go func(r database.Reservation) {
+			ctx, cancel := context.WithTimeout(tracing.Detach(ctx), 40*time.Second)
+			defer cancel()
+
+			if r.GcalEventID.Valid {
+				err := s.calendar.Do(ctx, "delete event", func(ctx context.Context, calendar google.Calendar) error {
+					return calendar.DeleteGoogleEvent(ctx, home.CalendarID, r.GcalEventID.String)
+				})
+				if err != nil {
+					logger.FromContext(ctx).Error("failed to delete google calendar event", "error", err)
+				}
+			}
+
+			owner, err := s.db.GetUser(ctx, r.UserID)
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to get reservation owner", "user_id", r.UserID, "error", err)
+				return
+			}
+
+			err = s.email.Do(ctx, "send cancellation", func(ctx context.Context, sender email.Sender) error {
+				return sender.SendCancellation(
+					ctx,
+					owner.Email,
+					roomName,
+					r.StartTime.In(home.Location).Format("Monday, January 2, 2006 at 3:04 PM"),
+					r.EndTime.In(home.Location).Format("Monday, January 2, 2006 at 3:04 PM"),
+					reason,
+				)
+			})
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to send cancellation email", "error", err)
+			}
+		}(r)
+	}
+}
+
+// maxRecurrenceSpan bounds how long a blackout may recur, which bounds the
+// occurrences expanded when it is created or checked.
+const maxRecurrenceSpan = 366 * 24 * time.Hour
+
+// validateRecurrence checks repeatUntil is set exactly when the blackout
+// recurs, at most a year after its start, and that occurrences do not run
+// into each other.
+func validateRecurrence(input CreateBlackoutInput) error {
+	step, recurring := recurrenceStep(input.Recurrence)
+	if !recurring {
+		if !input.RepeatUntil.IsZero() {
+			return ErrInvalidRecurrence
+		}
+		return nil
+	}
+
+	if input.RepeatUntil.Before(input.StartTime) {
+		return ErrInvalidRecurrence
+	}
+	if input.RepeatUntil.Sub(input.StartTime) > maxRecurrenceSpan {
+		return ErrRecurrenceTooLong
+	}
+	if input.EndTime.Sub(input.StartTime) >= time.Duration(step)*24*time.Hour {
+		return ErrInvalidRecurrence
+	}
+	return nil
+}
+
+// recurrenceStep returns the number of days between occurrences.
+func recurrenceStep(recurrence string) (int, bool) {
+	switch recurrence {
+	case RecurrenceDaily:
+		return 1, true
+	case RecurrenceWeekly:
+		return 7, true
+	default:
+		return 0, false
+	}
+}
+
+// blackoutSpan returns the time from the start of the first occurrence
+// to the end of the last one.
+func blackoutSpan(b database.RoomBlackout) (time.Time, time.Time) {
+	if _, recurring := recurrenceStep(b.Recurrence); !recurring || !b.RepeatUntil.Valid {
+		return b.StartTime, b.EndTime
+	}
+	return b.StartTime, b.RepeatUntil.Time.Add(b.EndTime.Sub(b.StartTime))
+}
+
+// expandBlackout returns the occurrences of a blackout overlapping [from, to).
+// Recurring blackouts repeat at the same wall-clock time in loc, the campus
+// timezone, so a daily 09:00 blackout stays at 09:00 across daylight saving
+// changes.
+func expandBlackout(b database.RoomBlackout, loc *time.Location, from, to time.Time) []BlackoutPeriod {
+	step, recurring := recurrenceStep(b.Recurrence)
+	if !recurring || !b.RepeatUntil.Valid {
+		if b.StartTime.Before(to) && b.EndTime.After(from) {
+			return []BlackoutPeriod{{StartTime: b.StartTime, EndTime: b.EndTime}}
+		}
+		return nil
+	}
+
+	start := b.StartTime.In(loc)
+	end := b.EndTime.In(loc)
+
+	// Skip the occurrences ending before from, one step early as days
+	// around daylight saving changes are an hour shorter or longer
+	first := 0
+	if stepLength := time.Duration(step) * 24 * time.Hour; from.After(end) {
+		first = max(int(from.Sub(end)/stepLength)-1, 0)
+	}
+
+	var periods []BlackoutPeriod
+	for i := first; ; i++ {
+		s := start.AddDate(0, 0, i*step)
+		if s.After(b.RepeatUntil.Time) || !s.Before(to) {
+			break
+		}
+		e := end.AddDate(0, 0, i*step)
+		if e.After(from) {
+			periods = append(periods, BlackoutPeriod{StartTime: s.UTC(), EndTime: e.UTC()})
+		}
+	}
+	return periods
+}
+
+// overlapsAny reports whether [start, end) overlaps any of the periods.
+func overlapsAny(periods []BlackoutPeriod, start, end time.Time) bool {
+	for _, p := range periods {
+		if p.StartTime.Before(end) && p.EndTime.After(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// blackoutFromRow converts a blackout listed together with its room name.
+func blackoutFromRow(row database.ListRoomBlackoutsBetweenRow) database.RoomBlackout {
+	return database.RoomBlackout{
+		ID:          row.ID,
+		RoomID:      row.RoomID,
+		Reason:      row.Reason,
+		StartTime:   row.StartTime,
+		EndTime:     row.EndTime,
+		Recurrence:  row.Recurrence,
+		RepeatUntil: row.RepeatUntil,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+	}
+}
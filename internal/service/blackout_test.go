@@ -0,0 +1,181 @@
+package service
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/database"
+)
+
+func TestExpandBlackout(t *testing.T) {
+	helsinki, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Fatalf("failed to load timezone: %v", err)
+	}
+
+	// Helsinki local time
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2026, month, day, hour, 0, 0, 0, helsinki)
+	}
+	until := func(t time.Time) sql.NullTime {
+		return sql.NullTime{Time: t, Valid: true}
+	}
+
+	tests := []struct {
+		name     string
+		blackout database.RoomBlackout
+		from     time.Time
+		to       time.Time
+		want     []BlackoutPeriod
+	}{
+		{
+			name: "one-off inside range",
+			blackout: database.RoomBlackout{
+				StartTime:  at(3, 2, 9),
+				EndTime:    at(3, 2, 12),
+				Recurrence: RecurrenceNone,
+			},
+			from: at(3, 1, 0),
+			to:   at(3, 8, 0),
+			want: []BlackoutPeriod{{StartTime: at(3, 2, 9).UTC(), EndTime: at(3, 2, 12).UTC()}},
+		},
+		{
+			name: "one-off outside range",
+			blackout: database.RoomBlackout{
+				StartTime:  at(3, 2, 9),
+				EndTime:    at(3, 2, 12),
+				Recurrence: RecurrenceNone,
+			},
+			from: at(3, 2, 12),
+			to:   at(3, 8, 0),
+		},
+		{
+			name: "daily clipped to range",
+			blackout: database.RoomBlackout{
+				StartTime:   at(3, 2, 9),
+				EndTime:     at(3, 2, 10),
+				Recurrence:  RecurrenceDaily,
+				RepeatUntil: until(at(3, 10, 9)),
+			},
+			from: at(3, 4, 0),
+			to:   at(3, 6, 0),
+			want: []BlackoutPeriod{
+				{StartTime: at(3, 4, 9).UTC(), EndTime: at(3, 4, 10).UTC()},
+				{StartTime: at(3, 5, 9).UTC(), EndTime: at(3, 5, 10).UTC()},
+			},
+		},
+		{
+			name: "daily stops at repeat until",
+			blackout: database.RoomBlackout{
+				StartTime:   at(3, 2, 9),
+				EndTime:     at(3, 2, 10),
+				Recurrence:  RecurrenceDaily,
+				RepeatUntil: until(at(3, 3, 9)),
+			},
+			from: at(3, 1, 0),
+			to:   at(3, 8, 0),
+			want: []BlackoutPeriod{
+				{StartTime: at(3, 2, 9).UTC(), EndTime: at(3, 2, 10).UTC()},
+				{StartTime: at(3, 3, 9).UTC(), EndTime: at(3, 3, 10).UTC()},
+			},
+		},
+		{
+			name: "daily starts at the occurrences after from",
+			blackout: database.RoomBlackout{
+				StartTime:   at(1, 5, 9),
+				EndTime:     at(1, 5, 10),
+				Recurrence:  RecurrenceDaily,
+				RepeatUntil: until(at(12, 31, 0)),
+			},
+			from: at(3, 29, 0),
+			to:   at(3, 31, 0),
+			want: []BlackoutPeriod{
+				{StartTime: at(3, 29, 9).UTC(), EndTime: at(3, 29, 10).UTC()},
+				{StartTime: at(3, 30, 9).UTC(), EndTime: at(3, 30, 10).UTC()},
+			},
+		},
+		{
+			name: "weekly keeps wall-clock time across daylight saving",
+			blackout: database.RoomBlackout{
+				StartTime:   at(3, 23, 9),
+				EndTime:     at(3, 23, 11),
+				Recurrence:  RecurrenceWeekly,
+				RepeatUntil: until(at(4, 30, 0)),
+			},
+			from: at(3, 24, 0),
+			to:   at(4, 7, 0),
+			want: []BlackoutPeriod{
+				{StartTime: at(3, 30, 9).UTC(), EndTime: at(3, 30, 11).UTC()},
+				{StartTime: at(4, 6, 9).UTC(), EndTime: at(4, 6, 11).UTC()},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := expandBlackout(tt.blackout, helsinki, tt.from, tt.to)
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %d periods, got %d: %+v", len(tt.want), len(got), got)
+			}
+			for i := range got {
+				if !got[i].StartTime.Equal(tt.want[i].StartTime) || !got[i].EndTime.Equal(tt.want[i].EndTime) {
+					t.Errorf("period %d: expected %+v, got %+v", i, tt.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateRecurrence(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		input   CreateBlackoutInput
+		wantErr bool
+	}{
+		{
+			name:  "one-off",
+			input: CreateBlackoutInput{StartTime: start, EndTime: start.Add(72 * time.Hour), Recurrence: RecurrenceNone},
+		},
+		{
+			name:    "one-off with repeat until",
+			input:   CreateBlackoutInput{StartTime: start, EndTime: start.Add(time.Hour), Recurrence: RecurrenceNone, RepeatUntil: start.AddDate(0, 1, 0)},
+			wantErr: true,
+		},
+		{
+			name:  "weekly",
+			input: CreateBlackoutInput{StartTime: start, EndTime: start.Add(time.Hour), Recurrence: RecurrenceWeekly, RepeatUntil: start.AddDate(0, 1, 0)},
+		},
+		{
+			name:    "daily without repeat until",
+			input:   CreateBlackoutInput{StartTime: start, EndTime: start.Add(time.Hour), Recurrence: RecurrenceDaily},
+			wantErr: true,
+		},
+		{
+			name:  "daily for a year",
+			input: CreateBlackoutInput{StartTime: start, EndTime: start.Add(time.Hour), Recurrence: RecurrenceDaily, RepeatUntil: start.AddDate(1, 0, 0)},
+		},
+		{
+			name:    "daily for more than a year",
+			input:   CreateBlackoutInput{StartTime: start, EndTime: start.Add(time.Hour), Recurrence: RecurrenceDaily, RepeatUntil: start.AddDate(1, 1, 0)},
+			wantErr: true,
+		},
+		{
+			name:    "daily longer than a day",
+			input:   CreateBlackoutInput{StartTime: start, EndTime: start.Add(25 * time.Hour), Recurrence: RecurrenceDaily, RepeatUntil: start.AddDate(0, 1, 0)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRecurrence(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
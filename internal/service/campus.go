@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/campus"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/schedule"
+)
+
+// CampusService manages campuses and their rooms. Changes are loaded into
+// the campus registry shared by logins, reservations and CORS.
+type CampusService struct {
+	db       *database.DB
+	campuses *campus.Registry
+	audit    *audit.Service
+}
+
+// CampusInput contains the settings of a campus. The slug is ignored on update.
+type CampusInput struct {
+	Slug           string
+	Name           string
+	IntraCampusID  int // 0 when 42 logins are not matched to the campus
+	Timezone       string
+	Open           int // regular hours, minutes after midnight
+	Close          int
+	LoginProviders []string
+	CalendarID     string
+	CORSOrigins    []string
+	ActorID        int64
+}
+
+// CreateRoomInput contains the input parameters for adding a room to a campus.
+type CreateRoomInput struct {
+	CampusID  int64
+	Name      string
+	CreatedBy int64
+}
+
+// NewCampusService create dependencies for CampusService.
+func NewCampusService(
+	db *database.DB,
+	campuses *campus.Registry,
+	auditService *audit.Service,
+) *CampusService {
+	return &CampusService{
+		db:       db,
+		campuses: campuses,
+		audit:    auditService,
+	}
+}
+
+// ListCampuses returns all campuses ordered by ID.
+func (s *CampusService) ListCampuses(ctx context.Context) ([]database.Campus, error) {
+	rows, err := s.db.ListCampuses(ctx)
+	if err != nil {
+		slog.Error("failed to list campuses", "error", err)
+		return nil, ErrCampusFailed
+	}
+	return rows, nil
+}
+
+// CreateCampus adds a campus.
+func (s *CampusService) CreateCampus(ctx context.Context, input CampusInput) (database.Campus, error) {
+	if err := validateCampus(input); err != nil {
+		return database.Campus{}, err
+	}
+
+	row, err := s.db.CreateCampus(ctx, database.CreateCampusParams{
+		Slug:           input.Slug,
+		Name:           input.Name,
+		IntraCampusID:  intraCampusID(input.IntraCampusID),
+		Timezone:       input.Timezone,
+		OpenMinute:     int32(input.Open),  //nolint:gosec // validated to be within a day
+		CloseMinute:    int32(input.Close), //nolint:gosec // validated to be within a day
+		LoginProviders: nonNil(input.LoginProviders),
+		CalendarID:     input.CalendarID,
+		CorsOrigins:    nonNil(input.CORSOrigins),
+	})
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return database.Campus{}, ErrCampusTaken
+		}
+		slog.Error("failed to create campus", "error", err)
+		return database.Campus{}, ErrCampusFailed
+	}
+
+	s.reload(ctx)
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.CampusCreate,
+		ActorID:    input.ActorID,
+		CampusID:   row.ID,
+		TargetType: audit.TargetCampus,
+		TargetID:   strconv.FormatInt(row.ID, 10),
+		After:      campusSnapshot(row),
+	})
+
+	slog.Info("campus created", "campus_id", row.ID, "slug", row.Slug, "by", input.ActorID)
+	return row, nil
+}
+
+// UpdateCampus replaces the settings of a campus.
+func (s *CampusService) UpdateCampus(ctx context.Context, id int64, input CampusInput) (database.Campus, error) {
+	previous, err := s.db.GetCampus(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.Campus{}, ErrCampusNotFound
+		}
+		slog.Error("failed to get campus", "error", err)
+		return database.Campus{}, ErrCampusFailed
+	}
+
+	input.Slug = previous.Slug
+	if err := validateCampus(input); err != nil {
+		return database.Campus{}, err
+	}
+
+	row, err := s.db.UpdateCampus(ctx, database.UpdateCampusParams{
+		ID:             id,
+		Name:           input.Name,
+		IntraCampusID:  intraCampusID(input.IntraCampusID),
+		Timezone:       input.Timezone,
+		OpenMinute:     int32(input.Open),  //nolint:gosec // validated to be within a day
+		CloseMinute:    int32(input.Close), //nolint:gosec // validated to be within a day
+		LoginProviders: nonNil(input.LoginProviders),
+		CalendarID:     input.CalendarID,
+		CorsOrigins:    nonNil(input.CORSOrigins),
+	})
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return database.Campus{}, ErrCampusTaken
+		}
+		slog.Error("failed to update campus", "campus_id", id, "error", err)
+		return database.Campus{}, ErrCampusFailed
+	}
+
+	s.reload(ctx)
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.CampusUpdate,
+		ActorID:    input.ActorID,
+		CampusID:   row.ID,
+		TargetType: audit.TargetCampus,
+		TargetID:   strconv.FormatInt(row.ID, 10),
+		Before:     campusSnapshot(previous),
+		After:      campusSnapshot(row),
+	})
+	return row, nil
+}
+
+// ListRooms returns the rooms of a campus.
+func (s *CampusService) ListRooms(ctx context.Context, campusID int64) ([]database.Room, error) {
+	rooms, err := s.db.ListRoomsByCampus(ctx, campusID)
+	if err != nil {
+		slog.Error("failed to list rooms", "campus_id", campusID, "error", err)
+		return nil, ErrCampusFailed
+	}
+	return rooms, nil
+}
+
+// CreateRoom adds a room to a campus.
+func (s *CampusService) CreateRoom(ctx context.Context, input CreateRoomInput) (database.Room, error) {
+	if _, ok := s.campuses.Get(input.CampusID); !ok {
+		return database.Room{}, ErrCampusNotFound
+	}
+
+	room, err := s.db.CreateRoom(ctx, database.CreateRoomParams{
+		CampusID: input.CampusID,
+		Name:     input.Name,
+	})
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return database.Room{}, ErrRoomNameTaken
+		}
+		slog.Error("failed to create room", "campus_id", input.CampusID, "error", err)
+		return database.Room{}, ErrCampusFailed
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.RoomCreate,
+		ActorID:    input.CreatedBy,
+		RoomID:     room.ID,
+		TargetType: audit.TargetRoom,
+		TargetID:   strconv.FormatInt(room.ID, 10),
+		After:      roomSnapshot(room),
+	})
+
+	slog.Info("room created", "room_id", room.ID, "campus_id", room.CampusID, "by", input.CreatedBy)
+	return room, nil
+}
+
+// reload refreshes the campus registry after a change. The change is
+// already stored, so a failure only delays it until the next successful load.
+func (s *CampusService) reload(ctx context.Context) {
+	if err := s.campuses.Load(ctx); err != nil {
+		slog.Error("failed to reload campuses", "error", err)
+	}
+}
+
+// validateCampus checks the settings a campus is loaded with, so a bad
+// update cannot stop the registry from loading.
+func validateCampus(input CampusInput) error {
+	if _, err := time.LoadLocation(input.Timezone); err != nil {
+		return ErrInvalidCampus
+	}
+
+	// Only special hours run overnight, regular ones close by midnight
+	hours := schedule.Hours{Open: input.Open, Close: input.Close}
+	if err := hours.Validate(); err != nil || hours.Close > 24*60 {
+		return ErrInvalidOpeningHours
+	}
+
+	for _, p := range input.LoginProviders {
+		if p != campus.Provider42 && p != campus.ProviderKeycloak {
+			return ErrInvalidCampus
+		}
+	}
+	return nil
+}
+
+func intraCampusID(id int) sql.NullInt32 {
+	return sql.NullInt32{Int32: int32(id), Valid: id != 0} //nolint:gosec // campus IDs are small
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return slices.Clone(s)
+}
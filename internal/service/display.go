@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/dto"
+	"github.com/IbnBaqqi/book-me/internal/logger"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+)
+
+// DisplayService handles room display devices and the status they show.
+type DisplayService struct {
+	db    *database.DB
+	audit *audit.Service
+}
+
+// CreateDisplayDeviceInput contains the input parameters for registering a device.
+type CreateDisplayDeviceInput struct {
+	RoomID    int64
+	Name      string
+	CreatedBy int64
+	CampusID  int64 // campus of the user, the room must belong to it
+	Grants    rbac.Grants
+}
+
+// NewDisplayService create dependencies for DisplayService.
+func NewDisplayService(db *database.DB, auditService *audit.Service) *DisplayService {
+	return &DisplayService{
+		db:    db,
+		audit: auditService,
+	}
+}
+
+// CreateDevice registers a display device for a room and returns it together
+// with its token. The token is not stored and cannot be shown again.
+func (s *DisplayService) CreateDevice(ctx context.Context, input CreateDisplayDeviceInput) (database.DisplayDevice, string, error) {
+	if !input.Grants.Can(rbac.RoomsManage, input.RoomID) {
+		return database.DisplayDevice{}, "", ErrUnauthorized
+	}
+
+	if err := s.checkRoom(ctx, input.RoomID, input.CampusID, input.Grants); err != nil {
+		return database.DisplayDevice{}, "", err
+	}
+
+	token, hash, prefix := auth.MakeDeviceToken()
+
+	device, err := s.db.CreateDisplayDevice(ctx, database.CreateDisplayDeviceParams{
+		RoomID:      input.RoomID,
+		Name:        input.Name,
+		TokenHash:   hash,
+		TokenPrefix: prefix,
+		CreatedBy:   sql.NullInt64{Int64: input.CreatedBy, Valid: input.CreatedBy != 0},
+	})
+	if err != nil {
+		if database.IsUniqueViolation(err) {
+			return database.DisplayDevice{}, "", ErrDisplayDeviceNameTaken
+		}
+		logger.FromContext(ctx).Error("failed to create display device", "room_id", input.RoomID, "error", err)
+		return database.DisplayDevice{}, "", ErrDisplayFailed
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.RoomDisplayAdd,
+		ActorID:    input.CreatedBy,
+		RoomID:     device.RoomID,
+		TargetType: audit.TargetDisplayDevice,
+		TargetID:   strconv.FormatInt(device.ID, 10),
+		After:      displayDeviceSnapshot(device),
+	})
+
+	logger.FromContext(ctx).Info("display device registered",
+		"device_id", device.ID,
+		"room_id", device.RoomID,
+		"by", input.CreatedBy,
+	)
+	return device, token, nil
+}
+
+// ListDevices returns the display devices registered for a room.
+func (s *DisplayService) ListDevices(ctx context.Context, roomID, campusID int64, grants rbac.Grants) ([]database.DisplayDevice, error) {
+	if !grants.Can(rbac.RoomsManage, roomID) {
+		return nil, ErrUnauthorized
+	}
+
+	if err := s.checkRoom(ctx, roomID, campusID, grants); err != nil {
+		return nil, err
+	}
+
+	devices, err := s.db.ListDisplayDevicesByRoom(ctx, roomID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list display devices", "room_id", roomID, "error", err)
+		return nil, ErrDisplayFailed
+	}
+	return devices, nil
+}
+
+// RevokeDevice deletes a display device, its token stops working immediately.
+func (s *DisplayService) RevokeDevice(ctx context.Context, roomID, deviceID, revokedBy, campusID int64, grants rbac.Grants) error {
+	if !grants.Can(rbac.RoomsManage, roomID) {
+		return ErrUnauthorized
+	}
+
+	if err := s.checkRoom(ctx, roomID, campusID, grants); err != nil {
+		return err
+	}
+
+	rows, err := s.db.DeleteDisplayDevice(ctx, database.DeleteDisplayDeviceParams{
+		ID:     deviceID,
+		RoomID: roomID,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to revoke display device", "device_id", deviceID, "error", err)
+		return ErrDisplayFailed
+	}
+	if rows == 0 {
+		return ErrDisplayDeviceNotFound
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.RoomDisplayRevoke,
+		ActorID:    revokedBy,
+		RoomID:     roomID,
+		TargetType: audit.TargetDisplayDevice,
+		TargetID:   strconv.FormatInt(deviceID, 10),
+	})
+
+	logger.FromContext(ctx).Info("display device revoked", "device_id", deviceID, "room_id", roomID)
+	return nil
+}
+
+// GetRoomDisplay returns whether the room is free now together with the
+// current and next booking. Booking owners are not shown on displays.
+func (s *DisplayService) GetRoomDisplay(ctx context.Context, roomID int64, grants rbac.Grants) (dto.RoomDisplayDto, error) {
+	if !grants.Can(rbac.RoomsDisplay, roomID) {
+		return dto.RoomDisplayDto{}, ErrUnauthorized
+	}
+
+	room, err := s.db.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return dto.RoomDisplayDto{}, ErrRoomNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get room", "room_id", roomID, "error", err)
+		return dto.RoomDisplayDto{}, ErrDisplayFailed
+	}
+
+	now := time.Now().UTC()
+
+	// The current booking, if any, and the one after it
+	upcoming, err := s.db.ListUpcomingReservationsByRoom(ctx, database.ListUpcomingReservationsByRoomParams{
+		RoomID:  roomID,
+		EndTime: now,
+		Limit:   2,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to fetch upcoming reservations", "room_id", roomID, "error", err)
+		return dto.RoomDisplayDto{}, ErrReservationFetchFailed
+	}
+
+	return roomDisplay(room, upcoming, now), nil
+}
+
+// roomDisplay builds the display status from the reservations that have not
+// ended yet, ordered by start time.
+func roomDisplay(room database.Room, upcoming []database.Reservation, now time.Time) dto.RoomDisplayDto {
+	result := dto.RoomDisplayDto{
+		RoomID:   room.ID,
+		RoomName: room.Name,
+		Free:     true,
+		Now:      now,
+	}
+
+	if len(upcoming) > 0 && !upcoming[0].StartTime.After(now) {
+		result.Free = false
+		result.Current = displaySlot(upcoming[0])
+		upcoming = upcoming[1:]
+	}
+	if len(upcoming) > 0 {
+		result.Next = displaySlot(upcoming[0])
+	}
+
+	return result
+}
+
+func displaySlot(r database.Reservation) *dto.DisplaySlotDto {
+	return &dto.DisplaySlotDto{
+		StartTime: r.StartTime.UTC(),
+		EndTime:   r.EndTime.UTC(),
+	}
+}
+
+// checkRoom returns ErrRoomNotFound if the room does not exist or belongs to
+// another campus than the caller's, unless the caller manages all campuses.
+func (s *DisplayService) checkRoom(ctx context.Context, roomID, campusID int64, grants rbac.Grants) error {
+	room, err := s.db.GetRoomByID(ctx, roomID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRoomNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get room", "room_id", roomID, "error", err)
+		return ErrDisplayFailed
+	}
+	if room.CampusID != campusID && !grants.Has(rbac.CampusesManage) {
+		return ErrRoomNotFound
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/database"
+)
+
+func TestRoomDisplay(t *testing.T) {
+	now := time.Date(2026, 2, 23, 10, 0, 0, 0, time.UTC)
+	room := database.Room{ID: 1, Name: "Big Room"}
+
+	booking := func(start, end int) database.Reservation {
+		return database.Reservation{
+			StartTime: now.Add(time.Duration(start) * time.Minute),
+			EndTime:   now.Add(time.Duration(end) * time.Minute),
+		}
+	}
+
+	tests := []struct {
+		name        string
+		upcoming    []database.Reservation
+		wantFree    bool
+		wantCurrent bool
+		wantNext    bool
+	}{
+		{
+			name:     "no bookings",
+			wantFree: true,
+		},
+		{
+			name:        "booked now with a booking after",
+			upcoming:    []database.Reservation{booking(-30, 30), booking(60, 120)},
+			wantCurrent: true,
+			wantNext:    true,
+		},
+		{
+			name:        "booking starting exactly now",
+			upcoming:    []database.Reservation{booking(0, 60)},
+			wantCurrent: true,
+		},
+		{
+			name:     "free until next booking",
+			upcoming: []database.Reservation{booking(15, 60), booking(60, 120)},
+			wantFree: true,
+			wantNext: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := roomDisplay(room, tt.upcoming, now)
+
+			if got.Free != tt.wantFree {
+				t.Errorf("expected free %v, got %v", tt.wantFree, got.Free)
+			}
+			if (got.Current != nil) != tt.wantCurrent {
+				t.Errorf("expected current %v, got %+v", tt.wantCurrent, got.Current)
+			}
+			if (got.Next != nil) != tt.wantNext {
+				t.Errorf("expected next %v, got %+v", tt.wantNext, got.Next)
+			}
+			if tt.wantNext && !tt.wantCurrent && !got.Next.StartTime.Equal(tt.upcoming[0].StartTime) {
+				t.Errorf("expected next to be the first upcoming booking, got %+v", got.Next)
+			}
+		})
+	}
+}
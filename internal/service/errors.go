@@ -61,4 +61,152 @@ var (
 		Message:    "unauthorized to cancel this reservation",
 		StatusCode: http.StatusForbidden,
 	}
+	ErrAccessRuleNotFound = &ServiceError{
+		Message:    "access rule not found",
+		StatusCode: http.StatusNotFound,
+	}
+	ErrAccessRuleFetchFailed = &ServiceError{
+		Message:    "failed to fetch access rules",
+		StatusCode: http.StatusInternalServerError,
+	}
+	ErrAccessRuleSaveFailed = &ServiceError{
+		Message:    "failed to save access rule",
+		StatusCode: http.StatusInternalServerError,
+	}
+	ErrUserNotFound = &ServiceError{
+		Message:    "user not found",
+		StatusCode: http.StatusNotFound,
+	}
+	ErrUnknownRole = &ServiceError{
+		Message:    "unknown role",
+		StatusCode: http.StatusBadRequest,
+	}
+	ErrRoleAlreadyAssigned = &ServiceError{
+		Message:    "role is already assigned to this user",
+		StatusCode: http.StatusConflict,
+	}
+	ErrRoleAssignmentNotFound = &ServiceError{
+		Message:    "role assignment not found",
+		StatusCode: http.StatusNotFound,
+	}
+	ErrAPITokenNotFound = &ServiceError{
+		Message:    "api token not found",
+		StatusCode: http.StatusNotFound,
+	}
+	ErrAPITokenNameTaken = &ServiceError{
+		Message:    "an api token with this name already exists",
+		StatusCode: http.StatusConflict,
+	}
+	ErrUnknownScope = &ServiceError{
+		Message:    "unknown token scope",
+		StatusCode: http.StatusBadRequest,
+	}
+	ErrAPITokenFailed = &ServiceError{
+		Message:    "failed to manage api tokens",
+		StatusCode: http.StatusInternalServerError,
+	}
+	ErrDisplayDeviceNotFound = &ServiceError{
+		Message:    "display device not found",
+		StatusCode: http.StatusNotFound,
+	}
+	ErrDisplayDeviceNameTaken = &ServiceError{
+		Message:    "a display device with this name already exists for the room",
+		StatusCode: http.StatusConflict,
+	}
+	ErrDisplayFailed = &ServiceError{
+		Message:    "failed to manage room displays",
+		StatusCode: http.StatusInternalServerError,
+	}
+	ErrUserFetchFailed = &ServiceError{
+		Message:    "failed to fetch users",
+		StatusCode: http.StatusInternalServerError,
+	}
+	ErrUserUpdateFailed = &ServiceError{
+		Message:    "failed to update user",
+		StatusCode: http.StatusInternalServerError,
+	}
+	ErrCannotManageSelf = &ServiceError{
+		Message:    "you cannot perform this action on your own account",
+		StatusCode: http.StatusBadRequest,
+	}
+	ErrUserAlreadySuspended = &ServiceError{
+		Message:    "user is already suspended",
+		StatusCode: http.StatusConflict,
+	}
+	ErrUserNotSuspended = &ServiceError{
+		Message:    "user is not suspended",
+		StatusCode: http.StatusConflict,
+	}
+	ErrRoomUnavailable = &ServiceError{
+		Message:    "the room is unavailable at this time",
+		StatusCode: http.StatusConflict,
+	}
+	ErrBlackoutNotFound = &ServiceError{
+		Message:    "blackout not found",
+		StatusCode: http.StatusNotFound,
+	}
+	ErrInvalidRecurrence = &ServiceError{
+		Message:    "recurring blackouts need a repeatUntil and must be shorter than their interval",
+		StatusCode: http.StatusBadRequest,
+	}
+	ErrRecurrenceTooLong = &ServiceError{
+		Message:    "recurring blackouts can repeat for at most a year",
+		StatusCode: http.StatusBadRequest,
+	}
+	ErrBlackoutFailed = &ServiceError{
+		Message:    "failed to manage room blackouts",
+		StatusCode: http.StatusInternalServerError,
+	}
+	ErrOutsideOpeningHours = &ServiceError{
+		Message:    "reservation must be within the opening hours of a single day",
+		StatusCode: http.StatusBadRequest,
+	}
+	ErrInvalidOpeningHours = &ServiceError{
+		Message:    "opening time must be before closing time, between 00:00 and 24:00",
+		StatusCode: http.StatusBadRequest,
+	}
+	ErrInvalidCalendarFile = &ServiceError{
+		Message:    "invalid iCalendar file",
+		StatusCode: http.StatusBadRequest,
+	}
+	ErrOpeningHoursNotFound = &ServiceError{
+		Message:    "no opening hours exception on this date",
+		StatusCode: http.StatusNotFound,
+	}
+	ErrOpeningHoursFailed = &ServiceError{
+		Message:    "failed to manage opening hours",
+		StatusCode: http.StatusInternalServerError,
+	}
+	ErrCampusNotFound = &ServiceError{
+		Message:    "campus not found",
+		StatusCode: http.StatusNotFound,
+	}
+	ErrInvalidCampus = &ServiceError{
+		Message:    "invalid campus settings",
+		StatusCode: http.StatusBadRequest,
+	}
+	ErrCampusTaken = &ServiceError{
+		Message:    "a campus with this slug or intra campus ID already exists",
+		StatusCode: http.StatusConflict,
+	}
+	ErrRoomNameTaken = &ServiceError{
+		Message:    "a room with this name already exists on the campus",
+		StatusCode: http.StatusConflict,
+	}
+	ErrCampusFailed = &ServiceError{
+		Message:    "failed to manage campuses",
+		StatusCode: http.StatusInternalServerError,
+	}
+	ErrLockoutNotFound = &ServiceError{
+		Message:    "no lockout for this IP or account",
+		StatusCode: http.StatusNotFound,
+	}
+	ErrLockoutFailed = &ServiceError{
+		Message:    "failed to manage login lockouts",
+		StatusCode: http.StatusInternalServerError,
+	}
+	ErrInvalidPeriod = &ServiceError{
+		Message:    "the end of the period must be after its start",
+		StatusCode: http.StatusBadRequest,
+	}
 )
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/logger"
+)
+
+// Kinds of login lockouts
+const (
+	LockoutIP      = "ip"
+	LockoutAccount = "account"
+)
+
+// LockoutPolicy decides when failed logins lock out an IP or account.
+// Threshold failures within Window lock it out for Base, every further
+// lockout in a row lasts twice as long, up to Max. A lockout streak is
+// forgotten once no failure happened for Max.
+type LockoutPolicy struct {
+	Threshold int
+	Window    time.Duration
+	Base      time.Duration
+	Max       time.Duration
+}
+
+// LoginGuardService tracks failed OAuth callbacks per client IP and per
+// account and temporarily locks out the ones failing too often.
+type LoginGuardService struct {
+	db    *database.DB
+	audit *audit.Service
+
+	mu     sync.RWMutex
+	policy LockoutPolicy
+}
+
+// NewLoginGuardService create dependencies for LoginGuardService.
+func NewLoginGuardService(db *database.DB, auditService *audit.Service, policy LockoutPolicy) *LoginGuardService {
+	return &LoginGuardService{
+		db:     db,
+		audit:  auditService,
+		policy: policy,
+	}
+}
+
+// SetPolicy replaces the lockout policy. Lockouts already in place keep
+// their end.
+func (s *LoginGuardService) SetPolicy(policy LockoutPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = policy
+}
+
+// Locked returns how long the IP or the account remains locked out, zero
+// when neither is. Either may be empty. Logins are let through when the
+// lockouts can't be read.
+func (s *LoginGuardService) Locked(ctx context.Context, ip, account string) time.Duration {
+	now := time.Now()
+
+	var remaining time.Duration
+	for _, key := range lockoutKeys(ip, account) {
+		lockout, err := s.db.GetLoginLockout(ctx, database.GetLoginLockoutParams{
+			Kind:    key.kind,
+			Subject: key.subject,
+		})
+		if err != nil {
+			if !errors.Is(err, sql.ErrNoRows) {
+				logger.FromContext(ctx).Error("failed to get login lockout", "error", err)
+			}
+			continue
+		}
+		if lockout.LockedUntil.Valid && lockout.LockedUntil.Time.After(now) {
+			remaining = max(remaining, lockout.LockedUntil.Time.Sub(now))
+		}
+	}
+	return remaining
+}
+
+// Failure counts a failed login for the IP and the account, if known,
+// locking them out once they reach the threshold.
+func (s *LoginGuardService) Failure(ctx context.Context, ip, account, reason string) {
+	log := logger.FromContext(ctx)
+
+	for _, key := range lockoutKeys(ip, account) {
+		lockout, err := s.recordFailure(ctx, key, reason)
+		if err != nil {
+			log.Error("failed to record login failure", "kind", key.kind, "error", err)
+			continue
+		}
+
+		log.Warn("security event",
+			"event", "login_failed",
+			"kind", key.kind,
+			"subject", key.subject,
+			"reason", reason,
+			"failures", lockout.Failures,
+		)
+		if lockout.Failures == 0 && lockout.LockedUntil.Valid {
+			log.Warn("security event",
+				"event", "login_locked",
+				"kind", key.kind,
+				"subject", key.subject,
+				"lockouts", lockout.Lockouts,
+				"locked_until", lockout.LockedUntil.Time.UTC(),
+			)
+		}
+	}
+}
+
+// Success forgets the failures of an account after it logged in. Failures
+// of the IP are kept, a valid login doesn't vouch for other attempts.
+func (s *LoginGuardService) Success(ctx context.Context, account string) {
+	_, err := s.db.DeleteLoginLockout(ctx, database.DeleteLoginLockoutParams{
+		Kind:    LockoutAccount,
+		Subject: account,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to clear login failures", "error", err)
+	}
+}
+
+// ListLockouts returns the IPs and accounts currently locked out.
+func (s *LoginGuardService) ListLockouts(ctx context.Context) ([]database.LoginLockout, error) {
+	lockouts, err := s.db.ListActiveLoginLockouts(ctx, sql.NullTime{Time: time.Now(), Valid: true})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list login lockouts", "error", err)
+		return nil, ErrLockoutFailed
+	}
+	return lockouts, nil
+}
+
+// Unblock lifts the lockout of an IP or account and forgets its failures.
+func (s *LoginGuardService) Unblock(ctx context.Context, kind, subject string, actorID int64) error {
+	lockout, err := s.db.GetLoginLockout(ctx, database.GetLoginLockoutParams{
+		Kind:    kind,
+		Subject: subject,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrLockoutNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get login lockout", "error", err)
+		return ErrLockoutFailed
+	}
+
+	n, err := s.db.DeleteLoginLockout(ctx, database.DeleteLoginLockoutParams{
+		Kind:    kind,
+		Subject: subject,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to delete login lockout", "error", err)
+		return ErrLockoutFailed
+	}
+	if n == 0 {
+		return ErrLockoutNotFound
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.LoginUnblock,
+		ActorID:    actorID,
+		TargetType: audit.TargetLoginLockout,
+		TargetID:   strconv.FormatInt(lockout.ID, 10),
+		Before:     lockoutSnapshot(lockout),
+	})
+
+	logger.FromContext(ctx).Info("security event",
+		"event", "login_unblocked",
+		"kind", kind,
+		"subject", subject,
+		"by", actorID,
+	)
+	return nil
+}
+
+// recordFailure counts one failure for key in a transaction, so
+// concurrent callbacks don't lose counts.
+func (s *LoginGuardService) recordFailure(ctx context.Context, key lockoutKey, reason string) (database.LoginLockout, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return database.LoginLockout{}, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := s.db.WithTx(tx.Tx)
+
+	err = qtx.CreateLoginLockout(ctx, database.CreateLoginLockoutParams{
+		Kind:    key.kind,
+		Subject: key.subject,
+	})
+	if err != nil {
+		return database.LoginLockout{}, err
+	}
+
+	current, err := qtx.GetLoginLockoutForUpdate(ctx, database.GetLoginLockoutForUpdateParams{
+		Kind:    key.kind,
+		Subject: key.subject,
+	})
+	if err != nil {
+		return database.LoginLockout{}, err
+	}
+
+	s.mu.RLock()
+	policy := s.policy
+	s.mu.RUnlock()
+
+	next := policy.next(current, time.Now())
+	lockout, err := qtx.UpdateLoginLockout(ctx, database.UpdateLoginLockoutParams{
+		Kind:          key.kind,
+		Subject:       key.subject,
+		Failures:      next.Failures,
+		Lockouts:      next.Lockouts,
+		LastReason:    reason,
+		LastFailureAt: next.LastFailureAt,
+		LockedUntil:   next.LockedUntil,
+	})
+	if err != nil {
+		return database.LoginLockout{}, err
+	}
+
+	return lockout, tx.Commit()
+}
+
+// next returns the state of a lockout after one more failure at now.
+// Reaching the threshold locks it out and starts counting failures anew.
+func (p LockoutPolicy) next(current database.LoginLockout, now time.Time) database.LoginLockout {
+	next := current
+	next.LastFailureAt = now
+
+	// A new row starts at zero failures with last_failure_at set to now
+	sinceLast := now.Sub(current.LastFailureAt)
+	if sinceLast > p.Max {
+		next.Lockouts = 0
+	}
+	if sinceLast > p.Window {
+		next.Failures = 0
+	}
+
+	next.Failures++
+	if int(next.Failures) < p.Threshold {
+		return next
+	}
+
+	duration := p.Base
+	for range next.Lockouts {
+		duration *= 2
+		if duration >= p.Max {
+			break
+		}
+	}
+	duration = min(duration, p.Max)
+
+	next.Failures = 0
+	next.Lockouts++
+	next.LockedUntil = sql.NullTime{Time: now.Add(duration), Valid: true}
+	return next
+}
+
+type lockoutKey struct {
+	kind    string
+	subject string
+}
+
+// lockoutKeys returns the keys of the IP and account that are set.
+func lockoutKeys(ip, account string) []lockoutKey {
+	var keys []lockoutKey
+	if ip != "" {
+		keys = append(keys, lockoutKey{kind: LockoutIP, subject: ip})
+	}
+	if account != "" {
+		keys = append(keys, lockoutKey{kind: LockoutAccount, subject: account})
+	}
+	return keys
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/database"
+)
+
+func TestLockoutPolicyNext(t *testing.T) {
+	policy := LockoutPolicy{
+		Threshold: 3,
+		Window:    15 * time.Minute,
+		Base:      time.Minute,
+		Max:       time.Hour,
+	}
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		current          database.LoginLockout
+		expectedFailures int32
+		expectedLockouts int32
+		expectedLock     time.Duration // zero when not locked by this failure
+	}{
+		{
+			name:             "first failure",
+			current:          database.LoginLockout{LastFailureAt: now},
+			expectedFailures: 1,
+		},
+		{
+			name:             "below threshold",
+			current:          database.LoginLockout{Failures: 1, LastFailureAt: now.Add(-time.Minute)},
+			expectedFailures: 2,
+		},
+		{
+			name:             "reaching threshold locks for base",
+			current:          database.LoginLockout{Failures: 2, LastFailureAt: now.Add(-time.Minute)},
+			expectedLockouts: 1,
+			expectedLock:     time.Minute,
+		},
+		{
+			name:             "second lockout in a row doubles",
+			current:          database.LoginLockout{Failures: 2, Lockouts: 1, LastFailureAt: now.Add(-time.Minute)},
+			expectedLockouts: 2,
+			expectedLock:     2 * time.Minute,
+		},
+		{
+			name:             "lockouts are capped at max",
+			current:          database.LoginLockout{Failures: 2, Lockouts: 40, LastFailureAt: now.Add(-time.Minute)},
+			expectedLockouts: 41,
+			expectedLock:     time.Hour,
+		},
+		{
+			name:             "failures outside window are forgotten",
+			current:          database.LoginLockout{Failures: 2, Lockouts: 1, LastFailureAt: now.Add(-20 * time.Minute)},
+			expectedFailures: 1,
+			expectedLockouts: 1,
+		},
+		{
+			name:             "lockout streak is forgotten after max",
+			current:          database.LoginLockout{Failures: 2, Lockouts: 5, LastFailureAt: now.Add(-2 * time.Hour)},
+			expectedFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := policy.next(tt.current, now)
+
+			if next.Failures != tt.expectedFailures {
+				t.Errorf("expected %d failures, got %d", tt.expectedFailures, next.Failures)
+			}
+			if next.Lockouts != tt.expectedLockouts {
+				t.Errorf("expected %d lockouts, got %d", tt.expectedLockouts, next.Lockouts)
+			}
+			if !next.LastFailureAt.Equal(now) {
+				t.Errorf("expected last failure at %v, got %v", now, next.LastFailureAt)
+			}
+
+			if tt.expectedLock == 0 {
+				if next.LockedUntil.Valid {
+					t.Errorf("expected no lockout, got one until %v", next.LockedUntil.Time)
+				}
+				return
+			}
+			if !next.LockedUntil.Valid || !next.LockedUntil.Time.Equal(now.Add(tt.expectedLock)) {
+				t.Errorf("expected lockout until %v, got %v", now.Add(tt.expectedLock), next.LockedUntil)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/campus"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/schedule"
+)
+
+// OpeningHoursService manages holidays and special opening hours of each
+// campus. Changes are written to the database and loaded into the campus
+// calendars read by request validation and reservations.
+type OpeningHoursService struct {
+	db       *database.DB
+	campuses *campus.Registry
+	audit    *audit.Service
+}
+
+// SetOpeningHoursInput contains the input parameters for an exception on one date.
+type SetOpeningHoursInput struct {
+	CampusID  int64
+	Date      time.Time // local date, time of day is ignored
+	Closed    bool
+	Open      int // minutes after midnight
+	Close     int
+	Name      string
+	CreatedBy int64
+}
+
+// NewOpeningHoursService create dependencies for OpeningHoursService.
+func NewOpeningHoursService(
+	db *database.DB,
+	campuses *campus.Registry,
+	auditService *audit.Service,
+) *OpeningHoursService {
+	return &OpeningHoursService{
+		db:       db,
+		campuses: campuses,
+		audit:    auditService,
+	}
+}
+
+// Days returns the effective opening hours of the campus on every date in the range.
+func (s *OpeningHoursService) Days(c *campus.Campus, from, to time.Time) []schedule.Day {
+	return c.Hours.Days(from, to)
+}
+
+// ListExceptions returns all exceptions to the regular hours of the campus.
+func (s *OpeningHoursService) ListExceptions(ctx context.Context, campusID int64) ([]database.OpeningHoursException, error) {
+	rows, err := s.db.ListOpeningHoursExceptionsByCampus(ctx, campusID)
+	if err != nil {
+		slog.Error("failed to list opening hours", "error", err)
+		return nil, ErrOpeningHoursFailed
+	}
+	return rows, nil
+}
+
+// SetException closes the campus or sets special hours on a date.
+func (s *OpeningHoursService) SetException(
+	ctx context.Context,
+	input SetOpeningHoursInput,
+) (database.OpeningHoursException, error) {
+
+	hours := schedule.Hours{Open: input.Open, Close: input.Close, Closed: input.Closed}
+	if hours.Closed {
+		hours.Open, hours.Close = 0, 0
+	}
+	if err := hours.Validate(); err != nil {
+		return database.OpeningHoursException{}, ErrInvalidOpeningHours
+	}
+
+	date := dateOnly(input.Date)
+
+	var before any
+	previous, err := s.db.GetOpeningHoursException(ctx, database.GetOpeningHoursExceptionParams{
+		CampusID: input.CampusID,
+		Date:     date,
+	})
+	switch {
+	case err == nil:
+		before = openingHoursSnapshot(previous)
+	case !errors.Is(err, sql.ErrNoRows):
+		slog.Error("failed to get opening hours", "error", err)
+		return database.OpeningHoursException{}, ErrOpeningHoursFailed
+	}
+
+	row, err := s.db.UpsertOpeningHoursException(ctx, database.UpsertOpeningHoursExceptionParams{
+		CampusID:    input.CampusID,
+		Date:        date,
+		Closed:      hours.Closed,
+		OpenMinute:  int32(hours.Open),
+		CloseMinute: int32(hours.Close),
+		Name:        input.Name,
+		CreatedBy:   sql.NullInt64{Int64: input.CreatedBy, Valid: input.CreatedBy != 0},
+	})
+	if err != nil {
+		slog.Error("failed to save opening hours", "error", err)
+		return database.OpeningHoursException{}, ErrOpeningHoursFailed
+	}
+
+	s.reload(ctx)
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.OpeningHoursSet,
+		ActorID:    input.CreatedBy,
+		CampusID:   input.CampusID,
+		TargetType: audit.TargetOpeningHours,
+		TargetID:   exceptionTarget(input.CampusID, date),
+		Before:     before,
+		After:      openingHoursSnapshot(row),
+	})
+
+	slog.Info("opening hours set",
+		"campus_id", input.CampusID,
+		"date", date.Format(schedule.DateLayout),
+		"closed", row.Closed,
+		"by", input.CreatedBy,
+	)
+	return row, nil
+}
+
+// DeleteException restores the regular hours of the campus on a date.
+func (s *OpeningHoursService) DeleteException(ctx context.Context, campusID int64, date time.Time, deletedBy int64) error {
+	date = dateOnly(date)
+	key := database.GetOpeningHoursExceptionParams{CampusID: campusID, Date: date}
+
+	previous, err := s.db.GetOpeningHoursException(ctx, key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrOpeningHoursNotFound
+		}
+		slog.Error("failed to get opening hours", "error", err)
+		return ErrOpeningHoursFailed
+	}
+
+	n, err := s.db.DeleteOpeningHoursException(ctx, database.DeleteOpeningHoursExceptionParams(key))
+	if err != nil {
+		slog.Error("failed to delete opening hours", "error", err)
+		return ErrOpeningHoursFailed
+	}
+	if n == 0 {
+		return ErrOpeningHoursNotFound
+	}
+
+	s.reload(ctx)
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.OpeningHoursDelete,
+		ActorID:    deletedBy,
+		CampusID:   campusID,
+		TargetType: audit.TargetOpeningHours,
+		TargetID:   exceptionTarget(campusID, date),
+		Before:     openingHoursSnapshot(previous),
+	})
+	return nil
+}
+
+// ImportICS adds the events of an iCalendar file as exceptions of the
+// campus, replacing existing exceptions on the same dates. Times without a
+// timezone are read in the campus timezone. All or none are imported.
+func (s *OpeningHoursService) ImportICS(ctx context.Context, campusID int64, r io.Reader, importedBy int64) (int, error) {
+	c, ok := s.campuses.Get(campusID)
+	if !ok {
+		return 0, ErrCampusNotFound
+	}
+
+	exceptions, err := schedule.ParseICS(r, c.Location)
+	if err != nil {
+		return 0, &ServiceError{
+			Message:    fmt.Sprintf("%s: %v", ErrInvalidCalendarFile.Message, err),
+			StatusCode: ErrInvalidCalendarFile.StatusCode,
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		slog.Error("failed to start transaction", "error", err)
+		return 0, ErrOpeningHoursFailed
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := s.db.WithTx(tx.Tx)
+
+	for _, e := range exceptions {
+		date, err := time.Parse(schedule.DateLayout, e.Date)
+		if err != nil {
+			return 0, ErrInvalidCalendarFile
+		}
+
+		_, err = qtx.UpsertOpeningHoursException(ctx, database.UpsertOpeningHoursExceptionParams{
+			CampusID:    campusID,
+			Date:        date,
+			Closed:      e.Hours.Closed,
+			OpenMinute:  int32(e.Hours.Open),
+			CloseMinute: int32(e.Hours.Close),
+			Name:        truncate(e.Name, 255),
+			SourceUid:   truncate(e.UID, 255),
+			CreatedBy:   sql.NullInt64{Int64: importedBy, Valid: importedBy != 0},
+		})
+		if err != nil {
+			slog.Error("failed to import opening hours", "date", e.Date, "error", err)
+			return 0, ErrOpeningHoursFailed
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		slog.Error("failed to commit transaction", "error", err)
+		return 0, ErrOpeningHoursFailed
+	}
+
+	s.reload(ctx)
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.OpeningHoursImport,
+		ActorID:    importedBy,
+		CampusID:   campusID,
+		TargetType: audit.TargetOpeningHours,
+		TargetID:   strconv.FormatInt(campusID, 10),
+		After:      map[string]any{"imported": len(exceptions)},
+	})
+
+	slog.Info("opening hours imported", "campus_id", campusID, "count", len(exceptions), "by", importedBy)
+	return len(exceptions), nil
+}
+
+// reload refreshes the campus calendars after a change. The change is
+// already stored, so a failure only delays it until the next successful load.
+func (s *OpeningHoursService) reload(ctx context.Context) {
+	if err := s.campuses.Load(ctx); err != nil {
+		slog.Error("failed to reload opening hours", "error", err)
+	}
+}
+
+// exceptionTarget identifies an exception in the audit log as campus/date.
+func exceptionTarget(campusID int64, date time.Time) string {
+	return strconv.FormatInt(campusID, 10) + "/" + date.Format(schedule.DateLayout)
+}
+
+// dateOnly returns the date of t as midnight UTC, the form DATE columns are
+// written and read in.
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
@@ -6,44 +6,46 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log/slog"
+
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/campus"
 	"github.com/IbnBaqqi/book-me/internal/database"
 	"github.com/IbnBaqqi/book-me/internal/dto"
 	"github.com/IbnBaqqi/book-me/internal/email"
 	"github.com/IbnBaqqi/book-me/internal/google"
+	"github.com/IbnBaqqi/book-me/internal/integration"
+	"github.com/IbnBaqqi/book-me/internal/logger"
+	"github.com/IbnBaqqi/book-me/internal/metrics"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+	"github.com/IbnBaqqi/book-me/internal/tracing"
 )
 
-// User roles
+// User base roles
 const (
-	RoleStudent = "STUDENT"
-	RoleStaff   = "STAFF"
+	RoleStudent = string(rbac.Student)
+	RoleStaff   = string(rbac.Staff)
 )
 
-var helsinki *time.Location
-
-func init() {
-	var err error
-	helsinki, err = time.LoadLocation("Europe/Helsinki")
-	if err != nil {
-		panic("failed to load Europe/Helsinki timezone: " + err.Error())
-	}
-}
-
 // ReservationService handles reservation business logic.
 type ReservationService struct {
 	db       *database.DB
-	email    *email.Service
-	calendar *google.CalendarService
+	email    *integration.Optional[email.Sender]
+	calendar *integration.Optional[google.Calendar]
+	audit    *audit.Service
+	campuses *campus.Registry
 }
 
 // CreateReservationInput contains the input parameters for creating a reservation.
 type CreateReservationInput struct {
+	CampusID  int64 // campus of the user, the room must belong to it
 	UserID    int64
 	UserName  string
-	UserRole  string
+	Grants    rbac.Grants
 	RoomID    int64
 	StartTime time.Time
 	EndTime   time.Time
@@ -51,29 +53,35 @@ type CreateReservationInput struct {
 
 // GetReservationsInput contains the input parameters for fetching reservations.
 type GetReservationsInput struct {
+	CampusID  int64
 	StartDate time.Time
 	EndDate   time.Time
 	UserID    int64
-	UserRole  string
+	Grants    rbac.Grants
 }
 
 // CancelReservationInput contains the input parameters for cancelling a reservation.
 type CancelReservationInput struct {
 	ID       int64
+	CampusID int64
 	UserID   int64
-	UserRole string
+	Grants   rbac.Grants
 }
 
 // NewReservationService create dependencies for ReservationService.
 func NewReservationService(
 	db *database.DB,
-	emailService *email.Service,
-	calendarService *google.CalendarService,
+	emailService *integration.Optional[email.Sender],
+	calendarService *integration.Optional[google.Calendar],
+	auditService *audit.Service,
+	campuses *campus.Registry,
 ) *ReservationService {
 	return &ReservationService{
 		db:       db,
 		email:    emailService,
 		calendar: calendarService,
+		audit:    auditService,
+		campuses: campuses,
 	}
 }
 
@@ -88,7 +96,7 @@ func (s *ReservationService) CreateReservation(
 	// TODO use redis instead
 	dbUser, err := s.db.GetUser(ctx, input.UserID)
 	if err != nil {
-		slog.Error("failed to get user from db", "error", err)
+		logger.FromContext(ctx).Error("failed to get user from db", "error", err)
 		return nil, ErrGetUserFailed
 	}
 
@@ -101,14 +109,26 @@ func (s *ReservationService) CreateReservation(
 		return nil, err
 	}
 
-	// Validate duration (students only)
+	// Rooms of other campuses cannot be booked
+	if room.CampusID != input.CampusID {
+		return nil, ErrRoomNotFound
+	}
+	home := s.campuses.Resolve(room.CampusID)
+
+	// Validate duration (unless allowed unlimited bookings in this room)
 	duration := input.EndTime.Sub(input.StartTime)
 	maxDuration := 4 * time.Hour
 
-	if duration > maxDuration && input.UserRole == RoleStudent {
+	if duration > maxDuration && !input.Grants.Can(rbac.ReservationsUnlimited, room.ID) {
 		return nil, ErrExceedsMaxDuration
 	}
 
+	// The whole booking must fall within one day's opening hours,
+	// which may be shortened, extended or closed by the holiday calendar
+	if !home.Hours.Allows(input.StartTime, input.EndTime) {
+		return nil, ErrOutsideOpeningHours
+	}
+
 	// Start transaction
 	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.LevelReadCommitted,
@@ -125,6 +145,13 @@ func (s *ReservationService) CreateReservation(
 
 	qtx := s.db.WithTx(tx.Tx)
 
+	// Bookings and blackouts of the room are made one at a time, so two
+	// can't both pass the checks below
+	if _, err := qtx.LockRoom(ctx, room.ID); err != nil {
+		logger.FromContext(ctx).Error("database error", "error", err)
+		return nil, err
+	}
+
 	// Check for overlapping reservations
 	// Note: StartTime and EndTime are intentionally swapped for the overlap check logic
 	// This checks if the new reservation's time range conflicts with existing ones
@@ -134,14 +161,33 @@ func (s *ReservationService) CreateReservation(
 		EndTime:   input.StartTime,
 	})
 	if err != nil {
-		slog.Error("database error", "error", err)
+		logger.FromContext(ctx).Error("database error", "error", err)
 		return nil, err
 	}
 
 	if overlap {
+		metrics.ReservationConflicts.WithLabelValues(metrics.ConflictOverlap).Inc()
 		return nil, ErrTimeSlotTaken
 	}
 
+	// Check the room is not blacked out
+	blackouts, err := qtx.ListRoomBlackoutsBetween(ctx, database.ListRoomBlackoutsBetweenParams{
+		RoomID:   sql.NullInt64{Int64: room.ID, Valid: true},
+		FromTime: input.StartTime,
+		ToTime:   input.EndTime,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("database error", "error", err)
+		return nil, err
+	}
+
+	for _, b := range blackouts {
+		if len(expandBlackout(blackoutFromRow(b), home.Location, input.StartTime, input.EndTime)) > 0 {
+			metrics.ReservationConflicts.WithLabelValues(metrics.ConflictBlackout).Inc()
+			return nil, ErrRoomUnavailable
+		}
+	}
+
 	// Create reservation
 	reservation, err := qtx.CreateReservation(ctx, database.CreateReservationParams{
 		UserID:    input.UserID,
@@ -149,6 +195,7 @@ func (s *ReservationService) CreateReservation(
 		StartTime: input.StartTime,
 		EndTime:   input.EndTime,
 		Status:    "RESERVED",
+		CampusID:  room.CampusID,
 	})
 	if err != nil {
 		return nil, err
@@ -162,49 +209,56 @@ func (s *ReservationService) CreateReservation(
 		}
 	}
 
+	metrics.ReservationsCreated.Inc()
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.ReservationCreate,
+		ActorID:    input.UserID,
+		UserID:     input.UserID,
+		RoomID:     room.ID,
+		TargetType: audit.TargetReservation,
+		TargetID:   strconv.FormatInt(reservation.ID, 10),
+		After:      reservationSnapshot(reservation),
+	})
+
 	// Create Google Calendar event
 	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 40*time.Second)
+		ctx, cancel := context.WithTimeout(tracing.Detach(ctx), 40*time.Second)
 		defer cancel()
 
 		calendarReservation := &google.Reservation{
-			StartTime: reservation.StartTime,
-			EndTime:   reservation.EndTime,
-			CreatedBy: input.UserName,
-			Room:      room.Name,
+			StartTime:  reservation.StartTime,
+			EndTime:    reservation.EndTime,
+			CreatedBy:  input.UserName,
+			Room:       room.Name,
+			CalendarID: home.CalendarID,
+			TimeZone:   home.Location.String(),
 		}
 
-		eventID, err := s.calendar.CreateGoogleEvent(ctx, calendarReservation)
+		err := s.calendar.Do(ctx, "create event", func(ctx context.Context, calendar google.Calendar) error {
+			return s.createEvent(ctx, calendar, reservation.ID, calendarReservation)
+		})
 		if err != nil {
-			slog.Error("Failed to create Google Calendar event", "error", err)
-			return
-		}
-
-		// Update reservation with event ID
-		if eventID != "" {
-			updateErr := s.db.UpdateGoogleCalID(ctx, database.UpdateGoogleCalIDParams{
-				ID:          reservation.ID,
-				GcalEventID: sql.NullString{String: eventID, Valid: eventID != ""},
-			})
-			if updateErr != nil {
-				slog.Warn("Failed to update reservation with calendar event ID", "error", updateErr)
-			}
+			logger.FromContext(ctx).Error("Failed to create Google Calendar event", "error", err)
 		}
 	}()
 
 	// Send confirmation email
 	go func() {
-		emailCtx, cancel := context.WithTimeout(context.Background(), 40*time.Second)
+		emailCtx, cancel := context.WithTimeout(tracing.Detach(ctx), 40*time.Second)
 		defer cancel()
 
-		if err := s.email.SendConfirmation(
-			emailCtx,
-			dbUser.Email,
-			room.Name,
-			reservation.StartTime.In(helsinki).Format("Monday, January 2, 2006 at 3:04 PM"),
-			reservation.EndTime.In(helsinki).Format("Monday, January 2, 2006 at 3:04 PM"),
-		); err != nil {
-			slog.Error("failed to send confirmation email", "error", err)
+		err := s.email.Do(emailCtx, "send confirmation", func(ctx context.Context, sender email.Sender) error {
+			return sender.SendConfirmation(
+				ctx,
+				dbUser.Email,
+				room.Name,
+				reservation.StartTime.In(home.Location).Format("Monday, January 2, 2006 at 3:04 PM"),
+				reservation.EndTime.In(home.Location).Format("Monday, January 2, 2006 at 3:04 PM"),
+			)
+		})
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to send confirmation email", "error", err)
 		}
 	}()
 
@@ -222,56 +276,91 @@ func (s *ReservationService) GetReservations(
 	startDateTime := input.StartDate
 	endDateTime := input.EndDate.AddDate(0, 0, 1)
 
-	// isStaff := input.UserRole == RoleStaff
+	// isStaff := input.Grants.Can(rbac.ReservationsModerate, 0)
+
+	home := s.campuses.Resolve(input.CampusID)
 
-	// Fetch all reservations between dates
+	// Fetch all reservations of the campus between dates
 	reservations, err := s.db.GetAllBetweenDates(ctx, database.GetAllBetweenDatesParams{
 		StartTime: startDateTime,
 		EndTime:   endDateTime,
+		CampusID:  home.ID,
 	})
 	if err != nil {
-		slog.Error("failed to fetch reservations from db", "error", err)
+		logger.FromContext(ctx).Error("failed to fetch reservations from db", "error", err)
 		return nil, ErrReservationFetchFailed
 	}
-	// Group reservations by room ID
-	grouped := make(map[int64][]database.GetAllBetweenDatesRow)
-	for _, res := range reservations {
-		grouped[res.RoomID] = append(grouped[res.RoomID], res)
-	}
 
-	// Build result
-	result := make([]dto.ReservedDto, 0, len(grouped))
+	// Fetch blackouts overlapping the range
+	blackouts, err := s.db.ListRoomBlackoutsBetween(ctx, database.ListRoomBlackoutsBetweenParams{
+		CampusID: sql.NullInt64{Int64: home.ID, Valid: true},
+		FromTime: startDateTime,
+		ToTime:   endDateTime,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to fetch room blackouts from db", "error", err)
+		return nil, ErrReservationFetchFailed
+	}
 
-	for roomID, roomReservations := range grouped {
-		if len(roomReservations) == 0 {
-			continue
+	// Group reservations and blackouts by room ID, keeping rooms in order
+	rooms := make(map[int64]*dto.ReservedDto)
+	var roomIDs []int64
+	roomFor := func(roomID int64, roomName string) *dto.ReservedDto {
+		room, ok := rooms[roomID]
+		if !ok {
+			room = &dto.ReservedDto{
+				RoomID:   roomID,
+				RoomName: roomName,
+				Slots:    []dto.ReservedSlotDto{},
+			}
+			rooms[roomID] = room
+			roomIDs = append(roomIDs, roomID)
 		}
+		return room
+	}
 
-		roomName := roomReservations[0].RoomName
-
-		// Map reservations to slots
-		slots := make([]dto.ReservedSlotDto, 0, len(roomReservations))
-		for _, res := range roomReservations {
-			// var bookedBy *string
+	for _, res := range reservations {
+		// var bookedBy *string
+
+		// Show bookedBy only if user is staff or is the owner
+		// if isStaff || res.CreatedByID == input.UserID {
+		// 	bookedBy = &res.CreatedByName
+		// }
+
+		room := roomFor(res.RoomID, res.RoomName)
+		room.Slots = append(room.Slots, dto.ReservedSlotDto{
+			ID:        res.ID,
+			StartTime: res.StartTime.UTC(),
+			EndTime:   res.EndTime.UTC(),
+			BookedBy:  &res.CreatedByName,
+		})
+	}
 
-			// Show bookedBy only if user is staff or is the owner
-			// if isStaff || res.CreatedByID == input.UserID {
-			// 	bookedBy = &res.CreatedByName
-			// }
+	for _, b := range blackouts {
+		periods := expandBlackout(blackoutFromRow(b), home.Location, startDateTime, endDateTime)
+		if len(periods) == 0 {
+			continue
+		}
 
-			slots = append(slots, dto.ReservedSlotDto{
-				ID:        res.ID,
-				StartTime: res.StartTime.UTC(),
-				EndTime:   res.EndTime.UTC(),
-				BookedBy:  &res.CreatedByName,
+		room := roomFor(b.RoomID, b.RoomName)
+		for _, p := range periods {
+			room.Blackouts = append(room.Blackouts, dto.BlackoutSlotDto{
+				ID:        b.ID,
+				StartTime: p.StartTime,
+				EndTime:   p.EndTime,
+				Reason:    b.Reason,
 			})
 		}
+	}
 
-		result = append(result, dto.ReservedDto{
-			RoomID:   roomID,
-			RoomName: roomName,
-			Slots:    slots,
+	// Build result
+	result := make([]dto.ReservedDto, 0, len(roomIDs))
+	for _, roomID := range roomIDs {
+		room := rooms[roomID]
+		slices.SortFunc(room.Blackouts, func(a, b dto.BlackoutSlotDto) int {
+			return a.StartTime.Compare(b.StartTime)
 		})
+		result = append(result, *room)
 	}
 
 	return result, nil
@@ -293,10 +382,15 @@ func (s *ReservationService) CancelReservation(
 		return err
 	}
 
-	isStaff := input.UserRole == RoleStaff
+	canModerate := input.Grants.Can(rbac.ReservationsModerate, reservation.RoomID)
 	isOwner := reservation.UserID == input.UserID
 
-	if !isStaff && !isOwner {
+	// Moderators only see their own campus, unless they manage all campuses
+	if !isOwner && reservation.CampusID != input.CampusID && !input.Grants.Has(rbac.CampusesManage) {
+		return ErrReservationNotFound
+	}
+
+	if !canModerate && !isOwner {
 		return ErrUnauthorizedCancellation
 	}
 
@@ -306,14 +400,159 @@ func (s *ReservationService) CancelReservation(
 		return err
 	}
 
-	// Delete google calender event
+	if isOwner {
+		metrics.ReservationsCancelled.WithLabelValues(metrics.CancelOwner).Inc()
+	} else {
+		metrics.ReservationsCancelled.WithLabelValues(metrics.CancelModerator).Inc()
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.ReservationCancel,
+		ActorID:    input.UserID,
+		UserID:     reservation.UserID,
+		RoomID:     reservation.RoomID,
+		TargetType: audit.TargetReservation,
+		TargetID:   strconv.FormatInt(reservation.ID, 10),
+		Before:     reservationSnapshot(reservation),
+	})
+
+	// Delete google calender event. A reservation without one may still
+	// have its create job queued, which skips it once the reservation is gone.
+	if !reservation.GcalEventID.Valid {
+		return nil
+	}
 	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 40*time.Second)
+		ctx, cancel := context.WithTimeout(tracing.Detach(ctx), 40*time.Second)
 		defer cancel()
-		if err := s.calendar.DeleteGoogleEvent(ctx, reservation.GcalEventID.String); err != nil {
-			slog.Error("failed to delete google calendar event", "error", err)
+		home := s.campuses.Resolve(reservation.CampusID)
+		err := s.calendar.Do(ctx, "delete event", func(ctx context.Context, calendar google.Calendar) error {
+			return calendar.DeleteGoogleEvent(ctx, home.CalendarID, reservation.GcalEventID.String)
+		})
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to delete google calendar event", "error", err)
 		}
 	}()
 
 	return nil
 }
+
+// createEvent creates the calendar event of a reservation and stores its ID.
+// Queued jobs may run long after the reservation was made, so it is read
+// again first, and an event created for a reservation cancelled meanwhile,
+// or given an event by another job, is deleted again.
+func (s *ReservationService) createEvent(ctx context.Context, calendar google.Calendar, reservationID int64, event *google.Reservation) error {
+	reservation, err := s.db.GetReservationByID(ctx, reservationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		logger.FromContext(ctx).Info("reservation gone, skipping calendar event", "reservation_id", reservationID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if reservation.GcalEventID.Valid {
+		return nil
+	}
+
+	eventID, err := calendar.CreateGoogleEvent(ctx, event)
+	if err != nil {
+		return err
+	}
+	if eventID == "" {
+		return nil
+	}
+
+	// Update reservation with event ID
+	updated, err := s.db.UpdateGoogleCalID(ctx, database.UpdateGoogleCalIDParams{
+		ID:          reservationID,
+		GcalEventID: sql.NullString{String: eventID, Valid: true},
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("Failed to update reservation with calendar event ID", "error", err)
+		return nil
+	}
+	if updated == 0 {
+		logger.FromContext(ctx).Info("reservation gone or has an event, deleting new calendar event", "reservation_id", reservationID)
+		return calendar.DeleteGoogleEvent(ctx, event.CalendarID, eventID)
+	}
+	return nil
+}
+
+// ListReservationsInput selects the reservations overlapping a period.
+type ListReservationsInput struct {
+	CampusID int64 // 0 for all campuses
+	From     time.Time
+	To       time.Time
+}
+
+// ListReservations returns the reservations overlapping the period with
+// their room and owner, ordered by start time.
+func (s *ReservationService) ListReservations(
+	ctx context.Context,
+	input ListReservationsInput,
+) ([]database.ListReservationsBetweenRow, error) {
+	if !input.To.After(input.From) {
+		return nil, ErrInvalidPeriod
+	}
+	if input.CampusID != 0 {
+		if _, ok := s.campuses.Get(input.CampusID); !ok {
+			return nil, ErrCampusNotFound
+		}
+	}
+
+	reservations, err := s.db.ListReservationsBetween(ctx, database.ListReservationsBetweenParams{
+		FromTime: input.From,
+		ToTime:   input.To,
+		CampusID: sql.NullInt64{Int64: input.CampusID, Valid: input.CampusID != 0},
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list reservations", "error", err)
+		return nil, ErrReservationFetchFailed
+	}
+	return reservations, nil
+}
+
+// CalendarResync counts the calendar events created by ResyncCalendar.
+type CalendarResync struct {
+	Created int
+	Failed  int
+}
+
+// ResyncCalendar creates the missing calendar events of the reservations
+// overlapping the period, like those booked while the calendar was off.
+func (s *ReservationService) ResyncCalendar(ctx context.Context, input ListReservationsInput) (CalendarResync, error) {
+	if err := s.calendar.Err(); err != nil {
+		return CalendarResync{}, err
+	}
+
+	reservations, err := s.ListReservations(ctx, input)
+	if err != nil {
+		return CalendarResync{}, err
+	}
+
+	var result CalendarResync
+	calendar := s.calendar.Get()
+	for _, r := range reservations {
+		if r.GcalEventID.Valid {
+			continue
+		}
+
+		home := s.campuses.Resolve(r.CampusID)
+		err := s.createEvent(ctx, calendar, r.ID, &google.Reservation{
+			StartTime:  r.StartTime,
+			EndTime:    r.EndTime,
+			CreatedBy:  r.UserName,
+			Room:       r.RoomName,
+			CalendarID: home.CalendarID,
+			TimeZone:   home.Location.String(),
+		})
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to create google calendar event", "reservation_id", r.ID, "error", err)
+			result.Failed++
+			continue
+		}
+		result.Created++
+	}
+
+	logger.FromContext(ctx).Info("calendar resynced", "created", result.Created, "failed", result.Failed)
+	return result, nil
+}
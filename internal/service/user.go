@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IbnBaqqi/book-me/internal/audit"
+	"github.com/IbnBaqqi/book-me/internal/auth"
+	"github.com/IbnBaqqi/book-me/internal/campus"
+	"github.com/IbnBaqqi/book-me/internal/database"
+	"github.com/IbnBaqqi/book-me/internal/google"
+	"github.com/IbnBaqqi/book-me/internal/integration"
+	"github.com/IbnBaqqi/book-me/internal/logger"
+	"github.com/IbnBaqqi/book-me/internal/metrics"
+	"github.com/IbnBaqqi/book-me/internal/rbac"
+	"github.com/IbnBaqqi/book-me/internal/tracing"
+)
+
+// User list status filters
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+)
+
+// UserService handles staff management of users.
+// Token invalidation goes through the auth service, which also rejects
+// suspended users on every request.
+type UserService struct {
+	db       *database.DB
+	auth     *auth.Service
+	calendar *integration.Optional[google.Calendar]
+	audit    *audit.Service
+	campuses *campus.Registry
+}
+
+// ListUsersInput contains the input parameters for searching users.
+type ListUsersInput struct {
+	Search   string // matches name, email or login
+	Role     string
+	Status   string // UserStatusActive, UserStatusSuspended or empty for both
+	CampusID int64  // 0 lists users of all campuses
+	Limit    int
+	Offset   int
+}
+
+// UserActionInput identifies the target user of a staff action and who performs it.
+type UserActionInput struct {
+	UserID   int64
+	ActorID  int64
+	CampusID int64       // campus the actor may act in, 0 for all campuses
+	Grants   rbac.Grants // grants of the user performing the action
+}
+
+// NewUserService create dependencies for UserService.
+func NewUserService(
+	db *database.DB,
+	authService *auth.Service,
+	calendarService *integration.Optional[google.Calendar],
+	auditService *audit.Service,
+	campuses *campus.Registry,
+) *UserService {
+	return &UserService{
+		db:       db,
+		auth:     authService,
+		calendar: calendarService,
+		audit:    auditService,
+		campuses: campuses,
+	}
+}
+
+// ListUsers returns a page of users matching the input and the total number of matches.
+// Anonymized users are not listed.
+func (s *UserService) ListUsers(ctx context.Context, input ListUsersInput) ([]database.User, int64, error) {
+	search := sql.NullString{
+		String: escapeLike(strings.TrimSpace(input.Search)),
+		Valid:  strings.TrimSpace(input.Search) != "",
+	}
+	role := sql.NullString{String: input.Role, Valid: input.Role != ""}
+	suspended := sql.NullBool{
+		Bool:  input.Status == UserStatusSuspended,
+		Valid: input.Status != "",
+	}
+	campusID := sql.NullInt64{Int64: input.CampusID, Valid: input.CampusID != 0}
+
+	users, err := s.db.ListUsers(ctx, database.ListUsersParams{
+		Search:    search,
+		Role:      role,
+		Suspended: suspended,
+		CampusID:  campusID,
+		SkipRows:  int32(input.Offset), //nolint:gosec // bounded by the handler
+		MaxRows:   int32(input.Limit),  //nolint:gosec // bounded by the handler
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list users", "error", err)
+		return nil, 0, ErrUserFetchFailed
+	}
+
+	total, err := s.db.CountUsers(ctx, database.CountUsersParams{
+		Search:    search,
+		Role:      role,
+		Suspended: suspended,
+		CampusID:  campusID,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to count users", "error", err)
+		return nil, 0, ErrUserFetchFailed
+	}
+
+	return users, total, nil
+}
+
+// GetUser returns a single user. Users outside campusID are not found,
+// a campusID of 0 finds users of all campuses.
+func (s *UserService) GetUser(ctx context.Context, userID, campusID int64) (database.User, error) {
+	user, err := s.db.GetUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return database.User{}, ErrUserNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get user", "user_id", userID, "error", err)
+		return database.User{}, ErrUserFetchFailed
+	}
+	if campusID != 0 && user.CampusID != campusID {
+		return database.User{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// ListUserReservations returns the user's bookings overlapping the time range,
+// newest first. Zero times leave the range open.
+func (s *UserService) ListUserReservations(
+	ctx context.Context,
+	userID, campusID int64,
+	from, to time.Time,
+	limit int,
+) ([]database.ListReservationsByUserRow, error) {
+	if _, err := s.GetUser(ctx, userID, campusID); err != nil {
+		return nil, err
+	}
+
+	reservations, err := s.db.ListReservationsByUser(ctx, database.ListReservationsByUserParams{
+		UserID:   userID,
+		FromTime: sql.NullTime{Time: from, Valid: !from.IsZero()},
+		ToTime:   sql.NullTime{Time: to, Valid: !to.IsZero()},
+		MaxRows:  int32(limit), //nolint:gosec // bounded by the handler
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list user reservations", "user_id", userID, "error", err)
+		return nil, ErrReservationFetchFailed
+	}
+	return reservations, nil
+}
+
+// SuspendUser blocks the user from logging in and revokes their existing
+// tokens, which blocks booking as well.
+func (s *UserService) SuspendUser(ctx context.Context, input UserActionInput, reason string) (database.User, error) {
+	target, err := s.manageable(ctx, input)
+	if err != nil {
+		return database.User{}, err
+	}
+	if target.SuspendedAt.Valid {
+		return database.User{}, ErrUserAlreadySuspended
+	}
+
+	user, err := s.db.SuspendUser(ctx, database.SuspendUserParams{
+		ID:              target.ID,
+		SuspendedReason: reason,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to suspend user", "user_id", target.ID, "error", err)
+		return database.User{}, ErrUserUpdateFailed
+	}
+
+	if err := s.auth.RevokeTokens(ctx, user.ID); err != nil {
+		logger.FromContext(ctx).Error("failed to revoke tokens of suspended user", "user_id", user.ID, "error", err)
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.UserSuspend,
+		ActorID:    input.ActorID,
+		UserID:     user.ID,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatInt(user.ID, 10),
+		Before:     userSnapshot(target),
+		After:      userSnapshot(user),
+	})
+
+	logger.FromContext(ctx).Info("user suspended", "user_id", user.ID, "by", input.ActorID)
+	return user, nil
+}
+
+// UnsuspendUser lets a suspended user log in again. Tokens revoked on
+// suspension stay revoked.
+func (s *UserService) UnsuspendUser(ctx context.Context, input UserActionInput) (database.User, error) {
+	target, err := s.manageable(ctx, input)
+	if err != nil {
+		return database.User{}, err
+	}
+	if !target.SuspendedAt.Valid || target.AnonymizedAt.Valid {
+		return database.User{}, ErrUserNotSuspended
+	}
+
+	user, err := s.db.UnsuspendUser(ctx, target.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to unsuspend user", "user_id", target.ID, "error", err)
+		return database.User{}, ErrUserUpdateFailed
+	}
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.UserUnsuspend,
+		ActorID:    input.ActorID,
+		UserID:     user.ID,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatInt(user.ID, 10),
+		Before:     userSnapshot(target),
+		After:      userSnapshot(user),
+	})
+
+	logger.FromContext(ctx).Info("user unsuspended", "user_id", user.ID, "by", input.ActorID)
+	return user, nil
+}
+
+// AnonymizeUser removes the user's personal data, linked accounts, tokens,
+// roles and future bookings, and keeps the account suspended. Past bookings
+// are kept for statistics. Logging in again creates a fresh account.
+func (s *UserService) AnonymizeUser(ctx context.Context, input UserActionInput) error {
+	target, err := s.manageable(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to start transaction", "error", err)
+		return ErrUserUpdateFailed
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	qtx := s.db.WithTx(tx.Tx)
+
+	future, err := qtx.ListFutureReservationsByUser(ctx, target.ID)
+	if err == nil {
+		err = qtx.DeleteFutureReservationsByUser(ctx, target.ID)
+	}
+	if err == nil {
+		err = qtx.DeleteUserIdentities(ctx, target.ID)
+	}
+	if err == nil {
+		err = qtx.DeleteAPITokensByUser(ctx, target.ID)
+	}
+	if err == nil {
+		err = qtx.DeleteRoleAssignmentsByUser(ctx, target.ID)
+	}
+	if err == nil {
+		_, err = qtx.AnonymizeUser(ctx, target.ID)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to anonymize user", "user_id", target.ID, "error", err)
+		return ErrUserUpdateFailed
+	}
+
+	if err := s.auth.RevokeTokens(ctx, target.ID); err != nil {
+		logger.FromContext(ctx).Error("failed to revoke tokens of anonymized user", "user_id", target.ID, "error", err)
+	}
+	s.deleteCalendarEvents(ctx, future)
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.UserAnonymize,
+		ActorID:    input.ActorID,
+		UserID:     target.ID,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatInt(target.ID, 10),
+		Before:     userSnapshot(target),
+	})
+
+	logger.FromContext(ctx).Info("user anonymized", "user_id", target.ID, "by", input.ActorID)
+	return nil
+}
+
+// DeleteUser permanently deletes the user together with all their bookings.
+func (s *UserService) DeleteUser(ctx context.Context, input UserActionInput) error {
+	target, err := s.manageable(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	future, err := s.db.ListFutureReservationsByUser(ctx, target.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list future reservations", "user_id", target.ID, "error", err)
+		return ErrUserUpdateFailed
+	}
+
+	rows, err := s.db.DeleteUser(ctx, target.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to delete user", "user_id", target.ID, "error", err)
+		return ErrUserUpdateFailed
+	}
+	if rows == 0 {
+		return ErrUserNotFound
+	}
+
+	s.deleteCalendarEvents(ctx, future)
+
+	s.audit.Record(ctx, audit.Entry{
+		Action:     audit.UserDelete,
+		ActorID:    input.ActorID,
+		UserID:     target.ID,
+		TargetType: audit.TargetUser,
+		TargetID:   strconv.FormatInt(target.ID, 10),
+		Before:     userSnapshot(target),
+	})
+
+	logger.FromContext(ctx).Info("user deleted", "user_id", target.ID, "by", input.ActorID)
+	return nil
+}
+
+// manageable loads the target user and checks that the actor may act on it.
+// Nobody can act on their own account or on a user holding roles the actor
+// could not grant, so staff cannot suspend or remove admins.
+func (s *UserService) manageable(ctx context.Context, input UserActionInput) (database.User, error) {
+	if input.UserID == input.ActorID {
+		return database.User{}, ErrCannotManageSelf
+	}
+
+	target, err := s.GetUser(ctx, input.UserID, input.CampusID)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	assignments, err := s.db.ListRoleAssignmentsByUser(ctx, target.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list role assignments", "user_id", target.ID, "error", err)
+		return database.User{}, ErrUserFetchFailed
+	}
+
+	if !input.Grants.CanGrant(rbac.Role(target.Role), 0) {
+		return database.User{}, ErrUnauthorized
+	}
+	for _, a := range assignments {
+		if !input.Grants.CanGrant(rbac.Role(a.Role), a.RoomID.Int64) {
+			return database.User{}, ErrUnauthorized
+		}
+	}
+
+	return target, nil
+}
+
+// deleteCalendarEvents removes the calendar events of deleted bookings.
+func (s *UserService) deleteCalendarEvents(ctx context.Context, reservations []database.Reservation) {
+	metrics.ReservationsCancelled.WithLabelValues(metrics.CancelUserRemoved).Add(float64(len(reservations)))
+	for _, r := range reservations {
+		if !r.GcalEventID.Valid {
+			continue
+		}
+		calendarID := s.campuses.Resolve(r.CampusID).CalendarID
+		go func(eventID string) {
+			ctx, cancel := context.WithTimeout(tracing.Detach(ctx), 40*time.Second)
+			defer cancel()
+			err := s.calendar.Do(ctx, "delete event", func(ctx context.Context, calendar google.Calendar) error {
+				return calendar.DeleteGoogleEvent(ctx, calendarID, eventID)
+			})
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to delete google calendar event", "error", err)
+			}
+		}(r.GcalEventID.String)
+	}
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,147 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IbnBaqqi/book-me/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useMemoryExporter installs a tracer provider recording to memory for the
+// duration of the test. Call the returned function to flush and read spans.
+func useMemoryExporter(t *testing.T) func() tracetest.SpanStubs {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := NewProvider(exporter, "book-me-test", 1)
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		_ = provider.Shutdown(context.Background())
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	return func() tracetest.SpanStubs {
+		if err := provider.ForceFlush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return exporter.GetSpans()
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	spans := useMemoryExporter(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rooms/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, child := Start(r.Context(), "work")
+		child.End()
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := Middleware(mux)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet, "/rooms/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	got := spans()
+	if len(got) != 2 {
+		t.Fatalf("expected a handler and a child span, got %d spans", len(got))
+	}
+
+	child, server := got[0], got[1]
+	if server.Name != "GET /rooms/{id}" {
+		t.Errorf("expected span named after the route, got %q", server.Name)
+	}
+	if server.SpanContext.TraceID().String() != traceID {
+		t.Errorf("expected the trace of the caller, got %s", server.SpanContext.TraceID())
+	}
+	if server.Status.Code != codes.Error {
+		t.Errorf("expected error status for a 500, got %v", server.Status.Code)
+	}
+	if child.Parent.SpanID() != server.SpanContext.SpanID() {
+		t.Errorf("expected the handler span to be the parent of spans started in the handler")
+	}
+}
+
+func TestDetach(t *testing.T) {
+	spans := useMemoryExporter(t)
+
+	reqCtx, cancel := context.WithCancel(context.Background())
+	reqCtx, request := Start(reqCtx, "request")
+
+	jobCtx := Detach(reqCtx)
+	cancel()
+	request.End()
+
+	if jobCtx.Err() != nil {
+		t.Fatalf("expected detached context to outlive the request, got %v", jobCtx.Err())
+	}
+
+	_, job := Start(jobCtx, "job")
+	job.End()
+
+	got := spans()
+	if len(got) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(got))
+	}
+	requestSpan, jobSpan := got[0], got[1]
+	if jobSpan.SpanContext.TraceID() != requestSpan.SpanContext.TraceID() {
+		t.Error("expected the job in the trace of the request")
+	}
+	if jobSpan.Parent.SpanID() != requestSpan.SpanContext.SpanID() {
+		t.Error("expected the request span to be the parent of the job span")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	prev := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	tests := []struct {
+		name    string
+		cfg     config.TracingConfig
+		wantErr bool
+	}{
+		{name: "no exporter", cfg: config.TracingConfig{Exporter: ExporterNone, SampleRatio: 1}},
+		{name: "otlp over http", cfg: config.TracingConfig{Exporter: ExporterOTLP, Protocol: ProtocolHTTP, Endpoint: "localhost:4318", Insecure: true}},
+		{name: "otlp over grpc", cfg: config.TracingConfig{Exporter: ExporterOTLP, Protocol: ProtocolGRPC, Endpoint: "localhost:4317", Insecure: true}},
+		{name: "unknown exporter", cfg: config.TracingConfig{Exporter: "zipkin"}, wantErr: true},
+		{name: "unknown protocol", cfg: config.TracingConfig{Exporter: ExporterOTLP, Protocol: "thrift"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown, err := Setup(context.Background(), tt.cfg)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			// Nothing listens on the endpoints, shutdown must not hang on it
+			ctx, cancel := context.WithTimeout(context.Background(), 0)
+			defer cancel()
+			_ = shutdown(ctx)
+		})
+	}
+}
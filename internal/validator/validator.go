@@ -2,10 +2,13 @@
 package validator
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/IbnBaqqi/book-me/internal/schedule"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -16,6 +19,19 @@ var (
 	maxDateRangeDays = 60                // maximum allowed date range in days
 )
 
+// regularHours are the school hours checked before SetOpeningHours is called.
+var regularHours = schedule.NewCalendar(helsinkiTZ, schedule.Hours{
+	Open:  schoolOpenHour * 60,
+	Close: schoolCloseHour * 60,
+})
+
+// openingHours returns the calendar the schoolHours validator reads when the
+// context carries no campus calendar, set by SetOpeningHours.
+var (
+	openingHoursMu sync.RWMutex
+	openingHours   = func() *schedule.Calendar { return regularHours }
+)
+
 func init() {
 	validate = validator.New()
 
@@ -25,16 +41,38 @@ func init() {
 	// so this will essentially never fail
 	// but the linter is happy because the error is handled.
 	_ = validate.RegisterValidation("futureTime", validateFutureTime)
-	_ = validate.RegisterValidation("schoolHours", validateSchoolHours)
+	_ = validate.RegisterValidationCtx("schoolHours", validateSchoolHours)
 	_ = validate.RegisterValidation("maxDateRange", validateMaxDateRange)
-	_ = validate.RegisterValidation("utc", validateUTC)
 }
 
 var helsinkiTZ, _ = time.LoadLocation("Europe/Helsinki")
 
+// SetOpeningHours sets where the schoolHours validator gets the calendar it
+// checks against by default. It is called on every validation, so the
+// calendar of campuses loaded again applies right away.
+func SetOpeningHours(hours func() *schedule.Calendar) {
+	openingHoursMu.Lock()
+	defer openingHoursMu.Unlock()
+	openingHours = hours
+}
+
+type openingHoursKey struct{}
+
+// WithOpeningHours returns a context that makes ValidateCtx check
+// schoolHours against the calendar, the one of the user's campus.
+func WithOpeningHours(ctx context.Context, c *schedule.Calendar) context.Context {
+	return context.WithValue(ctx, openingHoursKey{}, c)
+}
+
 // Validate validates a struct and returns ValidationError if validation fails
 func Validate(s interface{}) error {
-	err := validate.Struct(s)
+	return ValidateCtx(context.Background(), s)
+}
+
+// ValidateCtx is Validate with a context, which may carry the opening hours
+// set with WithOpeningHours.
+func ValidateCtx(ctx context.Context, s interface{}) error {
+	err := validate.StructCtx(ctx, s)
 	if err == nil {
 		return nil
 	}
@@ -62,28 +100,27 @@ func validateFutureTime(fl validator.FieldLevel) bool {
 	return t.After(time.Now())
 }
 
-func validateUTC(fl validator.FieldLevel) bool {
+// validateSchoolHours checks if time is within the campus opening hours,
+// taking closed days and special hours into account
+func validateSchoolHours(ctx context.Context, fl validator.FieldLevel) bool {
 	t, ok := fl.Field().Interface().(time.Time)
 	if !ok {
 		return false
 	}
-	_, offset := t.Zone()
-	return offset == 0
-}
 
-// validateSchoolHours checks if time is within school operating hours
-func validateSchoolHours(fl validator.FieldLevel) bool {
-	t, ok := fl.Field().Interface().(time.Time)
-	if !ok {
-		return false
+	if c, ok := ctx.Value(openingHoursKey{}).(*schedule.Calendar); ok {
+		return c.IsOpenAt(t)
 	}
 
-	hour := t.In(helsinkiTZ).Hour()
-	minute := t.In(helsinkiTZ).Minute()
-	return hour >= schoolOpenHour && (hour < schoolCloseHour || (hour == schoolCloseHour && minute == 0))
+	openingHoursMu.RLock()
+	hours := openingHours
+	openingHoursMu.RUnlock()
+	return hours().IsOpenAt(t)
 }
 
-// validateMaxDateRange ensures date range doesn't exceed a maximum (e.g 60 days)
+// validateMaxDateRange ensures date range doesn't exceed a maximum (e.g 60 days).
+// Days are counted on the calendar of the start date, so a range across a
+// daylight saving change is not an hour too long or short.
 func validateMaxDateRange(fl validator.FieldLevel) bool {
 	endDate, ok := fl.Field().Interface().(time.Time)
 	if !ok {
@@ -100,8 +137,17 @@ func validateMaxDateRange(fl validator.FieldLevel) bool {
 		return false
 	}
 
-	diff := endDate.Sub(startDate)
-	return diff.Hours() <= float64(maxDateRangeDays*24)
+	return calendarDays(startDate, endDate.In(startDate.Location())) <= maxDateRangeDays
+}
+
+// calendarDays returns the number of dates from the date of start to the
+// date of end.
+func calendarDays(start, end time.Time) int {
+	sy, sm, sd := start.Date()
+	ey, em, ed := end.Date()
+	from := time.Date(sy, sm, sd, 0, 0, 0, 0, time.UTC)
+	to := time.Date(ey, em, ed, 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from).Hours() / 24)
 }
 
 // FormatValidationErrors formats validator errors into user-friendly messages
@@ -124,6 +170,16 @@ func formatFieldError(err validator.FieldError) string {
 		return "This field is required"
 	case "gt":
 		return fmt.Sprintf("Must be greater than %s", err.Param())
+	case "gte":
+		return fmt.Sprintf("Must be greater than or equal to %s", err.Param())
+	case "lte":
+		return fmt.Sprintf("Must be less than or equal to %s", err.Param())
+	case "min":
+		return fmt.Sprintf("Must contain at least %s item(s)", err.Param())
+	case "max":
+		return fmt.Sprintf("Must be at most %s characters", err.Param())
+	case "oneof":
+		return fmt.Sprintf("Must be one of: %s", err.Param())
 	case "futureTime":
 		return "Time must be in the future"
 	case "gtfield":
@@ -133,11 +189,9 @@ func formatFieldError(err validator.FieldError) string {
 	case "datetime":
 		return "Invalid date/time format"
 	case "schoolHours":
-		return "Time must be between 6:00 AM and 8:00 PM"
+		return "Time must be within the campus opening hours"
 	case "maxDateRange":
 		return "Date range cannot exceed 60 days"
-	case "utc":
-		return "Time must be in UTC format (e.g. 2026-02-23T06:00:00Z)"
 	default:
 		return fmt.Sprintf("Validation failed on '%s'", err.Tag())
 	}
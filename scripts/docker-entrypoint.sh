@@ -1,8 +1,8 @@
 #!/bin/sh
 
 echo "Migrating the database..."
-goose -dir ./sql/migrations postgres "$DATABASE_URL" up
+/usr/local/bin/bookme migrate up || exit 1
 echo "Migrations completed successfully!"
 
 echo "Starting the server..."
-exec /usr/local/bin/bookme
\ No newline at end of file
+exec /usr/local/bin/bookme serve
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (user_id, name, token_hash, token_prefix, scopes, expires_at)
+VALUES (
+	$1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetAPITokenByHash :one
+SELECT * FROM api_tokens
+WHERE token_hash = $1;
+
+-- name: ListAPITokensByUser :many
+SELECT * FROM api_tokens
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: TouchAPIToken :exec
+UPDATE api_tokens
+SET last_used_at = now()
+WHERE id = $1
+  AND (last_used_at IS NULL OR last_used_at < now() - INTERVAL '1 minute');
+
+-- name: DeleteAPIToken :execrows
+DELETE FROM api_tokens
+WHERE id = $1 AND user_id = $2;
+
+-- name: DeleteAPITokensByUser :exec
+DELETE FROM api_tokens
+WHERE user_id = $1;
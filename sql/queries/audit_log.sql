@@ -0,0 +1,24 @@
+-- name: CreateAuditEntry :exec
+-- Without a campus, the entry belongs to the campus of its room, the user
+-- acted on or the actor
+INSERT INTO audit_log (action, actor_id, user_id, room_id, target_type, target_id, before, after, ip, request_id, campus_id)
+VALUES (
+	$1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+	COALESCE(
+		sqlc.narg('campus_id')::BIGINT,
+		(SELECT campus_id FROM rooms WHERE id = $4),
+		(SELECT campus_id FROM users WHERE id = $3),
+		(SELECT campus_id FROM users WHERE id = $2)
+	)
+);
+
+-- name: ListAuditEntries :many
+SELECT * FROM audit_log
+WHERE (sqlc.narg('user_id')::BIGINT IS NULL OR actor_id = sqlc.narg('user_id') OR user_id = sqlc.narg('user_id'))
+  AND (sqlc.narg('room_id')::BIGINT IS NULL OR room_id = sqlc.narg('room_id'))
+  AND (sqlc.narg('campus_id')::BIGINT IS NULL OR campus_id = sqlc.narg('campus_id'))
+  AND (sqlc.narg('action')::TEXT IS NULL OR action = sqlc.narg('action'))
+  AND (sqlc.narg('from_time')::TIMESTAMPTZ IS NULL OR occurred_at >= sqlc.narg('from_time'))
+  AND (sqlc.narg('to_time')::TIMESTAMPTZ IS NULL OR occurred_at < sqlc.narg('to_time'))
+ORDER BY occurred_at DESC, id DESC
+LIMIT sqlc.arg('max_rows') OFFSET sqlc.arg('skip_rows');
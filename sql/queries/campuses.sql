@@ -0,0 +1,28 @@
+-- name: ListCampuses :many
+SELECT * FROM campuses
+ORDER BY id;
+
+-- name: GetCampus :one
+SELECT * FROM campuses
+WHERE id = $1;
+
+-- name: CreateCampus :one
+INSERT INTO campuses (slug, name, intra_campus_id, timezone, open_minute, close_minute, login_providers, calendar_id, cors_origins)
+VALUES (
+	$1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+RETURNING *;
+
+-- name: UpdateCampus :one
+UPDATE campuses
+SET name = $2,
+    intra_campus_id = $3,
+    timezone = $4,
+    open_minute = $5,
+    close_minute = $6,
+    login_providers = $7,
+    calendar_id = $8,
+    cors_origins = $9,
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,25 @@
+-- name: CreateDisplayDevice :one
+INSERT INTO display_devices (room_id, name, token_hash, token_prefix, created_by)
+VALUES (
+	$1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetDisplayDeviceByHash :one
+SELECT * FROM display_devices
+WHERE token_hash = $1;
+
+-- name: ListDisplayDevicesByRoom :many
+SELECT * FROM display_devices
+WHERE room_id = $1
+ORDER BY name;
+
+-- name: TouchDisplayDevice :exec
+UPDATE display_devices
+SET last_seen_at = now()
+WHERE id = $1
+  AND (last_seen_at IS NULL OR last_seen_at < now() - INTERVAL '1 minute');
+
+-- name: DeleteDisplayDevice :execrows
+DELETE FROM display_devices
+WHERE id = $1 AND room_id = $2;
@@ -0,0 +1,23 @@
+-- name: GetLoginAccessRule :one
+SELECT * FROM login_access_rules
+WHERE login = $1;
+
+-- name: ListLoginAccessRules :many
+SELECT * FROM login_access_rules
+ORDER BY login;
+
+-- name: UpsertLoginAccessRule :one
+INSERT INTO login_access_rules (login, effect, reason, created_by)
+VALUES (
+	$1, $2, $3, $4
+)
+ON CONFLICT (login) DO UPDATE
+SET effect = EXCLUDED.effect,
+    reason = EXCLUDED.reason,
+    created_by = EXCLUDED.created_by,
+    created_at = now()
+RETURNING *;
+
+-- name: DeleteLoginAccessRule :execrows
+DELETE FROM login_access_rules
+WHERE login = $1;
@@ -0,0 +1,32 @@
+-- name: GetLoginLockout :one
+SELECT * FROM login_lockouts
+WHERE kind = $1 AND subject = $2;
+
+-- name: CreateLoginLockout :exec
+INSERT INTO login_lockouts (kind, subject)
+VALUES ($1, $2)
+ON CONFLICT (kind, subject) DO NOTHING;
+
+-- name: GetLoginLockoutForUpdate :one
+SELECT * FROM login_lockouts
+WHERE kind = $1 AND subject = $2
+FOR UPDATE;
+
+-- name: UpdateLoginLockout :one
+UPDATE login_lockouts
+SET failures = $3,
+    lockouts = $4,
+    last_reason = $5,
+    last_failure_at = $6,
+    locked_until = $7
+WHERE kind = $1 AND subject = $2
+RETURNING *;
+
+-- name: ListActiveLoginLockouts :many
+SELECT * FROM login_lockouts
+WHERE locked_until > $1
+ORDER BY locked_until DESC;
+
+-- name: DeleteLoginLockout :execrows
+DELETE FROM login_lockouts
+WHERE kind = $1 AND subject = $2;
@@ -0,0 +1,31 @@
+-- name: ListOpeningHoursExceptions :many
+SELECT * FROM opening_hours_exceptions
+ORDER BY campus_id, date;
+
+-- name: ListOpeningHoursExceptionsByCampus :many
+SELECT * FROM opening_hours_exceptions
+WHERE campus_id = $1
+ORDER BY date;
+
+-- name: GetOpeningHoursException :one
+SELECT * FROM opening_hours_exceptions
+WHERE campus_id = $1 AND date = $2;
+
+-- name: UpsertOpeningHoursException :one
+INSERT INTO opening_hours_exceptions (campus_id, date, closed, open_minute, close_minute, name, source_uid, created_by)
+VALUES (
+	$1, $2, $3, $4, $5, $6, $7, $8
+)
+ON CONFLICT (campus_id, date) DO UPDATE
+SET closed = EXCLUDED.closed,
+    open_minute = EXCLUDED.open_minute,
+    close_minute = EXCLUDED.close_minute,
+    name = EXCLUDED.name,
+    source_uid = EXCLUDED.source_uid,
+    created_by = EXCLUDED.created_by,
+    updated_at = now()
+RETURNING *;
+
+-- name: DeleteOpeningHoursException :execrows
+DELETE FROM opening_hours_exceptions
+WHERE campus_id = $1 AND date = $2;
@@ -1,7 +1,7 @@
 -- name: CreateReservation :one
-INSERT INTO reservations (user_id, room_id, start_time, end_time, status)
+INSERT INTO reservations (user_id, room_id, start_time, end_time, status, campus_id)
 VALUES (
-	$1, $2, $3, $4, $5
+	$1, $2, $3, $4, $5, $6
 )
 RETURNING *;
 
@@ -38,13 +38,76 @@ INNER JOIN users u ON r.user_id = u.id
 INNER JOIN rooms room ON r.room_id = room.id
 WHERE r.start_time >= $1
   AND r.end_time <= $2
+  AND r.campus_id = $3
 ORDER BY r.room_id, r.start_time;
 
 -- name: DeleteReservation :exec
 DELETE FROM reservations
 WHERE id = $1;
 
--- name: UpdateGoogleCalID :exec
+-- name: UpdateGoogleCalID :execrows
 UPDATE reservations
 SET gcal_event_id = $2
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1 AND gcal_event_id IS NULL;
+
+-- name: ListUpcomingReservationsByRoom :many
+SELECT * FROM reservations
+WHERE room_id = $1
+  AND end_time > $2
+ORDER BY start_time ASC
+LIMIT $3;
+
+-- name: ListReservationsByUser :many
+SELECT
+    r.id,
+    r.room_id,
+    r.start_time,
+    r.end_time,
+    r.status,
+    room.name as room_name
+FROM reservations r
+INNER JOIN rooms room ON r.room_id = room.id
+WHERE r.user_id = $1
+  AND (sqlc.narg('from_time')::TIMESTAMPTZ IS NULL OR r.end_time > sqlc.narg('from_time'))
+  AND (sqlc.narg('to_time')::TIMESTAMPTZ IS NULL OR r.start_time < sqlc.narg('to_time'))
+ORDER BY r.start_time DESC
+LIMIT sqlc.arg('max_rows');
+
+-- name: ListFutureReservationsByUser :many
+SELECT * FROM reservations
+WHERE user_id = $1
+  AND start_time > now();
+
+-- name: DeleteFutureReservationsByUser :exec
+DELETE FROM reservations
+WHERE user_id = $1
+  AND start_time > now();
+
+-- name: ListReservationsByRoomBetween :many
+SELECT * FROM reservations
+WHERE room_id = $1
+  AND start_time < sqlc.arg('to_time')
+  AND end_time > sqlc.arg('from_time')
+ORDER BY start_time ASC
+FOR UPDATE;
+
+-- name: ListReservationsBetween :many
+SELECT
+    r.id,
+    r.campus_id,
+    r.room_id,
+    r.user_id,
+    r.start_time,
+    r.end_time,
+    r.status,
+    r.gcal_event_id,
+    room.name as room_name,
+    u.name as user_name,
+    u.email as user_email
+FROM reservations r
+INNER JOIN users u ON r.user_id = u.id
+INNER JOIN rooms room ON r.room_id = room.id
+WHERE r.end_time > sqlc.arg('from_time')
+  AND r.start_time < sqlc.arg('to_time')
+  AND (sqlc.narg('campus_id')::BIGINT IS NULL OR r.campus_id = sqlc.narg('campus_id'))
+ORDER BY r.start_time, r.room_id;
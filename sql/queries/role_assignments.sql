@@ -0,0 +1,23 @@
+-- name: ListRoleAssignmentsByUser :many
+SELECT * FROM role_assignments
+WHERE user_id = $1
+ORDER BY id;
+
+-- name: GetRoleAssignment :one
+SELECT * FROM role_assignments
+WHERE id = $1;
+
+-- name: CreateRoleAssignment :one
+INSERT INTO role_assignments (user_id, role, room_id, granted_by)
+VALUES (
+	$1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: DeleteRoleAssignment :exec
+DELETE FROM role_assignments
+WHERE id = $1;
+
+-- name: DeleteRoleAssignmentsByUser :exec
+DELETE FROM role_assignments
+WHERE user_id = $1;
@@ -0,0 +1,34 @@
+-- name: CreateRoomBlackout :one
+INSERT INTO room_blackouts (room_id, reason, start_time, end_time, recurrence, repeat_until, created_by)
+VALUES (
+	$1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetRoomBlackout :one
+SELECT * FROM room_blackouts
+WHERE id = $1 AND room_id = $2;
+
+-- name: ListRoomBlackoutsByRoom :many
+SELECT * FROM room_blackouts
+WHERE room_id = $1
+ORDER BY start_time;
+
+-- name: ListRoomBlackoutsBetween :many
+-- Blackouts with an occurrence that may overlap [from_time, to_time),
+-- recurring ones are expanded by the caller.
+SELECT
+    b.*,
+    room.name as room_name,
+    room.campus_id
+FROM room_blackouts b
+INNER JOIN rooms room ON b.room_id = room.id
+WHERE (sqlc.narg('room_id')::BIGINT IS NULL OR b.room_id = sqlc.narg('room_id'))
+  AND (sqlc.narg('campus_id')::BIGINT IS NULL OR room.campus_id = sqlc.narg('campus_id'))
+  AND b.start_time < sqlc.arg('to_time')
+  AND COALESCE(b.repeat_until + (b.end_time - b.start_time), b.end_time) > sqlc.arg('from_time')::TIMESTAMPTZ
+ORDER BY b.room_id, b.start_time;
+
+-- name: DeleteRoomBlackout :execrows
+DELETE FROM room_blackouts
+WHERE id = $1 AND room_id = $2;
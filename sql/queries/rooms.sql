@@ -1,3 +1,21 @@
 -- name: GetRoomByID :one
 SELECT * FROM rooms
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: LockRoom :one
+-- Serializes the bookings and blackouts of a room until the transaction ends
+SELECT id FROM rooms
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListRoomsByCampus :many
+SELECT * FROM rooms
+WHERE campus_id = $1
+ORDER BY name;
+
+-- name: CreateRoom :one
+INSERT INTO rooms (campus_id, name)
+VALUES (
+	$1, $2
+)
+RETURNING *;
@@ -0,0 +1,19 @@
+-- name: GetUserIdentity :one
+SELECT * FROM user_identities
+WHERE provider = $1 AND subject = $2;
+
+-- name: CreateUserIdentity :one
+INSERT INTO user_identities (user_id, provider, subject)
+VALUES (
+	$1, $2, $3
+)
+RETURNING *;
+
+-- name: TouchUserIdentity :exec
+UPDATE user_identities
+SET last_login_at = now()
+WHERE id = $1;
+
+-- name: DeleteUserIdentities :exec
+DELETE FROM user_identities
+WHERE user_id = $1;
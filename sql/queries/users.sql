@@ -1,7 +1,7 @@
 -- name: CreateUser :one
-INSERT INTO users (email, name, role)
+INSERT INTO users (email, name, role, campus_id)
 VALUES (
-	$1, $2, $3
+	$1, $2, $3, $4
 )
 RETURNING *;
 
@@ -13,16 +13,73 @@ WHERE id = $1;
 SELECT * FROM users
 WHERE email = $1;
 
--- -- name: UpdateUser :one
--- UPDATE users
--- SET email = $2, name = $3, role = $4
--- WHERE id = $1
--- RETURNING *;
+-- name: UpdateUserProfile :one
+-- A NULL campus_id keeps the user's campus.
+UPDATE users
+SET name = sqlc.arg('name'),
+    login = sqlc.arg('login'),
+    avatar_url = sqlc.arg('avatar_url'),
+    intra_campus_id = sqlc.arg('intra_campus_id'),
+    role = sqlc.arg('role'),
+    campus_id = COALESCE(sqlc.narg('campus_id'), campus_id),
+    updated_at = now()
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: ListUsers :many
+SELECT * FROM users
+WHERE (sqlc.narg('search')::TEXT IS NULL
+       OR name ILIKE '%' || sqlc.narg('search') || '%'
+       OR email ILIKE '%' || sqlc.narg('search') || '%'
+       OR login ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::TEXT IS NULL OR role = sqlc.narg('role'))
+  AND (sqlc.narg('suspended')::BOOLEAN IS NULL OR (suspended_at IS NOT NULL) = sqlc.narg('suspended'))
+  AND (sqlc.narg('campus_id')::BIGINT IS NULL OR campus_id = sqlc.narg('campus_id'))
+  AND anonymized_at IS NULL
+ORDER BY name, id
+LIMIT sqlc.arg('max_rows') OFFSET sqlc.arg('skip_rows');
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users
+WHERE (sqlc.narg('search')::TEXT IS NULL
+       OR name ILIKE '%' || sqlc.narg('search') || '%'
+       OR email ILIKE '%' || sqlc.narg('search') || '%'
+       OR login ILIKE '%' || sqlc.narg('search') || '%')
+  AND (sqlc.narg('role')::TEXT IS NULL OR role = sqlc.narg('role'))
+  AND (sqlc.narg('suspended')::BOOLEAN IS NULL OR (suspended_at IS NOT NULL) = sqlc.narg('suspended'))
+  AND (sqlc.narg('campus_id')::BIGINT IS NULL OR campus_id = sqlc.narg('campus_id'))
+  AND anonymized_at IS NULL;
+
+-- name: SuspendUser :one
+UPDATE users
+SET suspended_at = now(), suspended_reason = $2, updated_at = now()
+WHERE id = $1
+RETURNING *;
 
--- -- name: ListUsers :many
--- SELECT * FROM users
--- ORDER BY name;
+-- name: UnsuspendUser :one
+UPDATE users
+SET suspended_at = NULL, suspended_reason = '', updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: AnonymizeUser :one
+UPDATE users
+SET email = 'deleted-' || id || '@anonymized.invalid',
+    name = 'Deleted user',
+    login = NULL,
+    avatar_url = NULL,
+    intra_campus_id = NULL,
+    suspended_at = COALESCE(suspended_at, now()),
+    anonymized_at = now(),
+    updated_at = now()
+WHERE id = $1
+RETURNING *;
 
--- -- name: DeleteUser :exec
--- DELETE FROM users
--- WHERE id = $1;
\ No newline at end of file
+-- name: RevokeUserTokens :exec
+UPDATE users
+SET tokens_valid_after = now()
+WHERE id = $1;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
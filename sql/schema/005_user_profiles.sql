@@ -0,0 +1,29 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN login VARCHAR(100),
+    ADD COLUMN avatar_url TEXT,
+    ADD COLUMN intra_campus_id INTEGER,
+    ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+CREATE TABLE user_identities ( -- links a provider account to a user
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_login_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT fk_identity_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT unique_provider_subject UNIQUE (provider, subject)
+);
+
+CREATE INDEX idx_identity_user ON user_identities (user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_identities;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS login,
+    DROP COLUMN IF EXISTS avatar_url,
+    DROP COLUMN IF EXISTS intra_campus_id,
+    DROP COLUMN IF EXISTS updated_at;
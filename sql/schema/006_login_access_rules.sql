@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE login_access_rules ( -- per-login allow/deny overrides for 42 logins
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    login VARCHAR(100) NOT NULL UNIQUE,
+    effect VARCHAR(10) NOT NULL CHECK (effect IN ('ALLOW', 'DENY')),
+    reason TEXT NOT NULL DEFAULT '',
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT fk_access_rule_creator FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_access_rules;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE role_assignments ( -- roles granted on top of the user's base role
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    room_id BIGINT,
+    granted_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT fk_role_assignment_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_role_assignment_room FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_role_assignment_granter FOREIGN KEY (granted_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX idx_role_assignment_unique ON role_assignments (user_id, role, COALESCE(room_id, 0));
+
+-- +goose Down
+DROP TABLE IF EXISTS role_assignments;
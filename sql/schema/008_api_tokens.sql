@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE api_tokens ( -- personal access tokens for scripts and integrations
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE, -- hex SHA-256 of the token, the token itself is never stored
+    token_prefix VARCHAR(16) NOT NULL, -- first characters of the token to help users recognise it
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT fk_api_token_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT uq_api_token_name UNIQUE (user_id, name)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_tokens;
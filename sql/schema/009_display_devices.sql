@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE display_devices ( -- room displays authenticating with a device token
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    room_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE, -- hex SHA-256 of the device token
+    token_prefix VARCHAR(16) NOT NULL,
+    created_by BIGINT,
+    last_seen_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT fk_display_device_room FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_display_device_creator FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT uq_display_device_name UNIQUE (room_id, name)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS display_devices;
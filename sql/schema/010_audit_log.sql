@@ -0,0 +1,36 @@
+-- +goose Up
+CREATE TABLE audit_log ( -- append-only trail of reservation, admin and login actions
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    action VARCHAR(50) NOT NULL,
+    actor_id BIGINT, -- no foreign keys, entries outlive the users and rooms they mention
+    user_id BIGINT, -- user the action was performed on
+    room_id BIGINT,
+    target_type VARCHAR(30) NOT NULL,
+    target_id VARCHAR(100) NOT NULL DEFAULT '',
+    before JSONB NOT NULL DEFAULT 'null',
+    after JSONB NOT NULL DEFAULT 'null',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT ''
+);
+
+CREATE INDEX idx_audit_log_occurred_at ON audit_log (occurred_at);
+CREATE INDEX idx_audit_log_actor ON audit_log (actor_id, occurred_at);
+CREATE INDEX idx_audit_log_user ON audit_log (user_id, occurred_at);
+CREATE INDEX idx_audit_log_room ON audit_log (room_id, occurred_at);
+
+-- +goose StatementBegin
+CREATE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_audit_log_append_only
+BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
@@ -0,0 +1,17 @@
+-- +goose Up
+ALTER TABLE users
+    ADD COLUMN suspended_at TIMESTAMPTZ,
+    ADD COLUMN suspended_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN tokens_valid_after TIMESTAMPTZ, -- tokens issued before this are rejected
+    ADD COLUMN anonymized_at TIMESTAMPTZ;
+
+CREATE INDEX idx_reservation_user_time ON reservations (user_id, start_time);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_reservation_user_time;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS suspended_at,
+    DROP COLUMN IF EXISTS suspended_reason,
+    DROP COLUMN IF EXISTS tokens_valid_after,
+    DROP COLUMN IF EXISTS anonymized_at;
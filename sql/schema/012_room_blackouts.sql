@@ -0,0 +1,25 @@
+-- +goose Up
+CREATE TABLE room_blackouts ( -- periods a room cannot be booked, e.g. maintenance
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    room_id BIGINT NOT NULL,
+    reason VARCHAR(255) NOT NULL,
+    start_time TIMESTAMPTZ NOT NULL, -- first occurrence
+    end_time TIMESTAMPTZ NOT NULL,
+    recurrence VARCHAR(10) NOT NULL DEFAULT 'NONE',
+    repeat_until TIMESTAMPTZ, -- last occurrence starts at or before this, required when recurring
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT fk_room_blackout_room FOREIGN KEY (room_id) REFERENCES rooms(id) ON DELETE CASCADE,
+    CONSTRAINT fk_room_blackout_creator FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_room_blackout_time CHECK (end_time > start_time),
+    CONSTRAINT chk_room_blackout_recurrence CHECK (
+        (recurrence = 'NONE' AND repeat_until IS NULL)
+        OR (recurrence IN ('DAILY', 'WEEKLY') AND repeat_until >= start_time)
+    )
+);
+
+CREATE INDEX idx_room_blackout_room_time ON room_blackouts (room_id, start_time);
+
+-- +goose Down
+DROP TABLE IF EXISTS room_blackouts;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE opening_hours_exceptions ( -- closed days, shortened days and extended nights
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    date DATE NOT NULL UNIQUE, -- campus local date
+    closed BOOLEAN NOT NULL DEFAULT FALSE,
+    open_minute INT NOT NULL DEFAULT 0, -- minutes after local midnight
+    close_minute INT NOT NULL DEFAULT 0, -- up to 1440, closing at midnight
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    source_uid VARCHAR(255) NOT NULL DEFAULT '', -- iCalendar UID of imported exceptions
+    created_by BIGINT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT fk_opening_hours_creator FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT chk_opening_hours_minutes CHECK (
+        closed OR (open_minute >= 0 AND close_minute <= 1440 AND open_minute < close_minute)
+    )
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS opening_hours_exceptions;
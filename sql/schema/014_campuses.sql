@@ -0,0 +1,68 @@
+-- +goose Up
+CREATE TABLE campuses ( -- one BookMe deployment can serve several 42 campuses
+    id BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    slug VARCHAR(50) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    intra_campus_id INTEGER UNIQUE, -- 42 intra campus ID, 42 logins are matched on it
+    timezone VARCHAR(64) NOT NULL,
+    open_minute INT NOT NULL DEFAULT 360, -- regular opening hours, minutes after local midnight
+    close_minute INT NOT NULL DEFAULT 1200,
+    login_providers TEXT[] NOT NULL DEFAULT '{42,keycloak}',
+    calendar_id VARCHAR(255) NOT NULL DEFAULT '', -- Google calendar, empty uses the default
+    cors_origins TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT chk_campus_hours CHECK (open_minute >= 0 AND close_minute <= 1440 AND open_minute < close_minute)
+);
+
+INSERT INTO campuses (slug, name, intra_campus_id, timezone)
+VALUES ('hive-helsinki', 'Hive Helsinki', 13, 'Europe/Helsinki');
+
+-- Rooms, users and reservations belong to a campus, existing ones to Hive
+ALTER TABLE rooms ADD COLUMN campus_id BIGINT;
+UPDATE rooms SET campus_id = (SELECT id FROM campuses WHERE slug = 'hive-helsinki');
+ALTER TABLE rooms
+    ALTER COLUMN campus_id SET NOT NULL,
+    ADD CONSTRAINT fk_room_campus FOREIGN KEY (campus_id) REFERENCES campuses(id),
+    DROP CONSTRAINT rooms_name_key,
+    ADD CONSTRAINT uq_room_campus_name UNIQUE (campus_id, name);
+
+ALTER TABLE users ADD COLUMN campus_id BIGINT;
+UPDATE users SET campus_id = (SELECT id FROM campuses WHERE slug = 'hive-helsinki');
+ALTER TABLE users
+    ALTER COLUMN campus_id SET NOT NULL,
+    ADD CONSTRAINT fk_user_campus FOREIGN KEY (campus_id) REFERENCES campuses(id);
+
+ALTER TABLE reservations ADD COLUMN campus_id BIGINT;
+UPDATE reservations r SET campus_id = room.campus_id FROM rooms room WHERE room.id = r.room_id;
+ALTER TABLE reservations
+    ALTER COLUMN campus_id SET NOT NULL,
+    ADD CONSTRAINT fk_reservation_campus FOREIGN KEY (campus_id) REFERENCES campuses(id);
+
+CREATE INDEX idx_reservation_campus_time ON reservations (campus_id, start_time);
+
+ALTER TABLE opening_hours_exceptions ADD COLUMN campus_id BIGINT;
+UPDATE opening_hours_exceptions SET campus_id = (SELECT id FROM campuses WHERE slug = 'hive-helsinki');
+ALTER TABLE opening_hours_exceptions
+    ALTER COLUMN campus_id SET NOT NULL,
+    ADD CONSTRAINT fk_opening_hours_campus FOREIGN KEY (campus_id) REFERENCES campuses(id) ON DELETE CASCADE,
+    DROP CONSTRAINT opening_hours_exceptions_date_key,
+    ADD CONSTRAINT uq_opening_hours_campus_date UNIQUE (campus_id, date);
+
+-- +goose Down
+ALTER TABLE opening_hours_exceptions
+    DROP CONSTRAINT IF EXISTS uq_opening_hours_campus_date,
+    DROP COLUMN IF EXISTS campus_id,
+    ADD CONSTRAINT opening_hours_exceptions_date_key UNIQUE (date);
+
+DROP INDEX IF EXISTS idx_reservation_campus_time;
+ALTER TABLE reservations DROP COLUMN IF EXISTS campus_id;
+ALTER TABLE users DROP COLUMN IF EXISTS campus_id;
+
+ALTER TABLE rooms
+    DROP CONSTRAINT IF EXISTS uq_room_campus_name,
+    DROP COLUMN IF EXISTS campus_id,
+    ADD CONSTRAINT rooms_name_key UNIQUE (name);
+
+DROP TABLE IF EXISTS campuses;
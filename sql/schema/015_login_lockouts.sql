@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE login_lockouts ( -- failed OAuth callbacks and lockouts per client IP and per account
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('ip', 'account')),
+    subject VARCHAR(255) NOT NULL, -- IP address, or provider:login for accounts
+    failures INT NOT NULL DEFAULT 0, -- failures since the last lockout, within the failure window
+    lockouts INT NOT NULL DEFAULT 0, -- lockouts in a row, each one twice as long as the last
+    last_reason TEXT NOT NULL DEFAULT '',
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    locked_until TIMESTAMPTZ,
+
+    PRIMARY KEY (kind, subject)
+);
+
+CREATE INDEX idx_login_lockouts_locked_until ON login_lockouts (locked_until);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_lockouts;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Lockouts are referred to by ID in the audit log, which must not keep the
+-- account a lockout is about
+ALTER TABLE login_lockouts
+    ADD COLUMN id BIGSERIAL NOT NULL UNIQUE;
+
+-- +goose Down
+ALTER TABLE login_lockouts
+    DROP COLUMN IF EXISTS id;
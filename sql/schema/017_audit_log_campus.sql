@@ -0,0 +1,20 @@
+-- +goose Up
+-- Audit entries belong to the campus of their room, the user acted on or
+-- the actor, so staff only read the entries of their own campus
+ALTER TABLE audit_log ADD COLUMN campus_id BIGINT; -- no foreign key, like the other IDs
+
+ALTER TABLE audit_log DISABLE TRIGGER trg_audit_log_append_only;
+UPDATE audit_log a
+SET campus_id = COALESCE(
+    (SELECT campus_id FROM rooms WHERE id = a.room_id),
+    (SELECT campus_id FROM users WHERE id = a.user_id),
+    (SELECT campus_id FROM users WHERE id = a.actor_id)
+);
+ALTER TABLE audit_log ENABLE TRIGGER trg_audit_log_append_only;
+
+CREATE INDEX idx_audit_log_campus ON audit_log (campus_id, occurred_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_audit_log_campus;
+
+ALTER TABLE audit_log DROP COLUMN IF EXISTS campus_id;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Special hours may run overnight, closing up to 24 hours after opening
+ALTER TABLE opening_hours_exceptions
+    DROP CONSTRAINT chk_opening_hours_minutes,
+    ADD CONSTRAINT chk_opening_hours_minutes CHECK (
+        closed OR (
+            open_minute >= 0 AND open_minute < 1440
+            AND open_minute < close_minute AND close_minute <= open_minute + 1440
+        )
+    );
+
+-- +goose Down
+UPDATE opening_hours_exceptions SET close_minute = 1440 WHERE close_minute > 1440;
+ALTER TABLE opening_hours_exceptions
+    DROP CONSTRAINT chk_opening_hours_minutes,
+    ADD CONSTRAINT chk_opening_hours_minutes CHECK (
+        closed OR (open_minute >= 0 AND close_minute <= 1440 AND open_minute < close_minute)
+    );
@@ -0,0 +1,10 @@
+// Package schema embeds the goose migrations of the database, so the
+// binary can migrate it without the migration files.
+package schema
+
+import "embed"
+
+// FS holds the migrations, named after their version.
+//
+//go:embed *.sql
+var FS embed.FS